@@ -0,0 +1,94 @@
+package kra
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Address is a postal address split into its components
+//
+// Parsing is best-effort: when the raw address does not follow a recognized
+// format, only Raw is set.
+type Address struct {
+	// POBox is the post office box number, e.g. "12345"
+	POBox string `json:"po_box,omitempty"`
+	// PostalCode is the five-digit postal code, e.g. "00100"
+	PostalCode string `json:"postal_code,omitempty"`
+	// Town is the post office town as written, e.g. "Nairobi"
+	Town string `json:"town,omitempty"`
+	// Raw is the address exactly as returned by KRA
+	Raw string `json:"raw,omitempty"`
+}
+
+// Parsed returns true if the address was split into components
+func (a Address) Parsed() bool {
+	return a.POBox != ""
+}
+
+// postalAddressPatterns match the common Kenyan postal address formats,
+// capturing the box number, postal code and town
+var postalAddressPatterns = []*regexp.Regexp{
+	// "P.O. Box 12345-00100, Nairobi", "PO BOX 12345 00100 NAIROBI", "Box 123, Nakuru"
+	regexp.MustCompile(`(?i)^(?:p\s*\.?\s*o\s*\.?\s*)?box\s*(?:no\.?\s*)?(\d+)(?:\s*[-–/,]?\s*(\d{5})\b)?[\s,.\-–]*(.*)$`),
+	// "12345-00100 Nairobi"
+	regexp.MustCompile(`^(\d+)\s*[-–/]\s*(\d{5})\b[\s,.\-–]*(.*)$`),
+}
+
+// ParsedPostalAddress splits PostalAddress into box number, postal code and town
+//
+// It understands the common Kenyan formats, such as "P.O. Box 12345-00100,
+// Nairobi", "PO BOX 12345 00100 NAIROBI" and "12345-00100 Nairobi", with any
+// casing and punctuation. A postal code written before the town ("00100
+// Nairobi") is recognized too. Addresses in other formats are returned with
+// only Raw set; check Parsed before relying on the components.
+//
+// Example:
+//
+//	addr := details.ParsedPostalAddress()
+//	if addr.Parsed() {
+//	    fmt.Printf("Box %s, %s %s\n", addr.POBox, addr.PostalCode, addr.Town)
+//	}
+func (t *TaxpayerDetails) ParsedPostalAddress() Address {
+	return parsePostalAddress(t.PostalAddress)
+}
+
+// parsePostalAddress parses a raw postal address, see ParsedPostalAddress
+func parsePostalAddress(raw string) Address {
+	addr := Address{Raw: raw}
+	trimmed := strings.TrimSpace(raw)
+
+	for _, pattern := range postalAddressPatterns {
+		match := pattern.FindStringSubmatch(trimmed)
+		if match == nil {
+			continue
+		}
+		addr.POBox = match[1]
+		addr.PostalCode = match[2]
+		town := strings.Trim(match[3], " \t,.-–")
+
+		// The postal code may follow the box after a comma, before the town
+		if addr.PostalCode == "" {
+			if fields := strings.Fields(town); len(fields) > 0 && isPostalCode(fields[0]) {
+				addr.PostalCode = fields[0]
+				town = strings.Trim(strings.Join(fields[1:], " "), " \t,.-–")
+			}
+		}
+		addr.Town = town
+		return addr
+	}
+
+	return addr
+}
+
+// isPostalCode returns true if s is a five-digit postal code
+func isPostalCode(s string) bool {
+	if len(s) != 5 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
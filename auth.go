@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -19,18 +20,52 @@ type authProvider struct {
 	token     string
 	expiresAt time.Time
 	mu        sync.RWMutex
+
+	// nextKey is the rotation counter for multiple API keys
+	nextKey uint64
+
+	// bearer is the caller-supplied bearer token, replaced by SetBearerToken
+	bearer atomic.Value
 }
 
 func newAuthProvider(config *Config) *authProvider {
-	return &authProvider{
+	a := &authProvider{
 		config: config,
 		client: &http.Client{
-			Timeout: config.Timeout,
+			Timeout:   config.Timeout,
+			Transport: config.transport(),
 		},
 	}
+	a.bearer.Store(config.BearerToken)
+	return a
+}
+
+// rotateKey returns the index of the API key a new request starts with,
+// rotating through Config.APIKeys
+func (a *authProvider) rotateKey() uint64 {
+	return atomic.AddUint64(&a.nextKey, 1) - 1
 }
 
-func (a *authProvider) Token(ctx context.Context) (string, error) {
+// Token returns the credential for a request
+//
+// keyIndex selects the key when several API keys are configured (see
+// rotateKey); it is ignored otherwise.
+func (a *authProvider) Token(ctx context.Context, keyIndex uint64) (string, error) {
+	if apiKey, ok := apiKeyFromContext(ctx); ok {
+		if err := ValidateAPIKey(apiKey); err != nil {
+			return "", err
+		}
+		return apiKey, nil
+	}
+
+	if token := a.bearer.Load().(string); token != "" {
+		return token, nil
+	}
+
+	if n := len(a.config.APIKeys); n > 0 {
+		return a.config.APIKeys[keyIndex%uint64(n)], nil
+	}
+
 	if a.config.APIKey != "" {
 		return a.config.APIKey, nil
 	}
@@ -48,12 +83,34 @@ func (a *authProvider) Token(ctx context.Context) (string, error) {
 
 func (a *authProvider) refresh(ctx context.Context) (string, error) {
 	a.mu.Lock()
-	defer a.mu.Unlock()
-
 	if a.token != "" && time.Until(a.expiresAt) > 30*time.Second {
-		return a.token, nil
+		token := a.token
+		a.mu.Unlock()
+		return token, nil
 	}
 
+	token, err := a.requestToken(ctx)
+	a.mu.Unlock()
+
+	// Report the attempt outside the lock so hooks may call TokenInfo
+	if a.config.TokenRefreshHook != nil {
+		a.config.TokenRefreshHook(err)
+	}
+
+	return token, err
+}
+
+// info reports the cached OAuth token's expiry and whether it is still
+// usable without a refresh
+func (a *authProvider) info() (time.Time, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.expiresAt, a.token != "" && time.Until(a.expiresAt) > 30*time.Second
+}
+
+// requestToken fetches a new OAuth token; callers must hold a.mu
+func (a *authProvider) requestToken(ctx context.Context) (string, error) {
 	if a.config.ClientID == "" || a.config.ClientSecret == "" {
 		return "", fmt.Errorf("client credentials not set")
 	}
@@ -98,6 +155,20 @@ func (a *authProvider) refresh(ctx context.Context) (string, error) {
 	return a.token, nil
 }
 
+// canFailover reports whether a request that failed with err should be
+// retried immediately with the next API key
+func (a *authProvider) canFailover(err error) bool {
+	if len(a.config.APIKeys) < 2 {
+		return false
+	}
+	switch err.(type) {
+	case *AuthenticationError, *RateLimitError:
+		return true
+	default:
+		return false
+	}
+}
+
 func parseExpiresIn(value interface{}) int {
 	switch v := value.(type) {
 	case float64:
@@ -30,3 +30,30 @@ func BenchmarkVerifyPIN(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkIsValidPINFormat(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if !IsValidPINFormat("P051234567A") {
+			b.Fatal("expected valid PIN")
+		}
+	}
+}
+
+func BenchmarkValidateAndNormalizePIN(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ValidateAndNormalizePIN("P051234567A"); err != nil {
+			b.Fatalf("ValidateAndNormalizePIN error = %v", err)
+		}
+	}
+}
+
+func BenchmarkValidateAndNormalizePINInvalid(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ValidateAndNormalizePIN("INVALID"); err == nil {
+			b.Fatal("expected invalid PIN")
+		}
+	}
+}
@@ -1,31 +1,58 @@
 package kra
 
 import (
-	"fmt"
+	"math"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/golang/groupcache/lru"
 )
 
+// CacheForever is a cache TTL meaning that entries never expire
+//
+// It is accepted wherever a cache TTL is configured, such as
+// WithCustomCacheTTLs or WithRawCacheTTL, and by CacheManager.Set. Entries
+// cached forever stay until they are evicted to make room, deleted, or the
+// cache is cleared, so reserve it for data that never changes, such as
+// historical filings. It is the largest representable duration, so it compares
+// as longer than any other TTL.
+const CacheForever time.Duration = math.MaxInt64
+
 // cacheEntry represents a cached item with expiration metadata
 type cacheEntry struct {
 	value      interface{}
-	expiration time.Time
+	expiration time.Time // zero for entries cached forever
 }
 
 // isExpired reports whether the entry has passed its TTL
 func (e *cacheEntry) isExpired() bool {
-	return time.Now().After(e.expiration)
+	return expired(e.expiration, time.Now())
+}
+
+// expired reports whether an expiration time has passed at now; the zero
+// time never expires
+func expired(expiration, now time.Time) bool {
+	return !expiration.IsZero() && now.After(expiration)
 }
 
 // CacheManager provides a groupcache-backed LRU cache with TTL semantics
 type CacheManager struct {
 	cache      *lru.Cache
+	expiries   map[string]time.Time // tracks live keys, since lru.Cache cannot be iterated
 	mu         sync.RWMutex
 	enabled    bool
 	debug      bool
 	maxEntries int
+	name       string
+	logger     Logger
+	hits       int64
+	misses     int64
+
+	// slowCompute and slowComputeHook report computations slower than the
+	// threshold; see SetSlowComputeThreshold
+	slowCompute     time.Duration
+	slowComputeHook func(key string, elapsed time.Duration)
 }
 
 // NewCacheManager creates a new cache manager backed by groupcache's LRU implementation
@@ -34,17 +61,29 @@ func NewCacheManager(enabled bool, debug bool, maxEntries int) *CacheManager {
 		maxEntries = 1024
 	}
 
-	var lruCache *lru.Cache
-	if enabled {
-		lruCache = lru.New(maxEntries)
-	}
-
-	return &CacheManager{
-		cache:      lruCache,
+	cm := &CacheManager{
 		enabled:    enabled,
 		debug:      debug,
 		maxEntries: maxEntries,
 	}
+	if enabled {
+		cm.reset()
+	}
+
+	return cm
+}
+
+// reset replaces the underlying LRU with an empty one
+//
+// The caller must hold the write lock (or have exclusive access).
+func (cm *CacheManager) reset() {
+	cm.cache = lru.New(cm.maxEntries)
+	cm.expiries = make(map[string]time.Time)
+	cm.cache.OnEvicted = func(key lru.Key, _ interface{}) {
+		if k, ok := key.(string); ok {
+			delete(cm.expiries, k)
+		}
+	}
 }
 
 // Get retrieves a value from the cache
@@ -61,30 +100,28 @@ func (cm *CacheManager) Get(key string) (interface{}, bool) {
 
 	value, ok := cm.cache.Get(key)
 	if !ok {
-		if cm.debug {
-			fmt.Printf("[Cache] MISS: %s\n", key)
-		}
+		cm.misses++
+		cm.debugf("[Cache] MISS: %s\n", key)
 		return nil, false
 	}
 
 	entry, _ := value.(*cacheEntry)
 	if entry == nil || entry.isExpired() {
+		cm.misses++
 		cm.cache.Remove(key)
-		if cm.debug {
-			fmt.Printf("[Cache] EXPIRED: %s\n", key)
-		}
+		cm.debugf("[Cache] EXPIRED: %s\n", key)
 		return nil, false
 	}
 
-	if cm.debug {
-		fmt.Printf("[Cache] HIT: %s\n", key)
-	}
+	cm.hits++
+	cm.debugf("[Cache] HIT: %s\n", key)
 	return entry.value, true
 }
 
 // Set stores a value in the cache with the specified TTL
 //
-// If TTL is 0 or negative, the entry will never expire (not recommended).
+// If TTL is CacheForever, the entry never expires. Any other TTL that is 0 or
+// negative expires the entry immediately.
 func (cm *CacheManager) Set(key string, value interface{}, ttl time.Duration) {
 	if !cm.enabled {
 		return
@@ -93,15 +130,18 @@ func (cm *CacheManager) Set(key string, value interface{}, ttl time.Duration) {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	entry := &cacheEntry{
-		value:      value,
-		expiration: time.Now().Add(ttl),
+	entry := &cacheEntry{value: value}
+	if ttl != CacheForever {
+		entry.expiration = time.Now().Add(ttl)
 	}
 
 	cm.cache.Add(key, entry)
+	cm.expiries[key] = entry.expiration
 
-	if cm.debug {
-		fmt.Printf("[Cache] SET: %s (TTL: %v)\n", key, ttl)
+	if ttl == CacheForever {
+		cm.debugf("[Cache] SET: %s (TTL: forever)\n", key)
+	} else {
+		cm.debugf("[Cache] SET: %s (TTL: %v)\n", key, ttl)
 	}
 }
 
@@ -116,9 +156,7 @@ func (cm *CacheManager) Delete(key string) {
 
 	cm.cache.Remove(key)
 
-	if cm.debug {
-		fmt.Printf("[Cache] DELETE: %s\n", key)
-	}
+	cm.debugf("[Cache] DELETE: %s\n", key)
 }
 
 // Clear removes all entries from the cache
@@ -130,11 +168,9 @@ func (cm *CacheManager) Clear() {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	cm.cache = lru.New(cm.maxEntries)
+	cm.reset()
 
-	if cm.debug {
-		fmt.Println("[Cache] CLEAR: All entries removed")
-	}
+	cm.debugf("[Cache] CLEAR: All entries removed\n")
 }
 
 // GetOrSet retrieves a value from cache or computes it using the provided function
@@ -143,7 +179,10 @@ func (cm *CacheManager) Clear() {
 // If the value is not in cache, it calls the compute function, stores the result,
 // and returns it.
 //
-// The compute function is called outside the lock to prevent deadlocks.
+// The compute function is called outside the lock to prevent deadlocks. Its
+// duration is logged in debug mode, and computations slower than the
+// threshold set with SetSlowComputeThreshold are always logged and reported to
+// its hook.
 func (cm *CacheManager) GetOrSet(
 	key string,
 	compute func() (interface{}, error),
@@ -155,7 +194,7 @@ func (cm *CacheManager) GetOrSet(
 	}
 
 	// Compute the value (outside the lock)
-	value, err := compute()
+	value, err := cm.timeCompute(key, compute)
 	if err != nil {
 		return nil, err
 	}
@@ -178,6 +217,78 @@ func (cm *CacheManager) Size() int {
 	return cm.cache.Len()
 }
 
+// SetSlowComputeThreshold reports computations slower than threshold
+//
+// Computations run by GetOrSet that take longer than threshold are logged with
+// their key and duration, even outside debug mode, and passed to onSlow, which
+// may be nil to only log. A threshold of 0 turns reporting off. Call it before
+// the cache is shared between goroutines; clients configure it through
+// WithSlowCacheCompute.
+func (cm *CacheManager) SetSlowComputeThreshold(threshold time.Duration, onSlow func(key string, elapsed time.Duration)) {
+	cm.slowCompute = threshold
+	cm.slowComputeHook = onSlow
+}
+
+// timeCompute runs compute and reports how long it took
+func (cm *CacheManager) timeCompute(key string, compute func() (interface{}, error)) (interface{}, error) {
+	start := time.Now()
+	value, err := compute()
+	cm.observeCompute(key, time.Since(start))
+	return value, err
+}
+
+// observeCompute reports how long a computation of key took
+func (cm *CacheManager) observeCompute(key string, elapsed time.Duration) {
+	if cm.slowCompute <= 0 || elapsed <= cm.slowCompute {
+		cm.debugf("[Cache] COMPUTE: %s took %v\n", key, elapsed)
+		return
+	}
+	logf(cm.logger, cm.name, "[Cache] SLOW COMPUTE: %s took %v (threshold %v)\n", key, elapsed, cm.slowCompute)
+	if cm.slowComputeHook != nil {
+		cm.slowComputeHook(key, elapsed)
+	}
+}
+
+// debugf writes a debug log line when debug mode is enabled
+func (cm *CacheManager) debugf(format string, args ...interface{}) {
+	if cm.debug {
+		logf(cm.logger, cm.name, format, args...)
+	}
+}
+
+// lookupStats returns the number of cache hits and misses since creation
+func (cm *CacheManager) lookupStats() (hits, misses int64) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	return cm.hits, cm.misses
+}
+
+// Keys returns the keys of all non-expired entries currently in the cache
+//
+// Keys are returned in sorted order. This is intended for debugging and does
+// not affect the recency of entries.
+func (cm *CacheManager) Keys() []string {
+	if !cm.enabled {
+		return nil
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	now := time.Now()
+	keys := make([]string, 0, len(cm.expiries))
+	for key, expiration := range cm.expiries {
+		if expired(expiration, now) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 // GenerateCacheKey creates a cache key from operation name and parameters
 //
 // This is a helper function to create consistent cache keys across the SDK.
@@ -1,7 +1,11 @@
 package kra
 
 import (
+	"bytes"
 	"fmt"
+	"log"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -59,6 +63,43 @@ func TestCacheManager_Expiration(t *testing.T) {
 	}
 }
 
+func TestCacheManager_CacheForever(t *testing.T) {
+	cm := newTestCacheManager(true)
+	cm.Set("forever", "value", CacheForever)
+	cm.Set("hour", "value", time.Hour)
+
+	if _, found := cm.Get("forever"); !found {
+		t.Fatal("expected forever entry to be cached")
+	}
+
+	// No amount of elapsed time expires a forever entry
+	for _, later := range []time.Duration{25 * time.Hour, 100 * 365 * 24 * time.Hour} {
+		now := time.Now().Add(later)
+		if expired(cm.expiries["forever"], now) {
+			t.Fatalf("forever entry expired after %v", later)
+		}
+		if !expired(cm.expiries["hour"], now) {
+			t.Fatalf("hour entry survived %v", later)
+		}
+	}
+	assertKeys(t, cm.Keys(), "forever", "hour")
+
+	if err := ValidateCacheTTL(CacheForever); err != nil {
+		t.Fatalf("ValidateCacheTTL(CacheForever) error = %v", err)
+	}
+	if err := ValidateCacheTTL(-1); err == nil {
+		t.Fatal("expected negative TTLs to be rejected")
+	}
+	client, err := NewClient(WithAPIKey(testAPIKey), WithCustomCacheTTLs(time.Hour, time.Hour, time.Minute, CacheForever, CacheForever))
+	if err != nil {
+		t.Fatalf("NewClient() with CacheForever error = %v", err)
+	}
+	defer client.Close()
+	if client.config.NILReturnTTL != CacheForever {
+		t.Fatalf("NILReturnTTL = %v, want CacheForever", client.config.NILReturnTTL)
+	}
+}
+
 func TestCacheManager_Delete(t *testing.T) {
 	cm := newTestCacheManager(true)
 
@@ -248,6 +289,40 @@ func TestCacheManager_EvictsLeastRecentlyUsed(t *testing.T) {
 	}
 }
 
+func TestCacheManager_SlowComputeThreshold(t *testing.T) {
+	var buf bytes.Buffer
+	var slowKeys []string
+	cm := newTestCacheManager(true)
+	cm.logger = log.New(&buf, "", 0)
+	cm.SetSlowComputeThreshold(10*time.Millisecond, func(key string, elapsed time.Duration) {
+		if elapsed < 10*time.Millisecond {
+			t.Errorf("hook fired for %s after only %v", key, elapsed)
+		}
+		slowKeys = append(slowKeys, key)
+	})
+
+	if _, err := cm.GetOrSet("fast", func() (interface{}, error) { return "v", nil }, time.Hour); err != nil {
+		t.Fatalf("GetOrSet() error = %v", err)
+	}
+	if len(slowKeys) != 0 || buf.Len() != 0 {
+		t.Fatalf("expected fast compute to go unreported, got hooks %v and log %q", slowKeys, buf.String())
+	}
+
+	slow := func() (interface{}, error) {
+		time.Sleep(30 * time.Millisecond)
+		return "v", nil
+	}
+	if _, err := cm.GetOrSet("slow", slow, time.Hour); err != nil {
+		t.Fatalf("GetOrSet() error = %v", err)
+	}
+	if len(slowKeys) != 1 || slowKeys[0] != "slow" {
+		t.Fatalf("expected hook for slow key, got %v", slowKeys)
+	}
+	if !strings.Contains(buf.String(), "SLOW COMPUTE: slow") {
+		t.Fatalf("expected slow compute log, got %q", buf.String())
+	}
+}
+
 func TestCacheManager_DebugLogging(t *testing.T) {
 	cm := NewCacheManager(true, true, 4)
 	cm.Set("key", "value", time.Millisecond)
@@ -255,3 +330,98 @@ func TestCacheManager_DebugLogging(t *testing.T) {
 	cm.Delete("key")
 	cm.Clear()
 }
+
+func TestCacheManager_Keys(t *testing.T) {
+	cm := NewCacheManager(true, false, 3)
+
+	cm.Set("b", "B", time.Hour)
+	cm.Set("a", "A", time.Hour)
+	cm.Set("c", "C", time.Hour)
+	assertKeys(t, cm.Keys(), "a", "b", "c")
+
+	cm.Delete("b")
+	assertKeys(t, cm.Keys(), "a", "c")
+
+	// "a" is now least recently used and gets evicted
+	cm.Set("d", "D", time.Hour)
+	cm.Set("e", "E", time.Hour)
+	assertKeys(t, cm.Keys(), "c", "d", "e")
+
+	cm.Set("short", "S", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if keys := cm.Keys(); len(keys) != 2 {
+		t.Fatalf("expected expired key to be excluded, got %v", keys)
+	}
+
+	cm.Clear()
+	assertKeys(t, cm.Keys())
+
+	if keys := newTestCacheManager(false).Keys(); keys != nil {
+		t.Fatalf("expected nil keys for disabled cache, got %v", keys)
+	}
+}
+
+func assertKeys(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Keys() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Keys() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestCacheCodecsRoundTripResults(t *testing.T) {
+	at := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	meta := ResponseMetadata{ResponseCode: "200", RequestID: "req-1", Attempts: 2}
+	extra := map[string]interface{}{"source": "test"}
+
+	values := []interface{}{
+		&PINVerificationResult{
+			PINNumber: "P051234567A", IsValid: true, TaxpayerName: "John Doe", Status: "active",
+			Obligations:    []TaxObligation{{ObligationID: "OBL-1", ObligationType: "VAT", IsActive: true}},
+			AdditionalData: extra, VerifiedAt: at, Metadata: meta,
+		},
+		&TCCVerificationResult{
+			TCCNumber: "TCC123456", IsValid: true, PINNumber: "P051234567A", ExpiryDate: "2025-12-31",
+			AdditionalData: extra, VerifiedAt: at, Metadata: meta,
+		},
+		&EslipValidationResult{
+			EslipNumber: "1234567890", IsValid: true, Amount: 1500.5, Currency: "KES", Status: "paid",
+			AdditionalData: extra, ValidatedAt: at, Metadata: meta,
+		},
+		&NILReturnResult{
+			Success: true, PINNumber: "P051234567A", Period: "202401", Status: "accepted",
+			AdditionalData: extra, FiledAt: at, Metadata: meta,
+		},
+		&ObligationResult{
+			Success: true, PINNumber: "P051234567A", ObligationID: "OBL-1", Status: "registered",
+			AdditionalData: extra, ProcessedAt: at, Metadata: meta,
+		},
+		&TaxpayerDetails{
+			PINNumber: "P051234567A", TaxpayerName: "John Doe", Status: "active",
+			Obligations:    []TaxObligation{{ObligationID: "OBL-1", ObligationType: "PAYE"}},
+			AdditionalData: extra, Metadata: meta,
+		},
+	}
+
+	for _, codec := range []CacheCodec{JSONCodec{}, GobCodec{}} {
+		for _, value := range values {
+			name := fmt.Sprintf("%T/%T", codec, value)
+			data, err := codec.Encode(value)
+			if err != nil {
+				t.Fatalf("%s: Encode() error = %v", name, err)
+			}
+
+			decoded := reflect.New(reflect.TypeOf(value).Elem()).Interface()
+			if err := codec.Decode(data, decoded); err != nil {
+				t.Fatalf("%s: Decode() error = %v", name, err)
+			}
+			if !reflect.DeepEqual(decoded, value) {
+				t.Fatalf("%s: round trip mismatch\n got  %+v\n want %+v", name, decoded, value)
+			}
+		}
+	}
+}
@@ -2,7 +2,11 @@ package kra
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -34,8 +38,37 @@ type Client struct {
 	cacheManager *CacheManager
 	closed       bool
 	mu           sync.RWMutex
+
+	// done is closed by Close; batches tracked in batches stop dispatching
+	// when it fires, and Close waits for them before releasing resources
+	done    chan struct{}
+	batches sync.WaitGroup
+
+	// inflight deduplicates concurrent uncached lookups of the same cache key
+	inflight flightGroup
 }
 
+// ErrClosedDuringBatch is returned by batch methods when the client is closed
+// while the batch is running
+var ErrClosedDuringBatch = errors.New("client closed during batch")
+
+// ErrTCCPINMismatch is returned by VerifyTCC when strict TCC PIN matching is
+// enabled and KRA reports the certificate under a different PIN
+var ErrTCCPINMismatch = errors.New("TCC PIN does not match the requested PIN")
+
+// ErrAborted is returned by requests that were in flight, or started, after
+// Abort was called. It wraps context.Canceled, so errors.Is(err,
+// context.Canceled) also reports true.
+var ErrAborted = fmt.Errorf("client aborted: %w", context.Canceled)
+
+// ErrHistoryTruncated is returned by GetObligationHistory, together with the
+// records read so far, when Config.ObligationHistoryMaxPages pages have been
+// read and KRA still reports further pages
+var ErrHistoryTruncated = errors.New("obligation history truncated at max pages")
+
+// errClientClosed is returned by operations on a closed client
+var errClientClosed = errors.New("client is closed")
+
 // NewClient creates a new KRA Connect client
 //
 // The client must be configured with at least an API key using WithAPIKey().
@@ -60,10 +93,36 @@ func NewClient(opts ...Option) (*Client, error) {
 		}
 	}
 
+	return newClient(config)
+}
+
+// NewClientFromConfig creates a client from a Config value
+//
+// Options remain the primary way to configure a client; this constructor
+// suits configuration loaded into the struct directly, for example from a
+// file. Fields are used as given, so start from *DefaultConfig() rather than
+// a zero Config to keep the defaults for anything the source omits. The
+// config is validated like NewClient's and copied, so later changes to cfg
+// do not affect the client.
+//
+// Example:
+//
+//	cfg := *kra.DefaultConfig()
+//	if err := yaml.Unmarshal(data, &cfg); err != nil {
+//	    log.Fatal(err)
+//	}
+//	client, err := kra.NewClientFromConfig(cfg)
+func NewClientFromConfig(cfg Config) (*Client, error) {
+	return newClient(cfg.clone())
+}
+
+// newClient validates config and builds a client around it
+func newClient(config *Config) (*Client, error) {
 	// Validate config
 	if err := config.Validate(); err != nil {
 		return nil, err
 	}
+	config.logAdvisories()
 
 	// Create components
 	rateLimiter := NewRateLimiter(
@@ -73,7 +132,14 @@ func NewClient(opts ...Option) (*Client, error) {
 		config.DebugMode,
 	)
 
-	cacheManager := NewCacheManager(config.CacheEnabled, config.DebugMode, config.CacheMaxEntries)
+	rateLimiter.name = config.ClientName
+	rateLimiter.logger = config.Logger
+
+	// Dry-run results are synthetic and must never be cached
+	cacheManager := NewCacheManager(config.CacheEnabled && !config.DryRun, config.DebugMode, config.CacheMaxEntries)
+	cacheManager.name = config.ClientName
+	cacheManager.logger = config.Logger
+	cacheManager.SetSlowComputeThreshold(config.SlowCacheCompute, config.SlowCacheComputeHook)
 
 	httpClient := NewHTTPClient(config, rateLimiter, cacheManager)
 
@@ -82,12 +148,13 @@ func NewClient(opts ...Option) (*Client, error) {
 		httpClient:   httpClient,
 		rateLimiter:  rateLimiter,
 		cacheManager: cacheManager,
+		done:         make(chan struct{}),
 	}, nil
 }
 
 // VerifyPIN verifies a KRA PIN number
 //
-// The PIN must be in the format: P followed by 9 digits and a letter (e.g., P051234567A).
+// The PIN must be in the format: A or P followed by 9 digits and a letter (e.g., P051234567A).
 // Results are cached according to the configured PIN verification TTL.
 //
 // Example:
@@ -112,48 +179,123 @@ func (c *Client) VerifyPIN(ctx context.Context, pin string) (*PINVerificationRes
 	}
 
 	// Check cache
-	cacheKey := GenerateCacheKey("pin_verification", normalizedPIN)
+	cacheKey := c.cacheKey(ctx, "pin_verification", normalizedPIN)
 	if cached, found := c.cacheManager.Get(cacheKey); found {
 		if result, ok := cached.(*PINVerificationResult); ok {
-			return result, nil
+			return result.tagged(ctx), nil
 		}
+		c.evictMistyped(cacheKey, cached)
 	}
 
-	// Make API request
-	apiResp, err := c.httpClient.Post(ctx, "/checker/v1/pinbypin", map[string]string{
-		"KRAPIN": normalizedPIN,
+	// Concurrent lookups of the same key share one in-flight request
+	shared, err := c.share(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		// Make API request
+		apiResp, err := c.httpClient.Post(ctx, "/checker/v1/pinbypin", map[string]string{
+			"KRAPIN": normalizedPIN,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		data := apiResp.Data
+		result := &PINVerificationResult{
+			PINNumber:        normalizedPIN,
+			VerifiedAt:       time.Now(),
+			Metadata:         apiResp.Meta,
+			RawData:          data,
+			AdditionalData:   data,
+			TaxpayerName:     firstString(data, "taxpayerName", "TaxpayerName", "taxpayer_name"),
+			Status:           NormalizeStatusString(firstString(data, "pinStatus", "status", "TaxpayerStatus")),
+			TaxpayerType:     strings.ToLower(firstString(data, "taxpayerType", "TaxpayerType", "taxpayer_type")),
+			RegistrationDate: firstString(data, "registrationDate", "RegistrationDate", "registration_date"),
+		}
+
+		if pinValue := firstString(data, "kraPin", "KRAPIN", "pin"); pinValue != "" {
+			result.PINNumber = pinValue
+		}
+
+		if isValid, ok := firstBool(data, "isValid", "IsValid"); ok {
+			result.IsValid = isValid
+		} else {
+			result.IsValid = inferValidityFromStatus(result.Status)
+		}
+
+		if err := c.postProcess(ctx, OperationPINVerification, result); err != nil {
+			return nil, err
+		}
+
+		// Cache result
+		c.cacheManager.Set(cacheKey, result, c.config.PINVerificationTTL)
+
+		return result, nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	data := apiResp.Data
-	result := &PINVerificationResult{
-		PINNumber:        normalizedPIN,
-		VerifiedAt:       time.Now(),
-		Metadata:         apiResp.Meta,
-		RawData:          data,
-		AdditionalData:   data,
-		TaxpayerName:     firstString(data, "taxpayerName", "TaxpayerName", "taxpayer_name"),
-		Status:           strings.ToLower(firstString(data, "pinStatus", "status", "TaxpayerStatus")),
-		TaxpayerType:     strings.ToLower(firstString(data, "taxpayerType", "TaxpayerType", "taxpayer_type")),
-		RegistrationDate: firstString(data, "registrationDate", "RegistrationDate", "registration_date"),
+	return shared.(*PINVerificationResult).tagged(ctx), nil
+}
+
+// VerifyPINWithOptions verifies a KRA PIN, optionally fetching additional data
+//
+// With zero options it behaves exactly like VerifyPIN. When IncludeObligations
+// is set and the PIN is valid, the taxpayer's obligations are fetched as well
+// and returned on the result, without the full profile lookup performed by
+// GetTaxpayerDetails. Results with obligations are cached separately from
+// plain verifications, using the PIN verification TTL.
+//
+// Example:
+//
+//	result, err := client.VerifyPINWithOptions(ctx, "P051234567A", kra.PINVerifyOptions{
+//	    IncludeObligations: true,
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	fmt.Printf("%s has %d obligations\n", result.TaxpayerName, len(result.Obligations))
+func (c *Client) VerifyPINWithOptions(ctx context.Context, pin string, opts PINVerifyOptions) (*PINVerificationResult, error) {
+	if !opts.IncludeObligations {
+		return c.VerifyPIN(ctx, pin)
 	}
 
-	if pinValue := firstString(data, "kraPin", "KRAPIN", "pin"); pinValue != "" {
-		result.PINNumber = pinValue
+	if err := c.checkClosed(); err != nil {
+		return nil, err
 	}
 
-	if isValid, ok := firstBool(data, "isValid", "IsValid"); ok {
-		result.IsValid = isValid
-	} else {
-		result.IsValid = inferValidityFromStatus(result.Status)
+	normalizedPIN, err := ValidateAndNormalizePIN(pin)
+	if err != nil {
+		return nil, err
 	}
 
-	// Cache result
-	c.cacheManager.Set(cacheKey, result, c.config.PINVerificationTTL)
+	cacheKey := c.cacheKey(ctx, "pin_verification_obligations", normalizedPIN)
+	if cached, found := c.cacheManager.Get(cacheKey); found {
+		if result, ok := cached.(*PINVerificationResult); ok {
+			return result.tagged(ctx), nil
+		}
+		c.evictMistyped(cacheKey, cached)
+	}
 
-	return result, nil
+	verified, err := c.VerifyPIN(ctx, normalizedPIN)
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy so the plain verification result in the cache is left untouched;
+	// tags belong to this call, not to the cached entry
+	result := *verified
+	result.ResultTags = nil
+	if result.IsValid {
+		obligations, _, err := c.fetchObligations(ctx, normalizedPIN)
+		if err != nil {
+			return nil, err
+		}
+		result.Obligations = obligations
+	}
+
+	c.cacheManager.Set(cacheKey, &result, c.config.PINVerificationTTL)
+
+	return result.tagged(ctx), nil
 }
 
 // VerifyTCC verifies a Tax Compliance Certificate
@@ -195,56 +337,83 @@ func (c *Client) VerifyTCC(ctx context.Context, req *TCCVerificationRequest) (*T
 	}
 
 	// Check cache
-	cacheKey := GenerateCacheKey("tcc_verification", normalizedPIN+"_"+normalizedTCC)
+	cacheKey := c.cacheKey(ctx, "tcc_verification", normalizedPIN+"_"+normalizedTCC)
 	if cached, found := c.cacheManager.Get(cacheKey); found {
 		if result, ok := cached.(*TCCVerificationResult); ok {
-			return result, nil
+			return c.checkTCCPIN(result.tagged(ctx))
 		}
+		c.evictMistyped(cacheKey, cached)
 	}
 
-	// Make API request
-	apiResp, err := c.httpClient.Post(ctx, "/v1/kra-tcc/validate", map[string]string{
-		"kraPIN":    normalizedPIN,
-		"tccNumber": normalizedTCC,
-	})
-	if err != nil {
-		return nil, err
-	}
+	// Concurrent lookups of the same key share one in-flight request
+	shared, err := c.share(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		// Make API request
+		apiResp, err := c.httpClient.Post(ctx, "/v1/kra-tcc/validate", map[string]string{
+			"kraPIN":    normalizedPIN,
+			"tccNumber": normalizedTCC,
+		})
+		if err != nil {
+			return nil, err
+		}
 
-	// Parse response
-	result := &TCCVerificationResult{
-		TCCNumber:      normalizedTCC,
-		PINNumber:      normalizedPIN,
-		VerifiedAt:     time.Now(),
-		Metadata:       apiResp.Meta,
-		RawData:        apiResp.Data,
-		AdditionalData: apiResp.Data,
-		TaxpayerName:   firstString(apiResp.Data, "taxpayerName", "TaxpayerName", "taxpayer_name"),
-		IssueDate:      firstString(apiResp.Data, "issueDate", "IssueDate"),
-		ExpiryDate:     firstString(apiResp.Data, "expiryDate", "ExpiryDate"),
-		Status:         strings.ToLower(firstString(apiResp.Data, "status", "tccStatus")),
-		CertificateType: firstString(apiResp.Data,
-			"certificateType",
-			"CertificateType"),
-	}
+		// Parse response
+		result := &TCCVerificationResult{
+			TCCNumber:      normalizedTCC,
+			PINNumber:      normalizedPIN,
+			RequestedPIN:   normalizedPIN,
+			VerifiedAt:     time.Now(),
+			Metadata:       apiResp.Meta,
+			RawData:        apiResp.Data,
+			AdditionalData: apiResp.Data,
+			TaxpayerName:   firstString(apiResp.Data, "taxpayerName", "TaxpayerName", "taxpayer_name"),
+			IssueDate:      firstString(apiResp.Data, "issueDate", "IssueDate"),
+			ExpiryDate:     firstString(apiResp.Data, "expiryDate", "ExpiryDate"),
+			Status:         NormalizeStatusString(firstString(apiResp.Data, "status", "tccStatus")),
+			CertificateType: firstString(apiResp.Data,
+				"certificateType",
+				"CertificateType"),
+		}
 
-	if pin := firstString(apiResp.Data, "kraPin", "TaxpayerPIN", "pin_number"); pin != "" {
-		result.PINNumber = pin
-	}
+		if pin := firstString(apiResp.Data, "kraPin", "TaxpayerPIN", "pin_number"); pin != "" {
+			result.PINNumber = pin
+		}
 
-	if valid, ok := firstBool(apiResp.Data, "isValid", "IsValid"); ok {
-		result.IsValid = valid
-	} else {
-		result.IsValid = inferValidityFromStatus(result.Status)
-	}
+		if valid, ok := firstBool(apiResp.Data, "isValid", "IsValid"); ok {
+			result.IsValid = valid
+		} else {
+			result.IsValid = inferValidityFromStatus(result.Status)
+		}
+
+		if expired, ok := firstBool(apiResp.Data, "isExpired", "IsExpired"); ok {
+			result.IsExpired = expired
+		}
+
+		if err := c.postProcess(ctx, OperationTCCVerification, result); err != nil {
+			return nil, err
+		}
+
+		// Cache result
+		ttl := c.config.TCCVerificationTTL
+		if advised := result.CacheAdvisory(); c.config.ExpiryAwareTTL && advised > 0 && advised < ttl {
+			ttl = advised
+		}
+		c.cacheManager.Set(cacheKey, result, ttl)
 
-	if expired, ok := firstBool(apiResp.Data, "isExpired", "IsExpired"); ok {
-		result.IsExpired = expired
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Cache result
-	c.cacheManager.Set(cacheKey, result, c.config.TCCVerificationTTL)
+	return c.checkTCCPIN(shared.(*TCCVerificationResult).tagged(ctx))
+}
 
+// checkTCCPIN enforces WithStrictTCCPINMatch on a TCC verification result
+func (c *Client) checkTCCPIN(result *TCCVerificationResult) (*TCCVerificationResult, error) {
+	if c.config.StrictTCCPINMatch && result.PINMismatch() {
+		return nil, fmt.Errorf("%w: TCC %s is issued to %s, requested %s",
+			ErrTCCPINMismatch, result.TCCNumber, result.PINNumber, result.RequestedPIN)
+	}
 	return result, nil
 }
 
@@ -263,6 +432,69 @@ func (c *Client) VerifyTCC(ctx context.Context, req *TCCVerificationRequest) (*T
 //	    fmt.Printf("Payment confirmed: %.2f %s\n", result.Amount, result.Currency)
 //	}
 func (c *Client) ValidateEslip(ctx context.Context, eslipNumber string) (*EslipValidationResult, error) {
+	return c.validateEslip(ctx, eslipNumber, "")
+}
+
+// ValidateEslipExpecting validates an e-slip and checks it covers an expected payment
+//
+// The e-slip is validated as by ValidateEslip, then compared with expected
+// using CheckPayment. The returned result carries the outcome in
+// PaymentMatch; a mismatch is not an error. Errors are only returned when the
+// e-slip number or expectation is malformed, or validation itself fails.
+//
+// Example:
+//
+//	result, err := client.ValidateEslipExpecting(ctx, "1234567890", kra.ExpectedPayment{
+//	    Amount:         5000,
+//	    Currency:       "KES",
+//	    ObligationType: "VAT",
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	if !result.PaymentMatch.Matches {
+//	    fmt.Println("payment rejected:", result.PaymentMatch.Mismatches)
+//	}
+func (c *Client) ValidateEslipExpecting(ctx context.Context, eslipNumber string, expected ExpectedPayment) (*EslipValidationResult, error) {
+	if err := expected.validate(); err != nil {
+		return nil, err
+	}
+
+	result, err := c.ValidateEslip(ctx, eslipNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	// The result may be shared with the cache, so annotate a copy
+	copied := *result
+	match := copied.CheckPayment(expected)
+	copied.PaymentMatch = &match
+	return &copied, nil
+}
+
+// ValidateEslipForPIN validates an e-slip on behalf of a specific taxpayer
+//
+// The normalized PIN is sent with the e-slip number so the API can tell apart
+// e-slips whose numbers overlap across taxpayers. Results are cached
+// separately from ValidateEslip, per e-slip and PIN.
+//
+// Example:
+//
+//	result, err := client.ValidateEslipForPIN(ctx, "1234567890", "P051234567A")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (c *Client) ValidateEslipForPIN(ctx context.Context, eslipNumber, pin string) (*EslipValidationResult, error) {
+	normalizedPIN, err := ValidateAndNormalizePIN(pin)
+	if err != nil {
+		return nil, err
+	}
+	return c.validateEslip(ctx, eslipNumber, normalizedPIN)
+}
+
+// validateEslip validates an e-slip, scoped to a taxpayer when pin is non-empty
+func (c *Client) validateEslip(ctx context.Context, eslipNumber, pin string) (*EslipValidationResult, error) {
 	if err := c.checkClosed(); err != nil {
 		return nil, err
 	}
@@ -272,23 +504,92 @@ func (c *Client) ValidateEslip(ctx context.Context, eslipNumber string) (*EslipV
 		return nil, err
 	}
 
+	payload := map[string]string{"EslipNumber": eslipNumber}
+	cacheKey := c.cacheKey(ctx, "eslip_validation", eslipNumber)
+	if pin != "" {
+		payload["TaxpayerPIN"] = pin
+		cacheKey = c.cacheKey(ctx, "eslip_validation_pin", eslipNumber, pin)
+	}
+
 	// Check cache
-	cacheKey := GenerateCacheKey("eslip_validation", eslipNumber)
 	if cached, found := c.cacheManager.Get(cacheKey); found {
 		if result, ok := cached.(*EslipValidationResult); ok {
-			return result, nil
+			return result.tagged(ctx), nil
 		}
+		c.evictMistyped(cacheKey, cached)
 	}
 
-	// Make API request
-	apiResp, err := c.httpClient.Post(ctx, "/payment/checker/v1/eslip", map[string]string{
-		"EslipNumber": eslipNumber,
+	// Concurrent lookups of the same key share one in-flight request
+	shared, err := c.share(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		// Make API request
+		apiResp, err := c.httpClient.Post(ctx, "/payment/checker/v1/eslip", payload)
+		if err != nil {
+			return nil, err
+		}
+
+		result := parseEslipResult(apiResp.Data, apiResp.Meta)
+		if result.EslipNumber == "" {
+			result.EslipNumber = eslipNumber
+		}
+		if result.TaxpayerPIN == "" {
+			result.TaxpayerPIN = pin
+		}
+
+		if err := c.postProcess(ctx, OperationEslipValidation, result); err != nil {
+			return nil, err
+		}
+
+		// Cache result
+		c.cacheManager.Set(cacheKey, result, c.config.EslipValidationTTL)
+
+		return result, nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	data := apiResp.Data
+	return shared.(*EslipValidationResult).tagged(ctx), nil
+}
+
+// EslipExists reports whether an e-slip number is known to KRA
+//
+// A not-found response yields false rather than an error; other failures are
+// returned as errors. GavaConnect has no dedicated existence endpoint, so the
+// check is answered from a cached validation result when one is available
+// and otherwise shares ValidateEslip's request, caching the full result so a
+// follow-up ValidateEslip call is served from the cache.
+//
+// Example:
+//
+//	exists, err := client.EslipExists(ctx, "1234567890")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !exists {
+//	    fmt.Println("Unknown e-slip")
+//	}
+func (c *Client) EslipExists(ctx context.Context, eslipNumber string) (bool, error) {
+	if _, err := c.ValidateEslip(ctx, eslipNumber); err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// isNotFound reports whether err is an API error for a missing record
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusNotFound ||
+		strings.Contains(strings.ToLower(apiErr.Message), "not found")
+}
+
+// parseEslipResult maps an e-slip payload onto an EslipValidationResult
+func parseEslipResult(data map[string]interface{}, meta ResponseMetadata) *EslipValidationResult {
 	result := &EslipValidationResult{
 		EslipNumber:  firstString(data, "EslipNumber", "eslipNumber", "eslip", "eslip_number"),
 		TaxpayerPIN:  firstString(data, "taxpayerPin", "TaxpayerPIN", "taxpayer_pin"),
@@ -303,17 +604,13 @@ func (c *Client) ValidateEslip(ctx context.Context, eslipNumber string) (*EslipV
 		),
 		ObligationType:   firstString(data, "obligationType", "taxType", "obligation_type"),
 		ObligationPeriod: firstString(data, "obligationPeriod", "taxPeriod", "obligation_period"),
-		Status:           strings.ToLower(firstString(data, "status", "eslipStatus")),
+		Status:           NormalizeStatusString(firstString(data, "status", "eslipStatus")),
 		ValidatedAt:      time.Now(),
-		Metadata:         apiResp.Meta,
+		Metadata:         meta,
 		RawData:          data,
 		AdditionalData:   data,
 	}
 
-	if result.EslipNumber == "" {
-		result.EslipNumber = eslipNumber
-	}
-
 	if amount, ok := firstFloat64(data, "amount", "Amount"); ok {
 		result.Amount = amount
 	}
@@ -328,10 +625,7 @@ func (c *Client) ValidateEslip(ctx context.Context, eslipNumber string) (*EslipV
 		result.Currency = currency
 	}
 
-	// Cache result
-	c.cacheManager.Set(cacheKey, result, c.config.EslipValidationTTL)
-
-	return result, nil
+	return result
 }
 
 // FileNILReturn files a NIL return for a tax obligation
@@ -352,148 +646,681 @@ func (c *Client) ValidateEslip(ctx context.Context, eslipNumber string) (*EslipV
 //	    fmt.Printf("Reference: %s\n", result.ReferenceNumber)
 //	}
 func (c *Client) FileNILReturn(ctx context.Context, req *NILReturnRequest) (*NILReturnResult, error) {
-	if err := c.checkClosed(); err != nil {
+	result, err := c.fileNILReturn(ctx, req)
+	if err != nil {
 		return nil, err
 	}
+	result.ResultTags = resultTagsFromContext(ctx)
+	return result, nil
+}
 
-	if req == nil {
-		return nil, fmt.Errorf("request cannot be nil")
+// fileNILReturn files a NIL return without attaching context tags, so the
+// result can be cached
+func (c *Client) fileNILReturn(ctx context.Context, req *NILReturnRequest) (*NILReturnResult, error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
 	}
 
-	normalizedPIN, err := ValidateAndNormalizePIN(req.PINNumber)
+	normalizedPIN, err := validateNILReturnRequest(req)
 	if err != nil {
 		return nil, err
 	}
-	if req.ObligationCode <= 0 {
-		return nil, NewValidationError("obligation_code", "Obligation code must be positive")
-	}
-	if req.Month < 1 || req.Month > 12 {
-		return nil, NewValidationError("month", "Month must be between 1 and 12")
-	}
-	if req.Year < 2000 {
-		return nil, NewValidationError("year", "Year must be >= 2000")
-	}
-
-	payload := map[string]interface{}{
-		"TAXPAYERDETAILS": map[string]interface{}{
-			"TaxpayerPIN":    normalizedPIN,
-			"ObligationCode": req.ObligationCode,
-			"Month":          req.Month,
-			"Year":           req.Year,
-		},
-	}
 
-	apiResp, err := c.httpClient.Post(ctx, "/dtd/return/v1/nil", payload)
+	apiResp, err := c.httpClient.Post(ctx, "/dtd/return/v1/nil", nilReturnPayload(normalizedPIN, req, c.config.NILFieldNames))
 	if err != nil {
 		return nil, err
 	}
 
 	data := apiResp.Data
 	result := &NILReturnResult{
-		PINNumber:             normalizedPIN,
-		ObligationID:          fmt.Sprintf("%d", req.ObligationCode),
-		Period:                fmt.Sprintf("%04d%02d", req.Year, req.Month),
-		FiledAt:               time.Now(),
-		Metadata:              apiResp.Meta,
-		RawData:               data,
-		AdditionalData:        data,
-		ReferenceNumber:       firstString(data, "referenceNumber", "RefNumber"),
-		FilingDate:            firstString(data, "filingDate", "FilingDate"),
-		AcknowledgementNumber: firstString(data, "acknowledgementNumber", "AcknowledgementNumber"),
-		Status:                strings.ToLower(firstString(data, "status", "filingStatus")),
-		Message:               firstString(data, "message", "responseDesc"),
+		PINNumber:    normalizedPIN,
+		ObligationID: fmt.Sprintf("%d", req.ObligationCode),
+		Period:       fmt.Sprintf("%04d%02d", req.Year, req.Month),
+		FiledAt:      time.Now(),
+		Metadata:     apiResp.Meta,
 	}
+	applyNILReturnPayload(result, data, c.config.FilingSuccessPredicate)
 
-	if success, ok := firstBool(data, "success", "Success"); ok {
-		result.Success = success
-	} else {
-		result.Success = inferValidityFromStatus(result.Status)
+	if err := c.postProcess(ctx, OperationNILReturn, result); err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
 
-// GetTaxpayerDetails retrieves detailed taxpayer information
+// FilingSuccessPredicate decides from a filing's status whether it succeeded
 //
-// Results are cached according to the configured taxpayer details TTL.
+// It is consulted when KRA's response carries no explicit success flag.
+type FilingSuccessPredicate func(status string) bool
+
+// DefaultFilingSuccess is the default FilingSuccessPredicate
+//
+// Only statuses that normalize to StatusAccepted or StatusPending, such as
+// "accepted", "queued" or "processing", count as success. Unknown or empty
+// statuses are failures, so a filing is never reported as done on a status
+// the SDK does not recognize.
+func DefaultFilingSuccess(status string) bool {
+	switch NormalizeStatus(status) {
+	case StatusAccepted, StatusPending:
+		return true
+	}
+	return false
+}
+
+// applyNILReturnPayload fills the filing outcome fields of a NIL return result from its payload
+//
+// success decides the outcome when the payload has no explicit success flag;
+// nil means DefaultFilingSuccess.
+func applyNILReturnPayload(result *NILReturnResult, data map[string]interface{}, success FilingSuccessPredicate) {
+	result.RawData = data
+	result.AdditionalData = data
+	result.ReferenceNumber = firstString(data, "referenceNumber", "RefNumber")
+	result.FilingDate = firstString(data, "filingDate", "FilingDate")
+	result.AcknowledgementNumber = firstString(data, "acknowledgementNumber", "AcknowledgementNumber")
+	result.Status = NormalizeStatusString(firstString(data, "status", "filingStatus"))
+	result.Message = firstString(data, "message", "responseDesc")
+
+	if success == nil {
+		success = DefaultFilingSuccess
+	}
+	if explicit, ok := firstBool(data, "success", "Success"); ok {
+		result.Success = explicit
+	} else {
+		result.Success = success(result.Status)
+	}
+}
+
+// FileDueNILReturns files NIL returns for every obligation due in the given period
+//
+// The period must be in the format YYYYMM. The taxpayer's obligations are
+// fetched and a NIL return is filed for each NIL-eligible obligation (see
+// TaxObligation.IsNILEligible) whose registration covers the period.
+// Obligations without a numeric obligation code are skipped as well.
+//
+// Filing is idempotent within the client: successful filings are remembered
+// for the configured NIL return TTL, and a repeated call for the same period
+// returns the earlier result instead of filing again. Concurrent calls for the
+// same filing share one request rather than each filing. Filings are submitted
+// sequentially; on the first failure the results filed so far are returned
+// together with the error. If the client is closed meanwhile, no further
+// returns are filed and ErrClosedDuringBatch is returned.
 //
 // Example:
 //
-//	details, err := client.GetTaxpayerDetails(ctx, "P051234567A")
+//	results, err := client.FileDueNILReturns(ctx, "P051234567A", "202401")
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
 //
-//	fmt.Printf("Name: %s\n", details.GetDisplayName())
-//	fmt.Printf("Obligations: %d\n", len(details.Obligations))
-func (c *Client) GetTaxpayerDetails(ctx context.Context, pin string) (*TaxpayerDetails, error) {
-	if err := c.checkClosed(); err != nil {
+//	for _, result := range results {
+//	    fmt.Printf("%s: %s\n", result.ObligationID, result.ReferenceNumber)
+//	}
+func (c *Client) FileDueNILReturns(ctx context.Context, pin string, period string) ([]*NILReturnResult, error) {
+	endBatch, err := c.beginBatch()
+	if err != nil {
 		return nil, err
 	}
+	defer endBatch()
 
-	// Validate and normalize PIN
 	normalizedPIN, err := ValidateAndNormalizePIN(pin)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check cache
-	cacheKey := GenerateCacheKey("taxpayer_details", normalizedPIN)
-	if cached, found := c.cacheManager.Get(cacheKey); found {
-		if details, ok := cached.(*TaxpayerDetails); ok {
-			return details, nil
-		}
+	period = strings.TrimSpace(period)
+	if err := ValidatePeriod(period); err != nil {
+		return nil, err
 	}
+	year, _ := strconv.Atoi(period[:4])
+	month, _ := strconv.Atoi(period[4:])
 
-	profileResp, err := c.httpClient.Post(ctx, "/checker/v1/pinbypin", map[string]string{
-		"KRAPIN": normalizedPIN,
-	})
+	obligations, _, err := c.fetchObligations(ctx, normalizedPIN)
 	if err != nil {
 		return nil, err
 	}
 
-	obligationResp, err := c.httpClient.Post(ctx, "/dtd/checker/v1/obligation", map[string]string{
-		"taxPayerPin": normalizedPIN,
-	})
-	if err != nil {
-		return nil, err
+	var results []*NILReturnResult
+	filed := make(map[int]bool)
+	for i := range obligations {
+		obligation := &obligations[i]
+		code, ok := nilObligationCode(obligation)
+		if !ok || filed[code] || !obligation.coversPeriod(year, time.Month(month)) {
+			continue
+		}
+		filed[code] = true
+
+		if c.isClosing() {
+			return results, ErrClosedDuringBatch
+		}
+		result, err := c.fileNILReturnOnce(ctx, normalizedPIN, code, period)
+		if err != nil {
+			return results, batchItemError(err)
+		}
+		results = append(results, result.tagged(ctx))
 	}
 
-	profile := profileResp.Data
-	obligations := parseObligations(obligationResp.Data)
+	return results, nil
+}
 
-	extra := map[string]interface{}{
-		"profile":     profile,
-		"obligations": obligationResp.Data,
+// FileNILReturnForPeriods files NIL returns for one obligation across several periods
+//
+// Each period must be in the format YYYYMM. Results and errors are returned
+// in input order: a period that fails validation or filing has a nil result
+// and a non-nil error at its index. A period listed more than once is filed
+// only once, and every occurrence shares its result and error. Up to 10
+// periods are filed concurrently.
+//
+// Like FileDueNILReturns, filing is idempotent within the client: successful
+// filings are remembered for the configured NIL return TTL, so repeating a
+// call only files the periods that have not yet succeeded, and concurrent
+// calls for the same period share one filing. If the PIN or
+// obligation code is invalid, or the client is closed, every period reports
+// that error.
+//
+// Example:
+//
+//	periods := []string{"202401", "202402", "202403"}
+//	results, errs := client.FileNILReturnForPeriods(ctx, "P051234567A", 1, periods)
+//	for i, period := range periods {
+//	    if errs[i] != nil {
+//	        fmt.Printf("%s: %v\n", period, errs[i])
+//	        continue
+//	    }
+//	    fmt.Printf("%s: %s\n", period, results[i].ReferenceNumber)
+//	}
+func (c *Client) FileNILReturnForPeriods(ctx context.Context, pin string, obligationCode int, periods []string) ([]*NILReturnResult, []error) {
+	results := make([]*NILReturnResult, len(periods))
+	errs := make([]error, len(periods))
+	failAll := func(err error) ([]*NILReturnResult, []error) {
+		for i := range errs {
+			errs[i] = err
+		}
+		return results, errs
 	}
 
-	details := &TaxpayerDetails{
-		PINNumber:        normalizedPIN,
-		TaxpayerName:     firstString(profile, "taxpayerName", "TaxpayerName", "taxpayer_name"),
-		TaxpayerType:     strings.ToLower(firstString(profile, "taxpayerType", "TaxpayerType", "taxpayer_type")),
-		Status:           strings.ToLower(firstString(profile, "pinStatus", "status", "TaxpayerStatus")),
-		RegistrationDate: firstString(profile, "registrationDate", "RegistrationDate", "registration_date"),
-		BusinessName:     firstString(profile, "businessName", "BusinessName"),
-		TradingName:      firstString(profile, "tradingName", "TradingName"),
-		PostalAddress:    firstString(profile, "postalAddress", "PostalAddress"),
-		PhysicalAddress:  firstString(profile, "physicalAddress", "PhysicalAddress"),
-		EmailAddress:     firstString(profile, "emailAddress", "EmailAddress"),
-		PhoneNumber:      firstString(profile, "phoneNumber", "PhoneNumber"),
-		Obligations:      obligations,
-		AdditionalData:   extra,
-		RetrievedAt:      time.Now(),
-		Metadata:         profileResp.Meta,
-		RawData:          profile,
+	if err := c.checkBatchSize(len(periods)); err != nil {
+		return failAll(err)
 	}
 
-	if details.TaxpayerName == "" {
-		details.TaxpayerName = firstString(profile, "legalName", "BusinessName")
+	endBatch, err := c.beginBatch()
+	if err != nil {
+		return failAll(err)
 	}
+	defer endBatch()
 
-	// Cache result
-	c.cacheManager.Set(cacheKey, details, c.config.TaxpayerDetailsTTL)
+	normalizedPIN, err := ValidateAndNormalizePIN(pin)
+	if err != nil {
+		return failAll(err)
+	}
+	if obligationCode <= 0 {
+		return failAll(NewValidationError("obligation_code", "Obligation code must be positive"))
+	}
 
-	return details, nil
+	// Group the input indexes by period so duplicates are filed once
+	var unique []string
+	indexes := make(map[string][]int)
+	for i, period := range periods {
+		period = strings.TrimSpace(period)
+		if err := ValidatePeriod(period); err != nil {
+			errs[i] = err
+			continue
+		}
+		if _, seen := indexes[period]; !seen {
+			unique = append(unique, period)
+		}
+		indexes[period] = append(indexes[period], i)
+	}
+
+	workers := batchWorkers
+	if len(unique) < workers {
+		workers = len(unique)
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for period := range jobs {
+				var result *NILReturnResult
+				err := ctx.Err()
+				if err == nil {
+					result, err = c.fileNILReturnOnce(ctx, normalizedPIN, obligationCode, period)
+				}
+				if result != nil {
+					result = result.tagged(ctx)
+				}
+				// Each period owns distinct indexes, so no locking is needed
+				for _, i := range indexes[period] {
+					results[i], errs[i] = result, batchItemError(err)
+				}
+			}
+		}()
+	}
+
+	for _, period := range unique {
+		jobs <- period
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, errs
+}
+
+// fileNILReturnOnce files a NIL return for an already validated PIN,
+// obligation code and YYYYMM period, reusing a cached successful filing
+//
+// Concurrent calls for the same filing join one in-flight request, so a
+// return is never filed twice at once. The returned result is untagged and
+// may be shared with the cache.
+func (c *Client) fileNILReturnOnce(ctx context.Context, normalizedPIN string, code int, period string) (*NILReturnResult, error) {
+	cacheKey := c.cacheKey(ctx, "nil_return", normalizedPIN, strconv.Itoa(code), period)
+	if result, ok := c.cachedNILReturn(cacheKey); ok {
+		return result, nil
+	}
+
+	year, _ := strconv.Atoi(period[:4])
+	month, _ := strconv.Atoi(period[4:])
+
+	shared, err := c.share(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		// A filing that completed since the lookup above must not be repeated
+		if result, ok := c.cachedNILReturn(cacheKey); ok {
+			return result, nil
+		}
+
+		result, err := c.fileNILReturn(ctx, &NILReturnRequest{
+			PINNumber:      normalizedPIN,
+			ObligationCode: code,
+			Month:          month,
+			Year:           year,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if result.Success {
+			c.cacheManager.Set(cacheKey, result, c.config.NILReturnTTL)
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return shared.(*NILReturnResult), nil
+}
+
+// cachedNILReturn returns a remembered successful filing for cacheKey
+func (c *Client) cachedNILReturn(cacheKey string) (*NILReturnResult, bool) {
+	cached, found := c.cacheManager.Get(cacheKey)
+	if !found {
+		return nil, false
+	}
+	result, ok := cached.(*NILReturnResult)
+	if !ok {
+		c.evictMistyped(cacheKey, cached)
+	}
+	return result, ok
+}
+
+// nilObligationCode returns the numeric obligation code for an obligation that can be NIL-filed
+func nilObligationCode(o *TaxObligation) (int, bool) {
+	if !o.IsNILEligible() {
+		return 0, false
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(o.ObligationID))
+	if err != nil || code <= 0 {
+		return 0, false
+	}
+	return code, true
+}
+
+// BuildNILReturnPayload validates a NIL return request and builds the request
+// body sent to the NIL filing endpoint
+//
+// The payload is the nested TAXPAYERDETAILS structure expected by KRA:
+//
+//	{"TAXPAYERDETAILS": {"TaxpayerPIN": "P051234567A", "ObligationCode": 1, "Month": 1, "Year": 2024}}
+//
+// FileNILReturn sends exactly this payload unless the field names were
+// overridden with WithNILFieldNames, so the builder can be used to inspect or
+// test the request without making an API call.
+func BuildNILReturnPayload(req *NILReturnRequest) (map[string]interface{}, error) {
+	normalizedPIN, err := validateNILReturnRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return nilReturnPayload(normalizedPIN, req, DefaultNILFieldNames()), nil
+}
+
+// FieldNameMap holds the field names used in the NIL return request body
+//
+// Empty fields fall back to the defaults from DefaultNILFieldNames.
+type FieldNameMap struct {
+	Details        string
+	TaxpayerPIN    string
+	ObligationCode string
+	Month          string
+	Year           string
+}
+
+// DefaultNILFieldNames returns the field names KRA currently expects in the
+// NIL return request body
+func DefaultNILFieldNames() FieldNameMap {
+	return FieldNameMap{
+		Details:        "TAXPAYERDETAILS",
+		TaxpayerPIN:    "TaxpayerPIN",
+		ObligationCode: "ObligationCode",
+		Month:          "Month",
+		Year:           "Year",
+	}
+}
+
+// withDefaults fills empty names from DefaultNILFieldNames
+func (m FieldNameMap) withDefaults() FieldNameMap {
+	return m.overlay(DefaultNILFieldNames())
+}
+
+// overlay fills empty names in m from base
+func (m FieldNameMap) overlay(base FieldNameMap) FieldNameMap {
+	pick := func(name, fallback string) string {
+		if name = strings.TrimSpace(name); name != "" {
+			return name
+		}
+		return fallback
+	}
+	return FieldNameMap{
+		Details:        pick(m.Details, base.Details),
+		TaxpayerPIN:    pick(m.TaxpayerPIN, base.TaxpayerPIN),
+		ObligationCode: pick(m.ObligationCode, base.ObligationCode),
+		Month:          pick(m.Month, base.Month),
+		Year:           pick(m.Year, base.Year),
+	}
+}
+
+// nilReturnDetails is the typed form of the TAXPAYERDETAILS block
+type nilReturnDetails struct {
+	TaxpayerPIN    string
+	ObligationCode int
+	Month          int
+	Year           int
+}
+
+// toMap converts the details into the wire representation
+func (d nilReturnDetails) toMap(names FieldNameMap) map[string]interface{} {
+	return map[string]interface{}{
+		names.TaxpayerPIN:    d.TaxpayerPIN,
+		names.ObligationCode: d.ObligationCode,
+		names.Month:          d.Month,
+		names.Year:           d.Year,
+	}
+}
+
+// validateTCCRequest validates a TCC verification request's PIN and TCC number
+func validateTCCRequest(req *TCCVerificationRequest) error {
+	if req == nil {
+		return NewValidationError("request", "Verification request cannot be nil")
+	}
+	if _, err := ValidateAndNormalizePIN(req.KraPIN); err != nil {
+		return err
+	}
+	_, err := ValidateAndNormalizeTCC(req.TCCNumber)
+	return err
+}
+
+// validateNILReturnRequest validates a NIL return request and returns the normalized PIN
+func validateNILReturnRequest(req *NILReturnRequest) (string, error) {
+	if req == nil {
+		return "", fmt.Errorf("request cannot be nil")
+	}
+
+	normalizedPIN, err := ValidateAndNormalizePIN(req.PINNumber)
+	if err != nil {
+		return "", err
+	}
+	if req.ObligationCode <= 0 {
+		return "", NewValidationError("obligation_code", "Obligation code must be positive")
+	}
+	if req.Month < 1 || req.Month > 12 {
+		return "", NewValidationError("month", "Month must be between 1 and 12")
+	}
+	if req.Year < 2000 {
+		return "", NewValidationError("year", "Year must be >= 2000")
+	}
+
+	return normalizedPIN, nil
+}
+
+// nilReturnPayload builds the NIL return request body for an already validated request
+func nilReturnPayload(normalizedPIN string, req *NILReturnRequest, names FieldNameMap) map[string]interface{} {
+	names = names.withDefaults()
+	details := nilReturnDetails{
+		TaxpayerPIN:    normalizedPIN,
+		ObligationCode: req.ObligationCode,
+		Month:          req.Month,
+		Year:           req.Year,
+	}
+	return map[string]interface{}{
+		names.Details: details.toMap(names),
+	}
+}
+
+// RegisterObligation registers a taxpayer for a new tax obligation
+//
+// The effective date must be in the format YYYY-MM-DD. Results are never cached.
+//
+// Example:
+//
+//	result, err := client.RegisterObligation(ctx, &kra.ObligationRegistrationRequest{
+//	    PINNumber:      "P051234567A",
+//	    ObligationCode: 1,
+//	    EffectiveDate:  "2024-01-01",
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	fmt.Printf("Reference: %s\n", result.ReferenceNumber)
+func (c *Client) RegisterObligation(ctx context.Context, req *ObligationRegistrationRequest) (*ObligationResult, error) {
+	return c.submitObligationChange(ctx, "/dtd/obligation/v1/register", req)
+}
+
+// DeregisterObligation deregisters a taxpayer from an existing tax obligation
+//
+// The effective date must be in the format YYYY-MM-DD. Results are never cached.
+//
+// Example:
+//
+//	result, err := client.DeregisterObligation(ctx, &kra.ObligationRegistrationRequest{
+//	    PINNumber:      "P051234567A",
+//	    ObligationCode: 1,
+//	    EffectiveDate:  "2024-12-31",
+//	})
+func (c *Client) DeregisterObligation(ctx context.Context, req *ObligationRegistrationRequest) (*ObligationResult, error) {
+	return c.submitObligationChange(ctx, "/dtd/obligation/v1/deregister", req)
+}
+
+// submitObligationChange validates an obligation request and posts it to the given endpoint
+func (c *Client) submitObligationChange(ctx context.Context, endpoint string, req *ObligationRegistrationRequest) (*ObligationResult, error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	normalizedPIN, err := ValidateAndNormalizePIN(req.PINNumber)
+	if err != nil {
+		return nil, err
+	}
+	if req.ObligationCode <= 0 {
+		return nil, NewValidationError("obligation_code", "Obligation code must be positive")
+	}
+	effectiveDate := strings.TrimSpace(req.EffectiveDate)
+	if effectiveDate == "" {
+		return nil, NewValidationError("effective_date", "Effective date is required")
+	}
+	if _, err := time.Parse("2006-01-02", effectiveDate); err != nil {
+		return nil, NewValidationError("effective_date", fmt.Sprintf("Invalid effective date: '%s'. Expected YYYY-MM-DD", req.EffectiveDate))
+	}
+
+	payload := map[string]interface{}{
+		"TAXPAYERDETAILS": map[string]interface{}{
+			"TaxpayerPIN":    normalizedPIN,
+			"ObligationCode": req.ObligationCode,
+			"EffectiveDate":  effectiveDate,
+		},
+	}
+
+	apiResp, err := c.httpClient.Post(ctx, endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	data := apiResp.Data
+	result := &ObligationResult{
+		PINNumber:     normalizedPIN,
+		ObligationID:  fmt.Sprintf("%d", req.ObligationCode),
+		EffectiveDate: effectiveDate,
+		ProcessedAt:   time.Now(),
+		Metadata:      apiResp.Meta,
+		ResultTags:    resultTagsFromContext(ctx),
+	}
+	applyObligationPayload(result, data)
+
+	return result, nil
+}
+
+// applyObligationPayload fills the processing outcome fields of an obligation result from its payload
+func applyObligationPayload(result *ObligationResult, data map[string]interface{}) {
+	result.RawData = data
+	result.AdditionalData = data
+	result.ReferenceNumber = firstString(data, "referenceNumber", "RefNumber")
+	result.Status = NormalizeStatusString(firstString(data, "status", "registrationStatus"))
+	result.Message = firstString(data, "message", "responseDesc")
+
+	if obligationID := firstString(data, "obligationId", "ObligationID"); obligationID != "" {
+		result.ObligationID = obligationID
+	}
+
+	if success, ok := firstBool(data, "success", "Success"); ok {
+		result.Success = success
+	} else {
+		result.Success = inferValidityFromStatus(result.Status)
+	}
+}
+
+// GetTaxpayerDetails retrieves detailed taxpayer information
+//
+// Results are cached according to the configured taxpayer details TTL.
+//
+// Example:
+//
+//	details, err := client.GetTaxpayerDetails(ctx, "P051234567A")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	fmt.Printf("Name: %s\n", details.GetDisplayName())
+//	fmt.Printf("Obligations: %d\n", len(details.Obligations))
+func (c *Client) GetTaxpayerDetails(ctx context.Context, pin string) (*TaxpayerDetails, error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	// Validate and normalize PIN
+	normalizedPIN, err := ValidateAndNormalizePIN(pin)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check cache
+	cacheKey := c.cacheKey(ctx, "taxpayer_details", normalizedPIN)
+	if cached, found := c.cacheManager.Get(cacheKey); found {
+		if details, ok := cached.(*TaxpayerDetails); ok {
+			return details.tagged(ctx), nil
+		}
+		c.evictMistyped(cacheKey, cached)
+	}
+
+	// Concurrent lookups of the same key share one in-flight request
+	shared, err := c.share(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		profileResp, err := c.httpClient.Post(ctx, "/checker/v1/pinbypin", map[string]string{
+			"KRAPIN": normalizedPIN,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		obligations, obligationData, err := c.fetchObligations(ctx, normalizedPIN)
+		if err != nil {
+			return nil, err
+		}
+
+		profile := profileResp.Data
+
+		extra := map[string]interface{}{
+			"profile":     profile,
+			"obligations": obligationData,
+		}
+
+		details := &TaxpayerDetails{
+			PINNumber:        normalizedPIN,
+			TaxpayerName:     firstString(profile, "taxpayerName", "TaxpayerName", "taxpayer_name"),
+			TaxpayerType:     strings.ToLower(firstString(profile, "taxpayerType", "TaxpayerType", "taxpayer_type")),
+			Status:           NormalizeStatusString(firstString(profile, "pinStatus", "status", "TaxpayerStatus")),
+			RegistrationDate: firstString(profile, "registrationDate", "RegistrationDate", "registration_date"),
+			BusinessName:     firstString(profile, "businessName", "BusinessName"),
+			TradingName:      firstString(profile, "tradingName", "TradingName"),
+			PostalAddress:    firstString(profile, "postalAddress", "PostalAddress"),
+			PhysicalAddress:  firstString(profile, "physicalAddress", "PhysicalAddress"),
+			EmailAddress:     firstString(profile, "emailAddress", "EmailAddress"),
+			PhoneNumber:      firstString(profile, "phoneNumber", "PhoneNumber"),
+			Obligations:      obligations,
+			AdditionalData:   extra,
+			RetrievedAt:      time.Now(),
+			Metadata:         profileResp.Meta,
+			RawData:          profile,
+			dueSoonDays:      c.config.DueSoonDays,
+		}
+
+		if details.TaxpayerName == "" {
+			details.TaxpayerName = firstString(profile, "legalName", "BusinessName")
+		}
+
+		if err := c.postProcess(ctx, OperationTaxpayerDetails, details); err != nil {
+			return nil, err
+		}
+
+		// Cache result
+		c.cacheManager.Set(cacheKey, details, c.config.TaxpayerDetailsTTL)
+
+		return details, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return shared.(*TaxpayerDetails).tagged(ctx), nil
+}
+
+// fetchObligations retrieves and parses the obligations registered for a normalized PIN
+func (c *Client) fetchObligations(ctx context.Context, normalizedPIN string) ([]TaxObligation, map[string]interface{}, error) {
+	obligationResp, err := c.httpClient.Post(ctx, "/dtd/checker/v1/obligation", map[string]string{
+		"taxPayerPin": normalizedPIN,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	obligations := parseObligations(obligationResp.Data)
+	for i := range obligations {
+		obligations[i].dueSoonDays = c.config.DueSoonDays
+	}
+
+	return obligations, obligationResp.Data, nil
 }
 
 func parseObligations(payload map[string]interface{}) []TaxObligation {
@@ -501,139 +1328,779 @@ func parseObligations(payload map[string]interface{}) []TaxObligation {
 		return nil
 	}
 
-	items, ok := payload["obligations"].([]interface{})
-	if !ok {
+	items := obligationItems(payload)
+	if len(items) == 0 {
+		return nil
+	}
+
+	obligations := make([]TaxObligation, 0, len(items))
+	for _, item := range items {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		obligation := TaxObligation{
+			ObligationID:     firstString(row, "obligationId", "ObligationID", "obligation_id"),
+			ObligationType:   firstString(row, "obligationType", "ObligationType", "obligation_type"),
+			Description:      firstString(row, "description", "Description"),
+			Status:           NormalizeStatusString(firstString(row, "status", "Status")),
+			RegistrationDate: firstString(row, "registrationDate", "RegistrationDate"),
+			EffectiveDate:    firstString(row, "effectiveDate", "EffectiveDate"),
+			EndDate:          firstString(row, "endDate", "EndDate"),
+			Frequency:        firstString(row, "frequency", "Frequency"),
+			NextFilingDate:   firstString(row, "nextFilingDate", "NextFilingDate"),
+			AdditionalData:   row,
+		}
+		if isActive, ok := firstBool(row, "isActive", "IsActive"); ok {
+			obligation.IsActive = isActive
+		} else {
+			obligation.IsActive = inferValidityFromStatus(obligation.Status)
+		}
+		obligations = append(obligations, obligation)
+	}
+
+	return obligations
+}
+
+// GetObligationHistory retrieves the filing history of a taxpayer obligation
+//
+// The history endpoint is paginated. Pages are followed until KRA reports no
+// further pages or Config.ObligationHistoryMaxPages pages have been read, and
+// the records from every page are returned in the order received. If the page
+// limit is reached while KRA still reports more pages, the records read so
+// far are returned with ErrHistoryTruncated.
+//
+// Example:
+//
+//	records, err := client.GetObligationHistory(ctx, "P051234567A", "OBL123456")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	for _, record := range records {
+//	    fmt.Printf("%s: %s (late: %v)\n", record.Period, record.Status, record.IsLate())
+//	}
+func (c *Client) GetObligationHistory(ctx context.Context, pin, obligationID string) ([]FilingRecord, error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	normalizedPIN, err := ValidateAndNormalizePIN(pin)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateObligationID(obligationID); err != nil {
+		return nil, err
+	}
+
+	var records []FilingRecord
+	pageToken := ""
+	more := false
+	for page := 1; page <= c.config.ObligationHistoryMaxPages; page++ {
+		payload := map[string]interface{}{
+			"taxPayerPin":  normalizedPIN,
+			"obligationId": obligationID,
+			"pageNumber":   page,
+		}
+		if pageToken != "" {
+			payload["pageToken"] = pageToken
+		}
+
+		resp, err := c.httpClient.Post(ctx, "/dtd/checker/v1/obligation/history", payload)
+		if err != nil {
+			return nil, err
+		}
+
+		pageRecords := parseFilingRecords(resp.Data, obligationID)
+		records = append(records, pageRecords...)
+
+		if len(pageRecords) == 0 {
+			more = false
+			break
+		}
+
+		pageToken, more = nextHistoryPage(resp.Data, page)
+		if !more {
+			break
+		}
+	}
+
+	if more {
+		return records, ErrHistoryTruncated
+	}
+	return records, nil
+}
+
+// obligationKeys lists the keys GavaConnect uses for the obligation list
+var obligationKeys = []string{"obligations", "Obligations", "obligationDetails", "ObligationDetails"}
+
+// obligationItems finds the obligation entries in a payload, accepting either
+// an array or a single object, under any of the known keys, and looking inside
+// a nested responseData block when the envelope was not unwrapped
+func obligationItems(payload map[string]interface{}) []interface{} {
+	for _, key := range obligationKeys {
+		switch value := payload[key].(type) {
+		case []interface{}:
+			return value
+		case map[string]interface{}:
+			return []interface{}{value}
+		}
+	}
+	if nested, ok := payload["responseData"].(map[string]interface{}); ok {
+		return obligationItems(nested)
+	}
+	return nil
+}
+
+// parseFilingRecords extracts filing records from a history page
+func parseFilingRecords(payload map[string]interface{}, obligationID string) []FilingRecord {
+	if payload == nil {
 		return nil
 	}
 
-	obligations := make([]TaxObligation, 0, len(items))
-	for _, item := range items {
-		row, ok := item.(map[string]interface{})
-		if !ok {
+	var items []interface{}
+	for _, key := range []string{"filings", "records", "history"} {
+		if list, ok := payload[key].([]interface{}); ok {
+			items = list
+			break
+		}
+	}
+
+	records := make([]FilingRecord, 0, len(items))
+	for _, item := range items {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		record := FilingRecord{
+			FilingID:              firstString(row, "filingId", "FilingID", "filing_id", "returnId"),
+			ObligationID:          firstString(row, "obligationId", "ObligationID", "obligation_id"),
+			Period:                firstString(row, "period", "Period", "taxPeriod", "returnPeriod"),
+			FilingDate:            firstString(row, "filingDate", "FilingDate", "filing_date", "dateFiled"),
+			DueDate:               firstString(row, "dueDate", "DueDate", "due_date"),
+			Status:                NormalizeStatusString(firstString(row, "status", "Status", "filingStatus")),
+			ReturnType:            firstString(row, "returnType", "ReturnType", "return_type"),
+			AcknowledgementNumber: firstString(row, "acknowledgementNumber", "AcknowledgementNumber", "ackNumber"),
+			AdditionalData:        row,
+		}
+		if record.ObligationID == "" {
+			record.ObligationID = obligationID
+		}
+		if amount, ok := firstFloat64(row, "taxAmount", "TaxAmount", "amount"); ok {
+			record.TaxAmount = amount
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// nextHistoryPage reports whether another history page follows the current
+// one, returning the continuation token if KRA supplied one
+func nextHistoryPage(payload map[string]interface{}, page int) (string, bool) {
+	if payload == nil {
+		return "", false
+	}
+
+	if token := firstString(payload, "nextPageToken", "nextToken", "next_page_token"); token != "" {
+		return token, true
+	}
+
+	if totalPages, ok := firstFloat64(payload, "totalPages", "TotalPages", "total_pages"); ok {
+		return "", page < int(totalPages)
+	}
+
+	hasMore, _ := firstBool(payload, "hasMore", "HasMore", "has_more")
+	return "", hasMore
+}
+
+func inferValidityFromStatus(status string) bool {
+	if status == "" {
+		return false
+	}
+	s := NormalizeStatusString(status)
+	if s == "" {
+		return false
+	}
+	switch NormalizeStatus(s) {
+	case StatusInactive, StatusSuspended, StatusBlacklisted:
+		return false
+	}
+	if strings.Contains(s, "invalid") || strings.Contains(s, "inactive") || strings.Contains(s, "expired") || strings.Contains(s, "reject") {
+		return false
+	}
+	return true
+}
+
+// batchWorkers bounds the number of concurrent requests made by VerifyPINsBatch
+const batchWorkers = 10
+
+// VerifyPINsBatch verifies multiple PIN numbers in parallel
+//
+// This method is more efficient than calling VerifyPIN multiple times
+// as it processes requests concurrently with proper goroutine management.
+// Results are returned in input order: results[i] belongs to pins[i] however
+// the verifications complete. At most 10 verifications run at once. If ctx
+// is cancelled, no further PINs are dispatched and the method returns
+// ctx.Err() immediately along with the results completed so far; entries that
+// did not complete are nil. Malformed PINs are handled according to
+// WithInvalidInputPolicy.
+//
+// Every rate limiter and network wait on the request path honors ctx, so the
+// workers of a cancelled batch exit promptly after the call returns; none stay
+// blocked waiting for a token on a saturated limiter.
+//
+// Example:
+//
+//	pins := []string{"P051234567A", "P051234567B", "P051234567C"}
+//	results, err := client.VerifyPINsBatch(ctx, pins)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	for _, result := range results {
+//	    fmt.Printf("%s: %v\n", result.PINNumber, result.IsValid)
+//	}
+func (c *Client) VerifyPINsBatch(ctx context.Context, pins []string) ([]*PINVerificationResult, error) {
+	if err := c.checkBatchSize(len(pins)); err != nil {
+		return nil, err
+	}
+
+	endBatch, err := c.beginBatch()
+	if err != nil {
+		return nil, err
+	}
+
+	inputErrs, err := c.checkBatchInputs(len(pins), func(i int) error {
+		_, err := ValidateAndNormalizePIN(pins[i])
+		return err
+	})
+	if err != nil {
+		endBatch()
+		return nil, err
+	}
+
+	results := make([]*PINVerificationResult, len(pins))
+	errs := make([]error, len(pins))
+	var mu sync.Mutex
+
+	workers := batchWorkers
+	if len(pins) < workers {
+		workers = len(pins)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				result, err := c.VerifyPIN(ctx, pins[index])
+				mu.Lock()
+				results[index] = result
+				errs[index] = batchItemError(err)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	// Dispatch PINs until done, cancelled, or the client is closed
+	go func() {
+		defer close(jobs)
+		for i := range pins {
+			if ctx.Err() != nil {
+				return
+			}
+			if inputErrs != nil && inputErrs[i] != nil {
+				continue
+			}
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			case <-c.done:
+				mu.Lock()
+				for j := i; j < len(pins); j++ {
+					errs[j] = ErrClosedDuringBatch
+				}
+				mu.Unlock()
+				return
+			}
+		}
+	}()
+
+	// The batch is released once its workers exit rather than when this
+	// method returns, so Close still waits for items left in flight by a
+	// cancelled batch
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		endBatch()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		// Return a snapshot; in-flight workers may still write to results
+		mu.Lock()
+		partial := append([]*PINVerificationResult(nil), results...)
+		mu.Unlock()
+		return partial, ctx.Err()
+	}
+
+	if err := batchResultError(errs, inputErrs); err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+// DefaultChunkSize is the chunk size used by VerifyPINsChunked when a non-positive size is given
+const DefaultChunkSize = 10
+
+// VerifyPINsChunked verifies a large list of PIN numbers in sequential chunks
+//
+// Each chunk is processed concurrently, but only one chunk is in flight at a
+// time, which bounds peak goroutines and memory for large inputs. Results and
+// errors are returned in input order; a failed item has a nil result and a
+// non-nil error at the same index. If the context is cancelled between chunks,
+// the remaining items receive the context error.
+//
+// Example:
+//
+//	results, errs := client.VerifyPINsChunked(ctx, pins, 25)
+//	for i, result := range results {
+//	    if errs[i] != nil {
+//	        log.Printf("%s: %v", pins[i], errs[i])
+//	        continue
+//	    }
+//	    fmt.Printf("%s: %v\n", result.PINNumber, result.IsValid)
+//	}
+func (c *Client) VerifyPINsChunked(ctx context.Context, pins []string, chunkSize int) ([]*PINVerificationResult, []error) {
+	results := make([]*PINVerificationResult, len(pins))
+	errs := make([]error, len(pins))
+
+	endBatch, err := c.beginBatch()
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return results, errs
+	}
+	defer endBatch()
+
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	for start := 0; start < len(pins); start += chunkSize {
+		end := start + chunkSize
+		if end > len(pins) {
+			end = len(pins)
+		}
+
+		err := ctx.Err()
+		if err == nil && c.isClosing() {
+			err = ErrClosedDuringBatch
+		}
+		if err != nil {
+			for i := start; i < len(pins); i++ {
+				errs[i] = err
+			}
+			break
+		}
+
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			go func(index int) {
+				defer wg.Done()
+				result, err := c.VerifyPIN(ctx, pins[index])
+				results[index], errs[index] = result, batchItemError(err)
+			}(i)
+		}
+		wg.Wait()
+	}
+
+	return results, errs
+}
+
+// VerifyPINsUnique verifies each distinct PIN in a messy list once
+//
+// PINs are normalized (trimmed and uppercased) and deduplicated, and each
+// distinct PIN is verified once, with at most 10 verifications running at a
+// time. Both maps are keyed by the normalized PIN: a PIN appears in results
+// when it verified, or in errs when its format is invalid or verification
+// failed, never in both. Blank entries are ignored.
+//
+// Example:
+//
+//	results, errs := client.VerifyPINsUnique(ctx, []string{" p051234567a", "P051234567A", "P051234567B"})
+//	for pin, err := range errs {
+//	    log.Printf("%s: %v", pin, err)
+//	}
+//	for pin, result := range results {
+//	    fmt.Printf("%s: %v\n", pin, result.IsValid)
+//	}
+func (c *Client) VerifyPINsUnique(ctx context.Context, pins []string) (map[string]*PINVerificationResult, map[string]error) {
+	results := make(map[string]*PINVerificationResult)
+	errs := make(map[string]error)
+
+	var unique []string
+	seen := make(map[string]bool)
+	for _, pin := range pins {
+		key := strings.ToUpper(strings.TrimSpace(pin))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if _, err := ValidateAndNormalizePIN(key); err != nil {
+			errs[key] = err
+			continue
+		}
+		unique = append(unique, key)
+	}
+
+	verified, verifyErrs := c.VerifyPINsChunked(ctx, unique, batchWorkers)
+	for i, pin := range unique {
+		if verifyErrs[i] != nil {
+			errs[pin] = verifyErrs[i]
+			continue
+		}
+		results[pin] = verified[i]
+	}
+
+	return results, errs
+}
+
+// FindInvalidPINs verifies PINs and returns only those that are not usable
+//
+// Each entry reports the PIN's input index and a reason: InvalidPINValidation
+// for malformed PINs, InvalidPINNotFound when KRA does not know the PIN or
+// reports it as invalid, and InvalidPINInactive when KRA reports a status
+// other than active, such as dormant or suspended. Entries are in input
+// order, and an empty result means every PIN is valid and active. PINs are
+// verified as by VerifyPINsChunked, at most 10 at a time.
+//
+// Failures that say nothing about the PIN itself, such as network errors or
+// cancellation, cannot be classified. The first one is returned as the error,
+// along with the invalid PINs found among the others.
+//
+// Example:
+//
+//	invalid, err := client.FindInvalidPINs(ctx, pins)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, entry := range invalid {
+//	    fmt.Printf("row %d: %s is %s\n", entry.Index+1, entry.PIN, entry.Reason)
+//	}
+func (c *Client) FindInvalidPINs(ctx context.Context, pins []string) ([]InvalidPIN, error) {
+	results, errs := c.VerifyPINsChunked(ctx, pins, batchWorkers)
+
+	var invalid []InvalidPIN
+	var firstErr error
+	for i, pin := range pins {
+		entry := InvalidPIN{Index: i, PIN: pin, Err: errs[i], Result: results[i]}
+		var validationErr *ValidationError
+		var formatErr *InvalidPINFormatError
+		switch {
+		case errs[i] == nil:
+			result := results[i]
+			if !result.IsValid {
+				entry.Reason = InvalidPINNotFound
+			} else if result.Status != "" && !result.IsActive() {
+				entry.Reason = InvalidPINInactive
+			} else {
+				continue
+			}
+		case errors.As(errs[i], &validationErr) || errors.As(errs[i], &formatErr):
+			entry.Reason = InvalidPINValidation
+		case isNotFound(errs[i]):
+			entry.Reason = InvalidPINNotFound
+		default:
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
 			continue
 		}
-		obligation := TaxObligation{
-			ObligationID:     firstString(row, "obligationId", "ObligationID", "obligation_id"),
-			ObligationType:   firstString(row, "obligationType", "ObligationType", "obligation_type"),
-			Description:      firstString(row, "description", "Description"),
-			Status:           strings.ToLower(firstString(row, "status", "Status")),
-			RegistrationDate: firstString(row, "registrationDate", "RegistrationDate"),
-			EffectiveDate:    firstString(row, "effectiveDate", "EffectiveDate"),
-			EndDate:          firstString(row, "endDate", "EndDate"),
-			Frequency:        firstString(row, "frequency", "Frequency"),
-			NextFilingDate:   firstString(row, "nextFilingDate", "NextFilingDate"),
-			AdditionalData:   row,
-		}
-		if isActive, ok := firstBool(row, "isActive", "IsActive"); ok {
-			obligation.IsActive = isActive
-		} else {
-			obligation.IsActive = inferValidityFromStatus(obligation.Status)
-		}
-		obligations = append(obligations, obligation)
+		invalid = append(invalid, entry)
 	}
 
-	return obligations
+	return invalid, firstErr
 }
 
-func inferValidityFromStatus(status string) bool {
-	if status == "" {
-		return false
+// VerifyTCCsBatch verifies multiple TCC numbers in parallel
+//
+// Results are returned in input order: results[i] belongs to requests[i]
+// however the verifications complete. Malformed requests are handled
+// according to WithInvalidInputPolicy.
+//
+// Example:
+//
+//	tccs := []string{"TCC123456", "TCC123457", "TCC123458"}
+//	results, err := client.VerifyTCCsBatch(ctx, tccs)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (c *Client) VerifyTCCsBatch(ctx context.Context, requests []*TCCVerificationRequest) ([]*TCCVerificationResult, error) {
+	if err := c.checkBatchSize(len(requests)); err != nil {
+		return nil, err
 	}
-	s := strings.ToLower(strings.TrimSpace(status))
-	if s == "" {
-		return false
+
+	endBatch, err := c.beginBatch()
+	if err != nil {
+		return nil, err
 	}
-	if strings.Contains(s, "invalid") || strings.Contains(s, "inactive") || strings.Contains(s, "expired") || strings.Contains(s, "reject") {
-		return false
+	defer endBatch()
+
+	inputErrs, err := c.checkBatchInputs(len(requests), func(i int) error {
+		return validateTCCRequest(requests[i])
+	})
+	if err != nil {
+		return nil, err
 	}
-	return true
+
+	results := make([]*TCCVerificationResult, len(requests))
+	errs := make([]error, len(requests))
+
+	c.runBatch(ctx, len(requests), inputErrs, func(i int) {
+		result, err := c.VerifyTCC(ctx, requests[i])
+		results[i], errs[i] = result, batchItemError(err)
+	}, func(i int, err error) {
+		errs[i] = err
+	})
+
+	if err := batchResultError(errs, inputErrs); err != nil {
+		return results, err
+	}
+
+	return results, nil
 }
 
-// VerifyPINsBatch verifies multiple PIN numbers in parallel
+// ValidateEslipsBatch validates multiple e-slip numbers in parallel
 //
-// This method is more efficient than calling VerifyPIN multiple times
-// as it processes requests concurrently with proper goroutine management.
+// Results are returned in input order: results[i] belongs to eslipNumbers[i]
+// however the validations complete. If any validation fails, the first
+// error is returned along with the results gathered so far. Malformed
+// e-slip numbers are handled according to WithInvalidInputPolicy.
 //
 // Example:
 //
-//	pins := []string{"P051234567A", "P051234567B", "P051234567C"}
-//	results, err := client.VerifyPINsBatch(ctx, pins)
+//	eslips := []string{"1234567890", "1234567891"}
+//	results, err := client.ValidateEslipsBatch(ctx, eslips)
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
-//
-//	for _, result := range results {
-//	    fmt.Printf("%s: %v\n", result.PINNumber, result.IsValid)
-//	}
-func (c *Client) VerifyPINsBatch(ctx context.Context, pins []string) ([]*PINVerificationResult, error) {
-	if err := c.checkClosed(); err != nil {
+func (c *Client) ValidateEslipsBatch(ctx context.Context, eslipNumbers []string) ([]*EslipValidationResult, error) {
+	if err := c.checkBatchSize(len(eslipNumbers)); err != nil {
 		return nil, err
 	}
 
-	results := make([]*PINVerificationResult, len(pins))
-	errs := make([]error, len(pins))
+	endBatch, err := c.beginBatch()
+	if err != nil {
+		return nil, err
+	}
+	defer endBatch()
 
-	var wg sync.WaitGroup
-	for i, pin := range pins {
-		wg.Add(1)
-		go func(index int, p string) {
-			defer wg.Done()
-			result, err := c.VerifyPIN(ctx, p)
-			results[index] = result
-			errs[index] = err
-		}(i, pin)
+	inputErrs, err := c.checkBatchInputs(len(eslipNumbers), func(i int) error {
+		return ValidateEslipNumber(eslipNumbers[i])
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	wg.Wait()
+	results, errs := c.validateEslips(ctx, eslipNumbers, inputErrs)
 
-	// Check for errors
-	for _, err := range errs {
-		if err != nil {
-			return results, err
-		}
+	if err := batchResultError(errs, inputErrs); err != nil {
+		return results, err
 	}
 
 	return results, nil
 }
 
-// VerifyTCCsBatch verifies multiple TCC numbers in parallel
+// validateEslips validates e-slips concurrently, keeping per-item errors
+//
+// Entries with a non-nil error in skip are left untouched; skip may be nil.
+// The caller must have registered the batch with beginBatch.
+func (c *Client) validateEslips(ctx context.Context, eslipNumbers []string, skip []error) ([]*EslipValidationResult, []error) {
+	results := make([]*EslipValidationResult, len(eslipNumbers))
+	errs := make([]error, len(eslipNumbers))
+
+	c.runBatch(ctx, len(eslipNumbers), skip, func(i int) {
+		result, err := c.ValidateEslip(ctx, eslipNumbers[i])
+		results[i], errs[i] = result, batchItemError(err)
+	}, func(i int, err error) {
+		errs[i] = err
+	})
+
+	return results, errs
+}
+
+// SetBearerToken replaces the bearer token used for subsequent requests
+//
+// It is intended for clients created with WithBearerToken whose token is
+// rotated externally; setting a token on other clients switches them to
+// bearer authentication. Requests already in flight keep the previous token.
 //
 // Example:
 //
-//	tccs := []string{"TCC123456", "TCC123457", "TCC123458"}
-//	results, err := client.VerifyTCCsBatch(ctx, tccs)
-//	if err != nil {
+//	if err := client.SetBearerToken(newToken); err != nil {
 //	    log.Fatal(err)
 //	}
-func (c *Client) VerifyTCCsBatch(ctx context.Context, requests []*TCCVerificationRequest) ([]*TCCVerificationResult, error) {
+func (c *Client) SetBearerToken(token string) error {
 	if err := c.checkClosed(); err != nil {
-		return nil, err
+		return err
+	}
+	if strings.TrimSpace(token) == "" {
+		return NewValidationError("bearer_token", "Bearer token is required")
 	}
 
-	results := make([]*TCCVerificationResult, len(requests))
-	errs := make([]error, len(requests))
+	c.httpClient.auth.bearer.Store(token)
+	return nil
+}
 
-	var wg sync.WaitGroup
-	for i, req := range requests {
-		wg.Add(1)
-		go func(index int, r *TCCVerificationRequest) {
-			defer wg.Done()
-			result, err := c.VerifyTCC(ctx, r)
-			results[index] = result
-			errs[index] = err
-		}(i, req)
+// LatencyStats returns per-endpoint latency summaries for requests made by
+// the client
+//
+// Every HTTP attempt that receives a response is recorded, including error
+// statuses and retries. Percentiles are approximate, derived from a fixed set
+// of histogram buckets. The returned map is a snapshot keyed by endpoint path.
+//
+// Example:
+//
+//	for endpoint, stats := range client.LatencyStats() {
+//	    fmt.Printf("%s: n=%d p50=%v p95=%v max=%v\n",
+//	        endpoint, stats.Count, stats.P50, stats.P95, stats.Max)
+//	}
+func (c *Client) LatencyStats() map[string]LatencySummary {
+	return c.httpClient.stats.latencySummaries()
+}
+
+// EstimateBatchDuration estimates how long a batch of itemCount requests
+// would take under the client's current rate limit
+//
+// The estimate is the longer of two bounds: the time to refill the tokens the
+// batch needs beyond those currently available, and the time for the batch
+// workers (10 at once) to complete every request at the median latency
+// observed so far. Before any request has completed only the rate limit
+// contributes. With a distributed rate limiter, whose shared state is not
+// visible to the client, the configured rate is used and the bucket is
+// assumed to be full. Retries and cache hits are not accounted for.
+//
+// Example:
+//
+//	if client.EstimateBatchDuration(len(pins)) > time.Until(windowEnd) {
+//	    log.Printf("batch of %d PINs will not fit the maintenance window", len(pins))
+//	}
+func (c *Client) EstimateBatchDuration(itemCount int) time.Duration {
+	if itemCount <= 0 {
+		return 0
 	}
 
-	wg.Wait()
+	rounds := (itemCount + batchWorkers - 1) / batchWorkers
+	estimate := time.Duration(rounds) * c.typicalLatency()
 
-	// Check for errors
-	for _, err := range errs {
-		if err != nil {
-			return results, err
+	if !c.config.RateLimitEnabled {
+		return estimate
+	}
+
+	available := c.config.MaxRequests
+	rate := float64(c.config.MaxRequests) / c.config.RateLimitWindow.Seconds()
+	if c.config.RateLimiterBackend == nil {
+		available = c.rateLimiter.AvailableTokens()
+		rate = c.rateLimiter.refillRate
+	}
+
+	if deficit := itemCount - available; deficit > 0 && rate > 0 {
+		wait := float64(deficit) / rate * float64(time.Second)
+		if wait > float64(math.MaxInt64) {
+			return time.Duration(math.MaxInt64)
+		}
+		if time.Duration(wait) > estimate {
+			estimate = time.Duration(wait)
 		}
 	}
+	return estimate
+}
 
-	return results, nil
+// typicalLatency returns the request-weighted median latency across endpoints,
+// or 0 before any request has completed
+func (c *Client) typicalLatency() time.Duration {
+	var total, count int64
+	for _, summary := range c.httpClient.stats.latencySummaries() {
+		total += int64(summary.P50) * summary.Count
+		count += summary.Count
+	}
+	if count == 0 {
+		return 0
+	}
+	return time.Duration(total / count)
+}
+
+// LastResponses returns the most recent responses the client received,
+// oldest first
+//
+// It returns nil unless the client was created with WithResponseHistory.
+//
+// Example:
+//
+//	for _, r := range client.LastResponses() {
+//	    fmt.Printf("%s %s -> %d in %v: %s\n", r.Method, r.Endpoint, r.StatusCode, r.Duration, r.Body)
+//	}
+func (c *Client) LastResponses() []ResponseRecord {
+	if c.httpClient.history == nil {
+		return nil
+	}
+	return c.httpClient.history.snapshot()
+}
+
+// DoWithRetry runs fn under the client's retry policy
+//
+// fn is retried with the same attempt count, exponential backoff and jitter
+// configured via WithRetry, and stops early on errors the SDK treats as
+// permanent (validation, authentication, and 4xx responses other than 429)
+// or when ctx is done. It returns nil once fn succeeds, otherwise the last
+// error fn returned. Use it to give composite workflows the SDK's retry
+// semantics; the SDK calls made inside fn still retry individually.
+//
+// Example:
+//
+//	err := client.DoWithRetry(ctx, func(ctx context.Context) error {
+//	    if _, err := client.VerifyPIN(ctx, pin); err != nil {
+//	        return err
+//	    }
+//	    _, err := client.FileNILReturn(ctx, req)
+//	    return err
+//	})
+func (c *Client) DoWithRetry(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
+	if fn == nil {
+		return NewValidationError("fn", "operation is required")
+	}
+	return c.httpClient.doWithRetry(ctx, fn)
+}
+
+// TokenInfo reports the state of the client's cached OAuth token
+//
+// expiresAt is the expiry of the most recently fetched token, and fromCache
+// is true when that token is still valid and the next request will reuse it
+// rather than refreshing. Clients authenticating with API keys always return
+// a zero time and false.
+//
+// Example:
+//
+//	expiresAt, fromCache := client.TokenInfo()
+//	if !fromCache {
+//	    log.Printf("next request will refresh the token (last expiry %v)", expiresAt)
+//	}
+func (c *Client) TokenInfo() (expiresAt time.Time, fromCache bool) {
+	return c.httpClient.auth.info()
 }
 
 // ClearCache clears all cached data
@@ -648,30 +2115,273 @@ func (c *Client) ClearCache() error {
 	return nil
 }
 
+// CacheKeys returns the keys of all non-expired cache entries
+//
+// Use this when diagnosing stale data to see what is currently cached.
+func (c *Client) CacheKeys() ([]string, error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	return c.cacheManager.Keys(), nil
+}
+
 // Close closes the client and releases resources
 //
-// After calling Close, the client cannot be used anymore.
+// After calling Close, the client cannot be used anymore. Close is
+// idempotent: only the first call releases resources, and later calls return
+// nil, so it is safe to combine an explicit Close with a deferred one. Batch
+// methods that are running stop dispatching new items and return
+// ErrClosedDuringBatch; Close waits for their in-flight requests to finish
+// before returning.
 func (c *Client) Close() error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	if c.closed {
-		return fmt.Errorf("client already closed")
+		c.mu.Unlock()
+		return nil
 	}
-
 	c.closed = true
+	close(c.done)
+	c.mu.Unlock()
+
+	// Let running batches observe the close and finish in-flight items
+	c.batches.Wait()
+
+	if c.config.DebugMode || c.config.SummaryOnClose {
+		hits, misses := c.cacheManager.lookupStats()
+		logf(c.config.Logger, c.config.ClientName, "[Client] SUMMARY: %s\n", c.httpClient.stats.summary(hits, misses))
+	}
+
 	c.cacheManager.Clear()
 
 	return nil
 }
 
+// Abort cancels every in-flight request and closes the client
+//
+// Unlike Close, which lets in-flight requests run to completion, Abort cancels
+// the client-internal context that all requests derive from, so calls blocked
+// on the network, the rate limiter or a retry backoff return promptly with
+// ErrAborted (which matches context.Canceled via errors.Is). Running batches
+// stop dispatching and return ErrClosedDuringBatch. Abort then closes the
+// client exactly as Close does; it is idempotent and safe to combine with
+// Close.
+//
+// Example:
+//
+//	go func() {
+//	    <-shutdown
+//	    client.Abort()
+//	}()
+func (c *Client) Abort() {
+	c.httpClient.abort()
+	c.Close()
+}
+
+// postProcess runs the configured ResultPostProcessor on a freshly parsed
+// result, before it is cached or returned
+func (c *Client) postProcess(ctx context.Context, operation string, result interface{}) error {
+	if c.config.ResultPostProcessor == nil {
+		return nil
+	}
+	return c.config.ResultPostProcessor(ctx, operation, result)
+}
+
+// cacheKey builds the cache key for an operation using the configured key
+// function, then applies the configured key prefix
+func (c *Client) cacheKey(ctx context.Context, operation string, params ...string) string {
+	var key string
+	if c.config.CacheKeyFunc != nil {
+		key = c.config.CacheKeyFunc(ctx, operation, params...)
+	} else {
+		key = GenerateCacheKey(operation, params...)
+	}
+	if c.config.CacheKeyPrefix != "" {
+		key = c.config.CacheKeyPrefix + ":" + key
+	}
+	// Results fetched with a per-call API key belong to that tenant alone
+	if apiKey, ok := apiKeyFromContext(ctx); ok {
+		key += ":key=" + apiKeyFingerprint(apiKey)
+	}
+	return key
+}
+
+// share runs compute once among concurrent lookups of cacheKey
+//
+// compute runs on a context that keeps the values of the first caller's ctx,
+// such as ContextWithAPIKey or ContextWithAttemptTimeout, but not its
+// cancellation, so a caller that cancels or times out only stops its own wait.
+// Abort still cancels the shared request. The computation is timed, so that
+// WithSlowCacheCompute reports slow upstream calls made on a cache miss.
+func (c *Client) share(ctx context.Context, cacheKey string, compute func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	return c.inflight.do(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		return c.cacheManager.timeCompute(cacheKey, func() (interface{}, error) {
+			return compute(ctx)
+		})
+	})
+}
+
+// evictMistyped logs a warning and removes a cache entry holding an unexpected type
+//
+// A type mismatch means two operations are sharing a cache key, which would
+// otherwise show up only as a silent cache miss on every call.
+func (c *Client) evictMistyped(key string, value interface{}) {
+	logf(c.config.Logger, c.config.ClientName, "[Cache] WARN: Unexpected %T cached under %s; evicting\n", value, key)
+	c.cacheManager.Delete(key)
+}
+
+// checkBatchSize rejects batch inputs larger than the configured maximum
+func (c *Client) checkBatchSize(n int) error {
+	if n > c.config.MaxBatchSize {
+		return NewValidationError("batch_size", fmt.Sprintf(
+			"Batch of %d items exceeds the maximum of %d; split the input into chunks or use VerifyPINsChunked",
+			n, c.config.MaxBatchSize,
+		))
+	}
+	return nil
+}
+
+// checkBatchInputs validates each batch entry with validate
+//
+// It returns the per-entry validation errors, or nil when every entry is
+// valid. Under PolicyFail any invalid entry rejects the batch with a
+// *BatchInputError instead.
+func (c *Client) checkBatchInputs(n int, validate func(i int) error) ([]error, error) {
+	var inputErrs []error
+	for i := 0; i < n; i++ {
+		if err := validate(i); err != nil {
+			if inputErrs == nil {
+				inputErrs = make([]error, n)
+			}
+			inputErrs[i] = err
+		}
+	}
+
+	if inputErrs != nil && c.config.InvalidInputPolicy == PolicyFail {
+		return nil, &BatchInputError{Errors: inputErrs}
+	}
+	return inputErrs, nil
+}
+
+// batchResultError returns the first request error of a batch, or a
+// *BatchInputError when the only failures were skipped invalid entries
+func batchResultError(errs, inputErrs []error) error {
+	if err := firstBatchError(errs); err != nil {
+		return err
+	}
+	if inputErrs != nil {
+		return &BatchInputError{Errors: inputErrs}
+	}
+	return nil
+}
+
+// beginBatch registers a running batch so that Close waits for it; the
+// returned function must be called when the batch finishes
+func (c *Client) beginBatch() (func(), error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, errClientClosed
+	}
+
+	c.batches.Add(1)
+	return c.batches.Done, nil
+}
+
+// runBatch calls fn for every index below n that skip does not mark, on at
+// most batchWorkers goroutines, and waits for the calls to finish
+//
+// skip may be nil. Dispatch stops as soon as ctx is done or the client is
+// closed; stop is then called, on the calling goroutine, for every remaining
+// item with ctx.Err() or ErrClosedDuringBatch. Each index is handed to either
+// fn or stop, never both, so they may write to per-index slots without
+// locking.
+func (c *Client) runBatch(ctx context.Context, n int, skip []error, fn func(i int), stop func(i int, err error)) {
+	workers := batchWorkers
+	if n < workers {
+		workers = n
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	var stopErr error
+	for i := 0; i < n; i++ {
+		if skip != nil && skip[i] != nil {
+			continue
+		}
+		// Check before dispatching, since select picks among ready cases at random
+		if stopErr == nil && ctx.Err() != nil {
+			stopErr = ctx.Err()
+		} else if stopErr == nil && c.isClosing() {
+			stopErr = ErrClosedDuringBatch
+		}
+		if stopErr == nil {
+			select {
+			case jobs <- i:
+				continue
+			case <-ctx.Done():
+				stopErr = ctx.Err()
+			case <-c.done:
+				stopErr = ErrClosedDuringBatch
+			}
+		}
+		stop(i, stopErr)
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// isClosing reports whether Close has been called
+func (c *Client) isClosing() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// batchItemError maps a per-item error caused by Close to ErrClosedDuringBatch
+func batchItemError(err error) error {
+	if errors.Is(err, errClientClosed) {
+		return ErrClosedDuringBatch
+	}
+	return err
+}
+
+// firstBatchError returns ErrClosedDuringBatch if the client was closed during
+// the batch, otherwise the first item error
+func firstBatchError(errs []error) error {
+	var first error
+	for _, err := range errs {
+		if errors.Is(err, ErrClosedDuringBatch) {
+			return ErrClosedDuringBatch
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
 // checkClosed checks if the client has been closed
 func (c *Client) checkClosed() error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if c.closed {
-		return fmt.Errorf("client is closed")
+		return errClientClosed
 	}
 
 	return nil
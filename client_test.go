@@ -1,9 +1,18 @@
 package kra
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -260,8 +269,8 @@ func TestClientClearCacheAndClose(t *testing.T) {
 		t.Fatalf("expected ClearCache to fail after Close")
 	}
 
-	if err := client.Close(); err == nil {
-		t.Fatalf("expected second Close to fail")
+	if err := client.Close(); err != nil {
+		t.Fatalf("expected second Close to be a no-op, got %v", err)
 	}
 }
 
@@ -326,4 +335,2331 @@ func TestClientVerifyTCCAPIError(t *testing.T) {
 	if !errors.As(err, &apiErr) {
 		t.Fatalf("expected APIError, got %v", err)
 	}
+	if code := KRACode(err); code != "TCC_INVALID" {
+		t.Errorf("KRACode() = %q, want TCC_INVALID", code)
+	}
+}
+
+func TestClientRegisterAndDeregisterObligation(t *testing.T) {
+	var paths []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		var body map[string]map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		details := body["TAXPAYERDETAILS"]
+		if details["TaxpayerPIN"] != "P051234567A" || details["EffectiveDate"] != "2024-01-01" {
+			t.Fatalf("unexpected payload: %v", body)
+		}
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"success":         true,
+				"referenceNumber": "REG123",
+				"status":          "APPROVED",
+			},
+		})
+	}
+
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	ctx := context.Background()
+	req := &ObligationRegistrationRequest{
+		PINNumber:      "p051234567a",
+		ObligationCode: 1,
+		EffectiveDate:  "2024-01-01",
+	}
+
+	res, err := client.RegisterObligation(ctx, req)
+	if err != nil || !res.Success || res.ReferenceNumber != "REG123" || res.Status != "approved" {
+		t.Fatalf("RegisterObligation() = %+v, %v", res, err)
+	}
+
+	if _, err := client.DeregisterObligation(ctx, req); err != nil {
+		t.Fatalf("DeregisterObligation() error = %v", err)
+	}
+
+	// Writes must never be served from cache
+	if _, err := client.RegisterObligation(ctx, req); err != nil {
+		t.Fatalf("RegisterObligation() second call error = %v", err)
+	}
+
+	want := []string{"/dtd/obligation/v1/register", "/dtd/obligation/v1/deregister", "/dtd/obligation/v1/register"}
+	if len(paths) != len(want) {
+		t.Fatalf("expected %d requests, got %v", len(want), paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("request %d path = %s, want %s", i, paths[i], want[i])
+		}
+	}
+}
+
+func TestClientRegisterObligationValidation(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request to %s", r.URL.Path)
+	}
+
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	ctx := context.Background()
+	cases := []*ObligationRegistrationRequest{
+		nil,
+		{PINNumber: "INVALID", ObligationCode: 1, EffectiveDate: "2024-01-01"},
+		{PINNumber: "P051234567A", ObligationCode: 0, EffectiveDate: "2024-01-01"},
+		{PINNumber: "P051234567A", ObligationCode: 1, EffectiveDate: ""},
+		{PINNumber: "P051234567A", ObligationCode: 1, EffectiveDate: "01/01/2024"},
+	}
+	for i, req := range cases {
+		if _, err := client.RegisterObligation(ctx, req); err == nil {
+			t.Fatalf("case %d: expected validation error", i)
+		}
+	}
+}
+
+func TestClientVerifyPINsUnique(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		requests[payload["KRAPIN"]]++
+		mu.Unlock()
+		if payload["KRAPIN"] == "P051234567C" {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(t, w, apiResponse{Success: false, Error: &apiErrorResponse{Code: "E400", Message: "bad request"}})
+			return
+		}
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"isValid": true, "status": "active"}})
+	}
+	client, server := newClientWithServer(t, handler, WithoutCache())
+	defer server.Close()
+
+	pins := []string{" p051234567a", "P051234567A\t", "P051234567B", "bad", "", "  ", "BAD ", "P051234567C", "p051234567b"}
+	results, errs := client.VerifyPINsUnique(context.Background(), pins)
+
+	if len(results) != 2 || results["P051234567A"] == nil || results["P051234567B"] == nil {
+		t.Fatalf("unexpected results: %v", results)
+	}
+	if results["P051234567A"].PINNumber != "P051234567A" {
+		t.Fatalf("result keyed under the wrong PIN: %+v", results["P051234567A"])
+	}
+	if len(errs) != 2 || errs["BAD"] == nil || errs["P051234567C"] == nil {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	var formatErr *InvalidPINFormatError
+	if !errors.As(errs["BAD"], &formatErr) {
+		t.Fatalf("expected format error for BAD, got %v", errs["BAD"])
+	}
+	if len(requests) != 3 || requests["P051234567A"] != 1 || requests["P051234567B"] != 1 || requests["P051234567C"] != 1 {
+		t.Fatalf("expected each distinct valid PIN verified once, got %v", requests)
+	}
+}
+
+func TestClientFindInvalidPINs(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		switch payload["KRAPIN"] {
+		case "P051234567B":
+			w.WriteHeader(http.StatusNotFound)
+			writeJSON(t, w, apiResponse{Success: false, Error: &apiErrorResponse{Code: "E404", Message: "PIN not found"}})
+		case "P051234567C":
+			writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"isValid": false}})
+		case "P051234567D":
+			writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"isValid": true, "status": "dormant"}})
+		case "P051234567E":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"isValid": true, "status": "active"}})
+		}
+	}
+	client, server := newClientWithServer(t, handler, WithRetry(0, time.Millisecond, time.Millisecond))
+	defer server.Close()
+
+	ctx := context.Background()
+	pins := []string{"P051234567A", "bad-pin", "P051234567B", "P051234567C", "P051234567D"}
+	invalid, err := client.FindInvalidPINs(ctx, pins)
+	if err != nil {
+		t.Fatalf("FindInvalidPINs() error = %v", err)
+	}
+
+	want := []struct {
+		index  int
+		reason InvalidPINReason
+	}{
+		{1, InvalidPINValidation},
+		{2, InvalidPINNotFound},
+		{3, InvalidPINNotFound},
+		{4, InvalidPINInactive},
+	}
+	if len(invalid) != len(want) {
+		t.Fatalf("expected %d invalid PINs, got %+v", len(want), invalid)
+	}
+	for i, w := range want {
+		if invalid[i].Index != w.index || invalid[i].PIN != pins[w.index] || invalid[i].Reason != w.reason {
+			t.Fatalf("entry %d = %+v, want index %d reason %s", i, invalid[i], w.index, w.reason)
+		}
+	}
+	if invalid[0].Err == nil || invalid[3].Result == nil || invalid[3].Result.Status != "dormant" {
+		t.Fatalf("expected error and result details, got %+v and %+v", invalid[0], invalid[3])
+	}
+
+	// Unclassifiable failures are returned as the error alongside the rest
+	invalid, err = client.FindInvalidPINs(ctx, []string{"P051234567E", "bad-pin"})
+	if err == nil {
+		t.Fatal("expected error for a server failure")
+	}
+	if len(invalid) != 1 || invalid[0].Reason != InvalidPINValidation {
+		t.Fatalf("expected the malformed PIN alongside the error, got %+v", invalid)
+	}
+
+	invalid, err = client.FindInvalidPINs(ctx, []string{"P051234567A"})
+	if err != nil || len(invalid) != 0 {
+		t.Fatalf("expected no invalid PINs, got %+v, %v", invalid, err)
+	}
+}
+
+func TestClientVerifyPINsChunked(t *testing.T) {
+	var inFlight, maxInFlight int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"kraPin":  body["KRAPIN"],
+				"isValid": true,
+				"status":  "active",
+			},
+		})
+	}
+
+	client, server := newClientWithServer(t, handler, WithoutCache())
+	defer server.Close()
+
+	pins := []string{
+		"P051234567A", "P051234567B", "P051234567C", "P051234567D",
+		"INVALID", "P051234567F", "P051234567G",
+	}
+	results, errs := client.VerifyPINsChunked(context.Background(), pins, 3)
+
+	if len(results) != len(pins) || len(errs) != len(pins) {
+		t.Fatalf("expected %d results and errors, got %d and %d", len(pins), len(results), len(errs))
+	}
+	for i, pin := range pins {
+		if pin == "INVALID" {
+			if errs[i] == nil || results[i] != nil {
+				t.Fatalf("expected error for invalid PIN at %d, got %+v, %v", i, results[i], errs[i])
+			}
+			continue
+		}
+		if errs[i] != nil || results[i] == nil || results[i].PINNumber != pin {
+			t.Fatalf("unexpected result at %d: %+v, %v", i, results[i], errs[i])
+		}
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got > 3 {
+		t.Fatalf("expected at most 3 concurrent requests, got %d", got)
+	}
+}
+
+func TestFileNILReturnPayloadSnapshot(t *testing.T) {
+	const want = `{"TAXPAYERDETAILS":{"Month":3,"ObligationCode":1,"TaxpayerPIN":"P051234567A","Year":2024}}`
+
+	var got string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+		got = string(body)
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"success": true, "status": "accepted"},
+		})
+	}
+
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	req := &NILReturnRequest{
+		PINNumber:      " p051234567a ",
+		ObligationCode: 1,
+		Month:          3,
+		Year:           2024,
+	}
+	if _, err := client.FileNILReturn(context.Background(), req); err != nil {
+		t.Fatalf("FileNILReturn() error = %v", err)
+	}
+	if got != want {
+		t.Fatalf("FileNILReturn() sent %s, want %s", got, want)
+	}
+
+	payload, err := BuildNILReturnPayload(req)
+	if err != nil {
+		t.Fatalf("BuildNILReturnPayload() error = %v", err)
+	}
+	built, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	if string(built) != want {
+		t.Fatalf("BuildNILReturnPayload() = %s, want %s", built, want)
+	}
+}
+
+func TestClientFileNILReturnCustomFieldNames(t *testing.T) {
+	var got string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"status": "accepted"}})
+	}
+	client, server := newClientWithServer(t, handler,
+		WithNILFieldNames(FieldNameMap{ObligationCode: "obligationCode"}),
+		WithNILFieldNames(FieldNameMap{Details: "taxpayerDetails", TaxpayerPIN: "pin"}),
+	)
+	defer server.Close()
+
+	req := &NILReturnRequest{PINNumber: "P051234567A", ObligationCode: 1, Month: 1, Year: 2024}
+	if _, err := client.FileNILReturn(context.Background(), req); err != nil {
+		t.Fatalf("FileNILReturn() error = %v", err)
+	}
+
+	want := `{"taxpayerDetails":{"Month":1,"Year":2024,"obligationCode":1,"pin":"P051234567A"}}`
+	if got != want {
+		t.Fatalf("FileNILReturn() sent %s, want %s", got, want)
+	}
+
+	if err := WithNILFieldNames(FieldNameMap{})(DefaultConfig()); err == nil {
+		t.Fatal("expected error for empty field name map")
+	}
+}
+
+func TestBuildNILReturnPayloadValidation(t *testing.T) {
+	cases := []*NILReturnRequest{
+		nil,
+		{PINNumber: "INVALID", ObligationCode: 1, Month: 1, Year: 2024},
+		{PINNumber: "P051234567A", ObligationCode: 0, Month: 1, Year: 2024},
+		{PINNumber: "P051234567A", ObligationCode: 1, Month: 13, Year: 2024},
+		{PINNumber: "P051234567A", ObligationCode: 1, Month: 1, Year: 1999},
+	}
+	for i, req := range cases {
+		if _, err := BuildNILReturnPayload(req); err == nil {
+			t.Fatalf("case %d: expected validation error", i)
+		}
+	}
+}
+
+func TestClientNamePrefixesDebugLogs(t *testing.T) {
+	var buf bytes.Buffer
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active"},
+		})
+	}
+	client, server := newClientWithServer(t, handler,
+		WithDebug(true),
+		WithClientName("tenant-a"),
+		WithLogger(log.New(&buf, "", 0)),
+	)
+	defer server.Close()
+
+	if _, err := client.VerifyPIN(context.Background(), "P051234567A"); err != nil {
+		t.Fatalf("VerifyPIN() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		t.Fatal("expected debug output")
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "[tenant-a] ") {
+			t.Fatalf("expected client name prefix, got %q", line)
+		}
+	}
+	if !strings.Contains(buf.String(), "[tenant-a] [HTTP] REQUEST") || !strings.Contains(buf.String(), "[tenant-a] [Cache] SET") {
+		t.Fatalf("expected HTTP and cache logs to be labelled, got:\n%s", buf.String())
+	}
+
+	if _, err := NewClient(WithAPIKey(testAPIKey), WithClientName("  ")); err == nil {
+		t.Fatal("expected error for blank client name")
+	}
+}
+
+func TestClientEvictsMistypedCacheEntry(t *testing.T) {
+	var hits int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active"},
+		})
+	}
+
+	var buf bytes.Buffer
+	client, server := newClientWithServer(t, handler, WithLogger(log.New(&buf, "", 0)))
+	defer server.Close()
+
+	cacheKey := GenerateCacheKey("pin_verification", "P051234567A")
+	client.cacheManager.Set(cacheKey, &TCCVerificationResult{}, time.Hour)
+
+	result, err := client.VerifyPIN(context.Background(), "P051234567A")
+	if err != nil || result == nil {
+		t.Fatalf("VerifyPIN() = %v, %v", result, err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Fatalf("expected mistyped entry to be refetched, got %d calls", got)
+	}
+	if !strings.Contains(buf.String(), "WARN") || !strings.Contains(buf.String(), cacheKey) {
+		t.Fatalf("expected warning mentioning %s, got %q", cacheKey, buf.String())
+	}
+
+	cached, found := client.cacheManager.Get(cacheKey)
+	if !found {
+		t.Fatal("expected refetched result to be cached")
+	}
+	if _, ok := cached.(*PINVerificationResult); !ok {
+		t.Fatalf("expected mistyped entry to be replaced, got %T", cached)
+	}
+}
+
+func TestClientCacheKeyPrefixIsolatesSharedCache(t *testing.T) {
+	var hits int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active"},
+		})
+	}
+
+	tenantA, serverA := newClientWithServer(t, handler, WithCacheKeyPrefix("tenant-a"))
+	defer serverA.Close()
+	tenantB, serverB := newClientWithServer(t, handler, WithCacheKeyPrefix("tenant-b"))
+	defer serverB.Close()
+
+	// Both clients share one cache backend
+	tenantB.cacheManager = tenantA.cacheManager
+
+	ctx := context.Background()
+	for _, client := range []*Client{tenantA, tenantB, tenantA, tenantB} {
+		if _, err := client.VerifyPIN(ctx, "P051234567A"); err != nil {
+			t.Fatalf("VerifyPIN() error = %v", err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Fatalf("expected one network call per tenant, got %d", got)
+	}
+
+	keys, err := tenantA.CacheKeys()
+	if err != nil {
+		t.Fatalf("CacheKeys() error = %v", err)
+	}
+	want := []string{"tenant-a:pin_verification:P051234567A", "tenant-b:pin_verification:P051234567A"}
+	if len(keys) != len(want) || keys[0] != want[0] || keys[1] != want[1] {
+		t.Fatalf("CacheKeys() = %v, want %v", keys, want)
+	}
+}
+
+func TestClientFileDueNILReturns(t *testing.T) {
+	var filings []map[string]interface{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dtd/checker/v1/obligation":
+			writeJSON(t, w, apiResponse{
+				Success: true,
+				Data: map[string]interface{}{
+					"obligations": []map[string]interface{}{
+						{"obligationId": "1", "obligationType": "VAT", "isActive": true, "effectiveDate": "2020-01-01"},
+						{"obligationId": "1", "obligationType": "VAT", "isActive": true},
+						{"obligationId": "2", "obligationType": "PAYE", "isActive": false},
+						{"obligationId": "VAT-X", "obligationType": "VAT", "isActive": true},
+						{"obligationId": "3", "obligationType": "PAYE", "isActive": true, "effectiveDate": "2024-02-01"},
+						{"obligationId": "4", "obligationType": "PAYE", "isActive": true, "endDate": "2023-12-31"},
+						{"obligationId": "5", "obligationType": "Income Tax - Resident Individual", "isActive": true, "frequency": "Annual"},
+						{"obligationId": "7", "obligationType": "PAYE", "isActive": true, "endDate": "2024-01-15"},
+					},
+				},
+			})
+		case "/dtd/return/v1/nil":
+			var body map[string]map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			filings = append(filings, body["TAXPAYERDETAILS"])
+			writeJSON(t, w, apiResponse{
+				Success: true,
+				Data:    map[string]interface{}{"success": true, "status": "accepted"},
+			})
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}
+
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	ctx := context.Background()
+	results, err := client.FileDueNILReturns(ctx, "P051234567A", "202401")
+	if err != nil {
+		t.Fatalf("FileDueNILReturns() error = %v", err)
+	}
+	if len(results) != 2 || results[0].ObligationID != "1" || results[1].ObligationID != "7" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if len(filings) != 2 || filings[0]["Month"] != float64(1) || filings[0]["Year"] != float64(2024) {
+		t.Fatalf("unexpected filings: %v", filings)
+	}
+
+	// A repeated call for the same period must not file again
+	again, err := client.FileDueNILReturns(ctx, "P051234567A", "202401")
+	if err != nil {
+		t.Fatalf("FileDueNILReturns() second call error = %v", err)
+	}
+	if len(again) != 2 || len(filings) != 2 {
+		t.Fatalf("expected idempotent re-run, got %d results and %d filings", len(again), len(filings))
+	}
+
+	if _, err := client.FileDueNILReturns(ctx, "P051234567A", "2024-01"); err == nil {
+		t.Fatal("expected error for invalid period")
+	}
+}
+
+func TestClientConcurrentNILFilingsFileOnce(t *testing.T) {
+	var filings int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dtd/checker/v1/obligation":
+			writeJSON(t, w, apiResponse{
+				Success: true,
+				Data: map[string]interface{}{
+					"obligations": []map[string]interface{}{
+						{"obligationId": "1", "obligationType": "VAT", "isActive": true},
+					},
+				},
+			})
+		case "/dtd/return/v1/nil":
+			atomic.AddInt32(&filings, 1)
+			time.Sleep(50 * time.Millisecond)
+			writeJSON(t, w, apiResponse{
+				Success: true,
+				Data:    map[string]interface{}{"success": true, "status": "accepted"},
+			})
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}
+
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	const callers = 3
+	errs := make(chan error, 2*callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, periodErrs := client.FileNILReturnForPeriods(context.Background(), "P051234567A", 1, []string{"202401"})
+			errs <- periodErrs[0]
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := client.FileDueNILReturns(context.Background(), "P051234567A", "202401")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("filing error = %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&filings); got != 1 {
+		t.Fatalf("expected concurrent duplicates to file once, got %d filings", got)
+	}
+}
+
+func TestClientFileNILReturnForPeriods(t *testing.T) {
+	var mu sync.Mutex
+	filed := make(map[string]int)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		details := body["TAXPAYERDETAILS"]
+		period := fmt.Sprintf("%04.0f%02.0f", details["Year"], details["Month"])
+		mu.Lock()
+		filed[period]++
+		mu.Unlock()
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"success": true, "status": "accepted", "referenceNumber": "REF-" + period},
+		})
+	}
+
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	ctx := context.Background()
+	periods := []string{"202401", " 202402 ", "2024-03", "202401", "202413"}
+	results, errs := client.FileNILReturnForPeriods(ctx, "P051234567A", 1, periods)
+	if len(results) != len(periods) || len(errs) != len(periods) {
+		t.Fatalf("expected %d results and errors, got %d and %d", len(periods), len(results), len(errs))
+	}
+
+	for _, i := range []int{0, 1, 3} {
+		if errs[i] != nil || results[i] == nil {
+			t.Fatalf("period %q: result = %+v, err = %v", periods[i], results[i], errs[i])
+		}
+	}
+	if results[0].Period != "202401" || results[1].ReferenceNumber != "REF-202402" {
+		t.Fatalf("unexpected results: %+v, %+v", results[0], results[1])
+	}
+	if results[3].ReferenceNumber != results[0].ReferenceNumber {
+		t.Fatalf("expected duplicate period to share its result, got %+v", results[3])
+	}
+
+	for _, i := range []int{2, 4} {
+		var validationErr *ValidationError
+		if !errors.As(errs[i], &validationErr) || results[i] != nil {
+			t.Fatalf("period %q: expected validation error, got result %+v, err %v", periods[i], results[i], errs[i])
+		}
+	}
+
+	if len(filed) != 2 || filed["202401"] != 1 || filed["202402"] != 1 {
+		t.Fatalf("expected each valid period filed once, got %v", filed)
+	}
+
+	// Re-running must not file the successful periods again
+	_, errs = client.FileNILReturnForPeriods(ctx, "P051234567A", 1, []string{"202401", "202402", "202403"})
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("re-run period %d: %v", i, err)
+		}
+	}
+	if filed["202401"] != 1 || filed["202402"] != 1 || filed["202403"] != 1 {
+		t.Fatalf("expected idempotent re-run, got %v", filed)
+	}
+
+	_, errs = client.FileNILReturnForPeriods(ctx, "invalid", 1, []string{"202401", "202402"})
+	if errs[0] == nil || errs[1] == nil {
+		t.Fatalf("expected invalid PIN to fail every period, got %v", errs)
+	}
+}
+
+func TestClientSummaryOnClose(t *testing.T) {
+	var calls int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active"},
+		})
+	}
+
+	var buf bytes.Buffer
+	client, server := newClientWithServer(t, handler,
+		WithSummaryOnClose(true),
+		WithLogger(log.New(&buf, "", 0)),
+		WithRetry(2, 5*time.Millisecond, 5*time.Millisecond),
+	)
+	defer server.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := client.VerifyPIN(ctx, "P051234567A"); err != nil {
+			t.Fatalf("VerifyPIN() error = %v", err)
+		}
+	}
+	if _, err := client.VerifyPIN(ctx, "INVALID"); err == nil {
+		t.Fatal("expected validation error")
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := "[Client] SUMMARY: requests=2 cache_hits=1 cache_misses=1 cache_hit_ratio=50.0% retries=1 rate_limit_waits=0 errors=none"
+	if got := strings.TrimSpace(buf.String()); got != want {
+		t.Fatalf("summary = %q, want %q", got, want)
+	}
+}
+
+func TestClientSummaryNotLoggedByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	client, err := NewClient(WithAPIKey(testAPIKey), WithLogger(log.New(&buf, "", 0)))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected no summary, got %q", buf.String())
+	}
+}
+
+func TestClientDryRunMakesNoRequests(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request to %s in dry-run mode", r.URL.Path)
+	}
+	client, server := newClientWithServer(t, handler, WithDryRun(true))
+	defer server.Close()
+
+	ctx := context.Background()
+	pinResult, err := client.VerifyPIN(ctx, "p051234567a")
+	if err != nil {
+		t.Fatalf("VerifyPIN() error = %v", err)
+	}
+	if !pinResult.Metadata.DryRun || pinResult.PINNumber != "P051234567A" {
+		t.Fatalf("expected dry-run PIN result, got %+v", pinResult)
+	}
+
+	nilResult, err := client.FileNILReturn(ctx, &NILReturnRequest{
+		PINNumber:      "P051234567A",
+		ObligationCode: 1,
+		Month:          1,
+		Year:           2024,
+	})
+	if err != nil || !nilResult.Metadata.DryRun {
+		t.Fatalf("FileNILReturn() = %+v, %v", nilResult, err)
+	}
+
+	details, err := client.GetTaxpayerDetails(ctx, "P051234567A")
+	if err != nil || !details.Metadata.DryRun {
+		t.Fatalf("GetTaxpayerDetails() = %+v, %v", details, err)
+	}
+
+	if _, err := client.VerifyPIN(ctx, "INVALID"); err == nil {
+		t.Fatal("expected validation to run in dry-run mode")
+	}
+
+	if keys, _ := client.CacheKeys(); len(keys) != 0 {
+		t.Fatalf("expected dry-run results not to be cached, got %v", keys)
+	}
+}
+
+func TestClientGetObligationHistoryFollowsPages(t *testing.T) {
+	var pages []float64
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dtd/checker/v1/obligation/history" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		page := body["pageNumber"].(float64)
+		pages = append(pages, page)
+
+		data := map[string]interface{}{
+			"filings": []map[string]interface{}{
+				{"period": fmt.Sprintf("2024-%02d", int(page)), "status": "FILED", "taxAmount": 0, "dueDate": "2024-02-20", "filingDate": "2024-02-25"},
+			},
+			"totalPages": 3,
+		}
+		if page == 1 {
+			if body["pageToken"] != nil {
+				t.Fatalf("unexpected page token on first page: %v", body["pageToken"])
+			}
+			data["nextPageToken"] = "cursor-2"
+		} else if page == 2 && body["pageToken"] != "cursor-2" {
+			t.Fatalf("expected page token cursor-2, got %v", body["pageToken"])
+		}
+		writeJSON(t, w, apiResponse{Success: true, Data: data})
+	}
+
+	// Reading exactly the last page at the limit is not a truncation
+	client, server := newClientWithServer(t, handler, WithObligationHistoryMaxPages(3))
+	defer server.Close()
+
+	records, err := client.GetObligationHistory(context.Background(), "P051234567A", "OBL123456")
+	if err != nil {
+		t.Fatalf("GetObligationHistory() error = %v", err)
+	}
+	if len(records) != 3 || len(pages) != 3 {
+		t.Fatalf("expected 3 records over 3 pages, got %d records over %v", len(records), pages)
+	}
+	if records[2].Period != "2024-03" || records[0].Status != "filed" || records[0].ObligationID != "OBL123456" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+	if !records[0].IsLate() {
+		t.Fatalf("expected record filed after due date to be late")
+	}
+}
+
+func TestClientGetObligationHistoryRespectsMaxPages(t *testing.T) {
+	requests := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"records": []map[string]interface{}{{"period": "2024-01"}},
+				"hasMore": true,
+			},
+		})
+	}
+
+	client, server := newClientWithServer(t, handler, WithObligationHistoryMaxPages(2))
+	defer server.Close()
+
+	records, err := client.GetObligationHistory(context.Background(), "P051234567A", "OBL123456")
+	if !errors.Is(err, ErrHistoryTruncated) {
+		t.Fatalf("expected ErrHistoryTruncated, got %v", err)
+	}
+	if requests != 2 || len(records) != 2 {
+		t.Fatalf("expected 2 requests and records, got %d requests and %d records", requests, len(records))
+	}
+}
+
+func TestClientVerifyPINWithOptions(t *testing.T) {
+	var pinCalls, obligationCalls int
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/checker/v1/pinbypin":
+			pinCalls++
+			writeJSON(t, w, apiResponse{
+				Success: true,
+				Data: map[string]interface{}{
+					"kraPin":       "P051234567A",
+					"taxpayerName": "Acme Ltd",
+					"pinStatus":    "active",
+				},
+			})
+		case "/dtd/checker/v1/obligation":
+			obligationCalls++
+			writeJSON(t, w, apiResponse{
+				Success: true,
+				Data: map[string]interface{}{
+					"obligations": []map[string]interface{}{
+						{"obligationType": "VAT", "isActive": true},
+					},
+				},
+			})
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}
+
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	plain, err := client.VerifyPINWithOptions(context.Background(), "P051234567A", PINVerifyOptions{})
+	if err != nil {
+		t.Fatalf("VerifyPINWithOptions() error = %v", err)
+	}
+	if len(plain.Obligations) != 0 || obligationCalls != 0 {
+		t.Fatalf("expected no obligations without IncludeObligations, got %d (calls=%d)", len(plain.Obligations), obligationCalls)
+	}
+
+	opts := PINVerifyOptions{IncludeObligations: true}
+	for i := 0; i < 2; i++ {
+		result, err := client.VerifyPINWithOptions(context.Background(), "P051234567A", opts)
+		if err != nil {
+			t.Fatalf("VerifyPINWithOptions() error = %v", err)
+		}
+		if len(result.Obligations) != 1 || result.Obligations[0].ObligationType != "VAT" {
+			t.Fatalf("unexpected obligations: %+v", result.Obligations)
+		}
+	}
+
+	if pinCalls != 1 || obligationCalls != 1 {
+		t.Fatalf("expected one call per endpoint, got pin=%d obligations=%d", pinCalls, obligationCalls)
+	}
+	if len(plain.Obligations) != 0 {
+		t.Fatal("expected cached plain verification result to be left untouched")
+	}
+}
+
+type testEnvKey struct{}
+
+func TestClientCacheKeyFuncUsesContext(t *testing.T) {
+	var calls int
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"pinStatus": "active"}})
+	}
+
+	client, server := newClientWithServer(t, handler,
+		WithCacheKeyFunc(func(ctx context.Context, operation string, params ...string) string {
+			env, _ := ctx.Value(testEnvKey{}).(string)
+			return env + ":" + GenerateCacheKey(operation, params...)
+		}),
+	)
+	defer server.Close()
+
+	blue := context.WithValue(context.Background(), testEnvKey{}, "blue")
+	green := context.WithValue(context.Background(), testEnvKey{}, "green")
+
+	for _, ctx := range []context.Context{blue, green, blue, green} {
+		if _, err := client.VerifyPIN(ctx, "P051234567A"); err != nil {
+			t.Fatalf("VerifyPIN() error = %v", err)
+		}
+	}
+
+	if calls != 2 {
+		t.Fatalf("expected one API call per context tag, got %d", calls)
+	}
+
+	keys, err := client.CacheKeys()
+	if err != nil {
+		t.Fatalf("CacheKeys() error = %v", err)
+	}
+	want := []string{"blue:pin_verification:P051234567A", "green:pin_verification:P051234567A"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Fatalf("CacheKeys() = %v, want %v", keys, want)
+	}
+}
+
+func TestClientTokenInfoAndRefreshHook(t *testing.T) {
+	var tokenStatus int32 = http.StatusOK
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status := int(atomic.LoadInt32(&tokenStatus)); status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		writeJSON(t, w, map[string]interface{}{"access_token": "token-1", "expires_in": "3600"})
+	}))
+	defer tokenServer.Close()
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"pinStatus": "active"}})
+	}))
+	defer apiServer.Close()
+
+	var refreshErrs []error
+	client, err := NewClient(
+		WithClientCredentials("client-id", "client-secret"),
+		WithBaseURL(apiServer.URL),
+		WithTokenURL(tokenServer.URL),
+		WithoutRateLimit(),
+		WithoutCache(),
+		WithRetry(0, time.Millisecond, time.Millisecond),
+		WithTokenRefreshHook(func(err error) {
+			refreshErrs = append(refreshErrs, err)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if expiresAt, fromCache := client.TokenInfo(); !expiresAt.IsZero() || fromCache {
+		t.Fatalf("expected no token before first request, got %v, %v", expiresAt, fromCache)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.VerifyPIN(context.Background(), "P051234567A"); err != nil {
+			t.Fatalf("VerifyPIN() error = %v", err)
+		}
+	}
+
+	expiresAt, fromCache := client.TokenInfo()
+	if !fromCache || time.Until(expiresAt) < 59*time.Minute {
+		t.Fatalf("expected cached token valid for ~1h, got %v, %v", expiresAt, fromCache)
+	}
+	if len(refreshErrs) != 1 || refreshErrs[0] != nil {
+		t.Fatalf("expected one successful refresh, got %v", refreshErrs)
+	}
+
+	// Force a refresh that fails
+	client.httpClient.auth.mu.Lock()
+	client.httpClient.auth.expiresAt = time.Now()
+	client.httpClient.auth.mu.Unlock()
+	atomic.StoreInt32(&tokenStatus, http.StatusInternalServerError)
+
+	if _, err := client.VerifyPIN(context.Background(), "P051234567A"); err == nil {
+		t.Fatal("expected error when token refresh fails")
+	}
+	if len(refreshErrs) != 2 || refreshErrs[1] == nil {
+		t.Fatalf("expected failed refresh to be reported, got %v", refreshErrs)
+	}
+	if _, fromCache := client.TokenInfo(); fromCache {
+		t.Fatal("expected expired token not to be reported as cached")
+	}
+}
+
+func TestClientBatchResultsKeepInputOrder(t *testing.T) {
+	// Later inputs answer sooner, so completion order is the reverse of input order
+	delays := map[string]time.Duration{
+		"P051234567A": 60 * time.Millisecond, "P051234567B": 40 * time.Millisecond, "P051234567C": 20 * time.Millisecond, "P051234567D": 0,
+		"TCC100001": 60 * time.Millisecond, "TCC100002": 40 * time.Millisecond, "TCC100003": 20 * time.Millisecond, "TCC100004": 0,
+		"1000000001": 60 * time.Millisecond, "1000000002": 40 * time.Millisecond, "1000000003": 20 * time.Millisecond, "1000000004": 0,
+	}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		key := payload["KRAPIN"] + payload["tccNumber"] + payload["EslipNumber"]
+		time.Sleep(delays[key])
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active"},
+		})
+	}
+	client, server := newClientWithServer(t, handler, WithoutCache())
+	defer server.Close()
+
+	ctx := context.Background()
+	pins := []string{"P051234567A", "P051234567B", "P051234567C", "P051234567D"}
+	pinResults, err := client.VerifyPINsBatch(ctx, pins)
+	if err != nil {
+		t.Fatalf("VerifyPINsBatch() error = %v", err)
+	}
+	for i, pin := range pins {
+		if pinResults[i] == nil || pinResults[i].PINNumber != pin {
+			t.Fatalf("pin result %d = %+v, want %s", i, pinResults[i], pin)
+		}
+	}
+
+	var requests []*TCCVerificationRequest
+	for _, tcc := range []string{"TCC100001", "TCC100002", "TCC100003", "TCC100004"} {
+		requests = append(requests, &TCCVerificationRequest{KraPIN: "P051234567A", TCCNumber: tcc})
+	}
+	tccResults, err := client.VerifyTCCsBatch(ctx, requests)
+	if err != nil {
+		t.Fatalf("VerifyTCCsBatch() error = %v", err)
+	}
+	for i, req := range requests {
+		if tccResults[i] == nil || tccResults[i].TCCNumber != req.TCCNumber {
+			t.Fatalf("tcc result %d = %+v, want %s", i, tccResults[i], req.TCCNumber)
+		}
+	}
+
+	eslips := []string{"1000000001", "1000000002", "1000000003", "1000000004"}
+	eslipResults, err := client.ValidateEslipsBatch(ctx, eslips)
+	if err != nil {
+		t.Fatalf("ValidateEslipsBatch() error = %v", err)
+	}
+	for i, eslip := range eslips {
+		if eslipResults[i] == nil || eslipResults[i].EslipNumber != eslip {
+			t.Fatalf("eslip result %d = %+v, want %s", i, eslipResults[i], eslip)
+		}
+	}
+}
+
+func TestClientVerifyPINsBatchReturnsPromptlyOnCancel(t *testing.T) {
+	var requests int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	release := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == batchWorkers {
+			cancel()
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"pinStatus": "active"}})
+	}
+
+	client, server := newClientWithServer(t, handler, WithRetry(0, time.Millisecond, time.Millisecond))
+	defer server.Close()
+	defer close(release)
+
+	pins := make([]string, 50)
+	for i := range pins {
+		pins[i] = fmt.Sprintf("P05%07dA", i)
+	}
+
+	start := time.Now()
+	results, err := client.VerifyPINsBatch(ctx, pins)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected prompt return after cancel, took %v", elapsed)
+	}
+	if len(results) != len(pins) {
+		t.Fatalf("expected %d result slots, got %d", len(pins), len(results))
+	}
+
+	// Give any stray dispatches a chance to reach the server
+	time.Sleep(50 * time.Millisecond)
+	if got := atomic.LoadInt32(&requests); got > batchWorkers {
+		t.Fatalf("expected at most %d requests after cancel, got %d", batchWorkers, got)
+	}
+}
+
+func TestClientCloseWaitsForCancelledBatchWorkers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stuck := make(chan struct{})
+	release := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		<-stuck
+		cancel()
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"pinStatus": "active"}})
+	}
+	// Hold the second item's worker inside VerifyPIN past the cancellation
+	keyFunc := func(ctx context.Context, operation string, params ...string) string {
+		if len(params) > 0 && params[0] == "P050000001A" {
+			close(stuck)
+			<-release
+		}
+		return GenerateCacheKey(operation, params...)
+	}
+	client, server := newClientWithServer(t, handler,
+		WithRetry(0, time.Millisecond, time.Millisecond),
+		WithCacheKeyFunc(keyFunc),
+	)
+	defer server.Close()
+
+	if _, err := client.VerifyPINsBatch(ctx, []string{"P050000000A", "P050000001A"}); !errors.Is(err, context.Canceled) {
+		close(release)
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	closeDone := make(chan error, 1)
+	go func() { closeDone <- client.Close() }()
+
+	select {
+	case err := <-closeDone:
+		close(release)
+		t.Fatalf("Close() returned while a batch worker was still running: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	select {
+	case err := <-closeDone:
+		if err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close() did not return after the batch worker finished")
+	}
+}
+
+func TestClientCloseDuringBatch(t *testing.T) {
+	var requests int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == batchWorkers {
+			close(started)
+		}
+		<-release
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"pinStatus": "active"}})
+	}
+
+	client, server := newClientWithServer(t, handler, WithRetry(0, time.Millisecond, time.Millisecond))
+	defer server.Close()
+
+	pins := make([]string, 30)
+	for i := range pins {
+		pins[i] = fmt.Sprintf("P05%07dA", i)
+	}
+
+	type batchOutcome struct {
+		results []*PINVerificationResult
+		err     error
+	}
+	batchDone := make(chan batchOutcome, 1)
+	go func() {
+		results, err := client.VerifyPINsBatch(context.Background(), pins)
+		batchDone <- batchOutcome{results, err}
+	}()
+
+	<-started
+	closeDone := make(chan error, 1)
+	go func() { closeDone <- client.Close() }()
+
+	// Close must wait for the in-flight requests of the running batch
+	select {
+	case err := <-closeDone:
+		t.Fatalf("Close() returned before batch finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	outcome := <-batchDone
+	if !errors.Is(outcome.err, ErrClosedDuringBatch) {
+		t.Fatalf("expected ErrClosedDuringBatch, got %v", outcome.err)
+	}
+	if err := <-closeDone; err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	completed := 0
+	for _, result := range outcome.results {
+		if result != nil {
+			completed++
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != batchWorkers || completed != batchWorkers {
+		t.Fatalf("expected only the %d in-flight PINs to complete, got %d requests and %d results", batchWorkers, got, completed)
+	}
+
+	if _, err := client.VerifyPINsBatch(context.Background(), pins); err == nil || errors.Is(err, ErrClosedDuringBatch) {
+		t.Fatalf("expected closed-client error for a batch started after Close, got %v", err)
+	}
+}
+
+func TestClientCloseDuringOtherBatches(t *testing.T) {
+	pins := make([]string, 30)
+	eslips := make([]string, 30)
+	tccs := make([]*TCCVerificationRequest, 30)
+	for i := range pins {
+		pins[i] = fmt.Sprintf("P05%07dA", i)
+		eslips[i] = fmt.Sprintf("12345%05d", i)
+		tccs[i] = &TCCVerificationRequest{KraPIN: pins[i], TCCNumber: fmt.Sprintf("TCC%d", i)}
+	}
+
+	tests := []struct {
+		name     string
+		inFlight int32
+		run      func(client *Client) error
+	}{
+		{"GenerateComplianceReport", batchWorkers, func(client *Client) error {
+			_, err := client.GenerateComplianceReport(context.Background(), pins)
+			return err
+		}},
+		{"ReconcileEslips", batchWorkers, func(client *Client) error {
+			_, err := client.ReconcileEslips(context.Background(), eslips)
+			return err
+		}},
+		{"ValidateEslipsBatch", batchWorkers, func(client *Client) error {
+			_, err := client.ValidateEslipsBatch(context.Background(), eslips)
+			return err
+		}},
+		{"VerifyTCCsBatch", batchWorkers, func(client *Client) error {
+			_, err := client.VerifyTCCsBatch(context.Background(), tccs)
+			return err
+		}},
+		{"FileDueNILReturns", 1, func(client *Client) error {
+			_, err := client.FileDueNILReturns(context.Background(), "P051234567A", "202401")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			started := make(chan struct{})
+			release := make(chan struct{})
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/dtd/checker/v1/obligation" {
+					writeJSON(t, w, apiResponse{
+						Success: true,
+						Data: map[string]interface{}{
+							"obligations": []map[string]interface{}{
+								{"obligationId": "1", "obligationType": "VAT", "isActive": true},
+								{"obligationId": "2", "obligationType": "PAYE", "isActive": true},
+								{"obligationId": "3", "obligationType": "MRI", "isActive": true},
+							},
+						},
+					})
+					return
+				}
+				if atomic.AddInt32(&requests, 1) == tt.inFlight {
+					close(started)
+				}
+				<-release
+				writeJSON(t, w, apiResponse{
+					Success: true,
+					Data:    map[string]interface{}{"isValid": false, "success": true, "status": "accepted"},
+				})
+			}
+
+			client, server := newClientWithServer(t, handler, WithRetry(0, time.Millisecond, time.Millisecond))
+			defer server.Close()
+
+			runDone := make(chan error, 1)
+			go func() { runDone <- tt.run(client) }()
+
+			<-started
+			closeDone := make(chan error, 1)
+			go func() { closeDone <- client.Close() }()
+
+			// Close must wait for the in-flight requests of the running batch
+			select {
+			case err := <-closeDone:
+				t.Fatalf("Close() returned before batch finished: %v", err)
+			case <-time.After(50 * time.Millisecond):
+			}
+			close(release)
+
+			if err := <-runDone; !errors.Is(err, ErrClosedDuringBatch) {
+				t.Fatalf("expected ErrClosedDuringBatch, got %v", err)
+			}
+			if err := <-closeDone; err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			// Dispatch is bounded, so only the first workers' requests were sent
+			if got := atomic.LoadInt32(&requests); got != tt.inFlight {
+				t.Fatalf("expected only the %d in-flight requests, got %d", tt.inFlight, got)
+			}
+		})
+	}
+}
+
+func TestClientBearerTokenAndRotation(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"pinStatus": "active"}})
+	}))
+	defer server.Close()
+
+	if _, err := NewClient(WithBearerToken("  ")); err == nil {
+		t.Fatal("expected error for empty bearer token")
+	}
+
+	client, err := NewClient(
+		WithBearerToken("sidecar-token-1"),
+		WithBaseURL(server.URL),
+		WithoutRateLimit(),
+		WithoutCache(),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if _, err := client.VerifyPIN(context.Background(), "P051234567A"); err != nil {
+		t.Fatalf("VerifyPIN() error = %v", err)
+	}
+	if err := client.SetBearerToken(""); err == nil {
+		t.Fatal("expected error when rotating to an empty token")
+	}
+	if err := client.SetBearerToken("sidecar-token-2"); err != nil {
+		t.Fatalf("SetBearerToken() error = %v", err)
+	}
+	if _, err := client.VerifyPIN(context.Background(), "P051234567A"); err != nil {
+		t.Fatalf("VerifyPIN() error = %v", err)
+	}
+
+	want := []string{"Bearer sidecar-token-1", "Bearer sidecar-token-2"}
+	if strings.Join(seen, ",") != strings.Join(want, ",") {
+		t.Fatalf("Authorization headers = %v, want %v", seen, want)
+	}
+}
+
+func TestClientValidateEslipExpecting(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"isValid":        payload["EslipNumber"] != "9999999999",
+				"status":         "paid",
+				"amount":         5000.0,
+				"currency":       "KES",
+				"obligationType": "VAT",
+			},
+		})
+	}
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		eslip    string
+		expected ExpectedPayment
+		fields   []string
+	}{
+		{"matches", "1234567890", ExpectedPayment{Amount: 5000, Currency: "kes", ObligationType: "vat"}, nil},
+		{"within tolerance", "1234567890", ExpectedPayment{Amount: 5000.5, Tolerance: 1}, nil},
+		{"amount", "1234567890", ExpectedPayment{Amount: 4000, Currency: "KES"}, []string{"amount"}},
+		{"currency", "1234567890", ExpectedPayment{Amount: 5000, Currency: "USD"}, []string{"currency"}},
+		{"obligation type", "1234567890", ExpectedPayment{ObligationType: "PAYE"}, []string{"obligation_type"}},
+		{"invalid slip", "9999999999", ExpectedPayment{Amount: 5000}, []string{"is_valid"}},
+		{"several", "1234567890", ExpectedPayment{Amount: 1, Currency: "USD", ObligationType: "PAYE"}, []string{"amount", "currency", "obligation_type"}},
+	}
+
+	for _, tt := range tests {
+		result, err := client.ValidateEslipExpecting(ctx, tt.eslip, tt.expected)
+		if err != nil {
+			t.Fatalf("%s: ValidateEslipExpecting() error = %v", tt.name, err)
+		}
+		match := result.PaymentMatch
+		if match == nil || match.Matches != (len(tt.fields) == 0) || len(match.Mismatches) != len(tt.fields) {
+			t.Fatalf("%s: unexpected payment match %+v", tt.name, match)
+		}
+		for i, field := range tt.fields {
+			if match.Mismatches[i].Field != field {
+				t.Fatalf("%s: mismatch %d = %v, want field %s", tt.name, i, match.Mismatches[i], field)
+			}
+		}
+	}
+
+	// The cached result must not carry a previous call's match
+	plain, err := client.ValidateEslip(ctx, "1234567890")
+	if err != nil || plain.PaymentMatch != nil {
+		t.Fatalf("expected plain validation without PaymentMatch, got %+v, %v", plain, err)
+	}
+
+	if _, err := client.ValidateEslipExpecting(ctx, "1234567890", ExpectedPayment{Amount: -1}); err == nil {
+		t.Fatal("expected error for negative expected amount")
+	}
+}
+
+func TestClientValidateEslipForPIN(t *testing.T) {
+	var payloads []map[string]string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		payloads = append(payloads, payload)
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "paid"},
+		})
+	}
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	ctx := context.Background()
+	if _, err := client.ValidateEslip(ctx, "1234567890"); err != nil {
+		t.Fatalf("ValidateEslip() error = %v", err)
+	}
+	result, err := client.ValidateEslipForPIN(ctx, "1234567890", " p051234567a ")
+	if err != nil {
+		t.Fatalf("ValidateEslipForPIN() error = %v", err)
+	}
+	if _, err := client.ValidateEslipForPIN(ctx, "1234567890", "P051234567A"); err != nil {
+		t.Fatalf("ValidateEslipForPIN() error = %v", err)
+	}
+
+	if len(payloads) != 2 {
+		t.Fatalf("expected PIN-scoped lookup to use its own cache entry, got %d requests", len(payloads))
+	}
+	if _, ok := payloads[0]["TaxpayerPIN"]; ok {
+		t.Fatalf("ValidateEslip should not send a PIN, got %v", payloads[0])
+	}
+	if payloads[1]["TaxpayerPIN"] != "P051234567A" || payloads[1]["EslipNumber"] != "1234567890" {
+		t.Fatalf("unexpected PIN-scoped payload: %v", payloads[1])
+	}
+	if result.TaxpayerPIN != "P051234567A" {
+		t.Fatalf("TaxpayerPIN = %q, want P051234567A", result.TaxpayerPIN)
+	}
+
+	if _, err := client.ValidateEslipForPIN(ctx, "1234567890", "INVALID"); err == nil {
+		t.Fatal("expected validation error for invalid PIN")
+	}
+	if _, err := client.ValidateEslipForPIN(ctx, "ABC", "P051234567A"); err == nil {
+		t.Fatal("expected validation error for invalid e-slip number")
+	}
+}
+
+func TestClientBatchRejectsOversizedInput(t *testing.T) {
+	var requests int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active"},
+		})
+	}
+	client, server := newClientWithServer(t, handler, WithoutCache(), WithMaxBatchSize(3))
+	defer server.Close()
+
+	pins := []string{"P051234567A", "P051234567B", "P051234567C", "P051234567D"}
+	ctx := context.Background()
+
+	_, err := client.VerifyPINsBatch(ctx, pins)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "batch_size" {
+		t.Fatalf("expected batch_size validation error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "chunks") {
+		t.Fatalf("expected error to suggest chunking, got %v", err)
+	}
+	if _, err := client.ValidateEslipsBatch(ctx, make([]string, 4)); !errors.As(err, &validationErr) {
+		t.Fatalf("expected validation error for oversized e-slip batch, got %v", err)
+	}
+	if atomic.LoadInt32(&requests) != 0 {
+		t.Fatalf("oversized batches should not reach the API, got %d requests", requests)
+	}
+
+	results, err := client.VerifyPINsBatch(ctx, pins[:3])
+	if err != nil {
+		t.Fatalf("VerifyPINsBatch() at the limit error = %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	if err := WithMaxBatchSize(0)(DefaultConfig()); err == nil {
+		t.Fatal("expected error for non-positive max batch size")
+	}
+}
+
+func TestClientEslipExists(t *testing.T) {
+	var requests int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var payload map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		switch payload["EslipNumber"] {
+		case "1234567890":
+			writeJSON(t, w, apiResponse{
+				Success: true,
+				Data:    map[string]interface{}{"isValid": true, "status": "paid"},
+			})
+		case "1234567891":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			writeJSON(t, w, apiResponse{Success: false, Error: &apiErrorResponse{Code: "E404", Message: "E-slip not found"}})
+		}
+	}
+	client, server := newClientWithServer(t, handler, WithRetry(0, time.Millisecond, time.Millisecond))
+	defer server.Close()
+
+	ctx := context.Background()
+	if exists, err := client.EslipExists(ctx, "1234567890"); err != nil || !exists {
+		t.Fatalf("EslipExists(existing) = %v, %v", exists, err)
+	}
+	if _, err := client.ValidateEslip(ctx, "1234567890"); err != nil {
+		t.Fatalf("ValidateEslip() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Fatalf("expected validation after existence check to hit the cache, got %d requests", got)
+	}
+
+	for _, eslip := range []string{"1234567891", "1234567892"} {
+		if exists, err := client.EslipExists(ctx, eslip); err != nil || exists {
+			t.Fatalf("EslipExists(%s) = %v, %v; want false, nil", eslip, exists, err)
+		}
+	}
+
+	if _, err := client.EslipExists(ctx, "ABC"); err == nil {
+		t.Fatal("expected validation error for malformed e-slip number")
+	}
+}
+
+func TestClientVerifyPINDeduplicatesConcurrentLookups(t *testing.T) {
+	var requests int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(100 * time.Millisecond)
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active"},
+		})
+	}
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	const callers = 20
+	start := make(chan struct{})
+	results := make([]*PINVerificationResult, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			<-start
+			results[index], errs[index] = client.VerifyPIN(context.Background(), "P051234567A")
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	for i := range results {
+		if errs[i] != nil || results[i] == nil || !results[i].IsValid {
+			t.Fatalf("caller %d: result = %+v, err = %v", i, results[i], errs[i])
+		}
+		if results[i] != results[0] {
+			t.Fatalf("caller %d received a different result instance", i)
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Fatalf("expected exactly one network call, got %d", got)
+	}
+}
+
+func TestClientVerifyPINIndividualHasNoTypeMismatch(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"kraPin":       "A012345678Z",
+				"isValid":      true,
+				"status":       "active",
+				"taxpayerType": "Individual",
+			},
+		})
+	}
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	result, err := client.VerifyPIN(context.Background(), "a012345678z")
+	if err != nil {
+		t.Fatalf("VerifyPIN() error = %v", err)
+	}
+	if !result.IsIndividual() || result.DetectedTypeFromPIN() != "individual" || result.TypeMismatch() {
+		t.Fatalf("expected a consistent individual result, got type %q detected %q mismatch %v",
+			result.TaxpayerType, result.DetectedTypeFromPIN(), result.TypeMismatch())
+	}
+}
+
+func TestClientAPIKeyOverrideIsolatesTenants(t *testing.T) {
+	var requests int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(50 * time.Millisecond)
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"isValid":      true,
+				"status":       "active",
+				"taxpayerName": strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "),
+			},
+		})
+	}
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	tenants := []string{"TENANTAKEY000001", "TENANTBKEY000002"}
+	for round := 0; round < 2; round++ {
+		var wg sync.WaitGroup
+		for _, key := range tenants {
+			wg.Add(1)
+			go func(key string) {
+				defer wg.Done()
+				result, err := client.VerifyPIN(ContextWithAPIKey(context.Background(), key), "P051234567A")
+				if err != nil || result.TaxpayerName != key {
+					t.Errorf("tenant %s: result = %+v, err = %v", key, result, err)
+				}
+			}(key)
+		}
+		wg.Wait()
+	}
+
+	// One request per tenant; the second round is served from each tenant's cache
+	if got := atomic.LoadInt32(&requests); got != int32(len(tenants)) {
+		t.Fatalf("expected %d requests, got %d", len(tenants), got)
+	}
+	for _, key := range client.cacheManager.Keys() {
+		if strings.Contains(key, tenants[0]) || strings.Contains(key, tenants[1]) {
+			t.Fatalf("cache key %q exposes an API key", key)
+		}
+	}
+}
+
+func TestClientSharedLookupSurvivesLeaderCancel(t *testing.T) {
+	var requests int32
+	arrived := make(chan struct{}, 1)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		arrived <- struct{}{}
+		time.Sleep(150 * time.Millisecond)
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active"},
+		})
+	}
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := client.VerifyPIN(leaderCtx, "P051234567A")
+		leaderErr <- err
+	}()
+	<-arrived
+
+	followerResult := make(chan *PINVerificationResult, 1)
+	followerErr := make(chan error, 1)
+	go func() {
+		result, err := client.VerifyPIN(context.Background(), "P051234567A")
+		followerResult <- result
+		followerErr <- err
+	}()
+	waitForWaiters(t, &client.inflight, client.cacheKey(context.Background(), "pin_verification", "P051234567A"), 2)
+	cancelLeader()
+
+	select {
+	case err := <-leaderErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("leader error = %v, want context.Canceled", err)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("leader did not return promptly after cancelling")
+	}
+
+	result, err := <-followerResult, <-followerErr
+	if err != nil || result == nil || !result.IsValid {
+		t.Fatalf("follower: result = %+v, err = %v", result, err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Fatalf("expected the follower to share the leader's request, got %d requests", got)
+	}
+}
+
+func TestClientSlowCacheComputeReportsSlowVerifyPIN(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active"},
+		})
+	}
+	var mu sync.Mutex
+	var slowKeys []string
+	var buf bytes.Buffer
+	client, server := newClientWithServer(t, handler,
+		WithLogger(log.New(&buf, "", 0)),
+		WithSlowCacheCompute(20*time.Millisecond, func(key string, elapsed time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			slowKeys = append(slowKeys, key)
+		}),
+	)
+	defer server.Close()
+
+	if _, err := client.VerifyPIN(context.Background(), "P051234567A"); err != nil {
+		t.Fatalf("VerifyPIN() error = %v", err)
+	}
+	// A cache hit performs no computation and must not be reported
+	if _, err := client.VerifyPIN(context.Background(), "P051234567A"); err != nil {
+		t.Fatalf("VerifyPIN() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := client.cacheKey(context.Background(), "pin_verification", "P051234567A")
+	if len(slowKeys) != 1 || slowKeys[0] != want {
+		t.Fatalf("expected one slow compute report for %q, got %v", want, slowKeys)
+	}
+	if !strings.Contains(buf.String(), "SLOW COMPUTE: "+want) {
+		t.Fatalf("expected slow compute log, got %q", buf.String())
+	}
+
+	if _, err := NewClient(WithAPIKey(testAPIKey), WithSlowCacheCompute(0, nil)); err == nil {
+		t.Fatal("expected error for non-positive threshold")
+	}
+}
+
+func TestParseObligationsResponseVariants(t *testing.T) {
+	single := map[string]interface{}{
+		"obligationDetails": map[string]interface{}{"obligationId": "OBL-1", "obligationType": "VAT", "status": "active"},
+	}
+	if got := parseObligations(single); len(got) != 1 || got[0].ObligationID != "OBL-1" || !got[0].IsActive {
+		t.Fatalf("single-object obligationDetails: got %+v", got)
+	}
+
+	nested := map[string]interface{}{
+		"responseData": map[string]interface{}{
+			"ObligationDetails": []interface{}{
+				map[string]interface{}{"obligationId": "OBL-1", "obligationType": "VAT"},
+				map[string]interface{}{"obligationId": "OBL-2", "obligationType": "PAYE"},
+			},
+		},
+	}
+	if got := parseObligations(nested); len(got) != 2 || got[1].ObligationType != "PAYE" {
+		t.Fatalf("nested ObligationDetails array: got %+v", got)
+	}
+
+	if got := parseObligations(map[string]interface{}{"obligations": "none"}); got != nil {
+		t.Fatalf("expected no obligations for a scalar value, got %+v", got)
+	}
+}
+
+func TestClientGetTaxpayerDetailsSingleObligationObject(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "obligation") {
+			writeJSON(t, w, apiResponse{
+				Success: true,
+				Data: map[string]interface{}{
+					"obligationDetails": map[string]interface{}{"obligationId": "OBL-9", "obligationType": "TOT"},
+				},
+			})
+			return
+		}
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"taxpayerName": "Jane Doe", "status": "active"},
+		})
+	}
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	details, err := client.GetTaxpayerDetails(context.Background(), "P051234567A")
+	if err != nil {
+		t.Fatalf("GetTaxpayerDetails() error = %v", err)
+	}
+	if len(details.Obligations) != 1 || details.Obligations[0].ObligationID != "OBL-9" {
+		t.Fatalf("expected the single obligation object to be parsed, got %+v", details.Obligations)
+	}
+}
+
+func TestClientDueSoonWindow(t *testing.T) {
+	dueIn := time.Now().AddDate(0, 0, 10).Format("2006-01-02")
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "obligation") {
+			writeJSON(t, w, apiResponse{
+				Success: true,
+				Data: map[string]interface{}{
+					"obligations": []interface{}{
+						map[string]interface{}{"obligationId": "OBL-1", "obligationType": "VAT", "status": "active", "nextFilingDate": dueIn},
+					},
+				},
+			})
+			return
+		}
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"status": "active"}})
+	}
+
+	wide, wideServer := newClientWithServer(t, handler, WithDueSoonWindow(14))
+	defer wideServer.Close()
+	details, err := wide.GetTaxpayerDetails(context.Background(), "P051234567A")
+	if err != nil {
+		t.Fatalf("GetTaxpayerDetails() error = %v", err)
+	}
+	if !details.Obligations[0].IsFilingDueSoonDefault() || len(details.ObligationsDueSoonDefault()) != 1 {
+		t.Fatal("expected the 14-day window to flag a filing due in 10 days")
+	}
+	if details.ComplianceLevel() != ComplianceAmber {
+		t.Fatalf("ComplianceLevel() = %s, want amber", details.ComplianceLevel())
+	}
+
+	standard, standardServer := newClientWithServer(t, handler)
+	defer standardServer.Close()
+	details, err = standard.GetTaxpayerDetails(context.Background(), "P051234567A")
+	if err != nil {
+		t.Fatalf("GetTaxpayerDetails() error = %v", err)
+	}
+	if details.Obligations[0].IsFilingDueSoonDefault() || len(details.ObligationsDueSoonDefault()) != 0 {
+		t.Fatal("expected the default 7-day window not to flag a filing due in 10 days")
+	}
+
+	if err := WithDueSoonWindow(0)(DefaultConfig()); err == nil {
+		t.Fatal("expected error for non-positive window")
+	}
+}
+
+func TestClientCancelledSaturatedBatchDoesNotLeakGoroutines(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active"},
+		})
+	}
+	// One token per hour: after the first request every worker blocks on the limiter
+	client, server := newClientWithServer(t, handler, WithoutCache(), WithRateLimit(1, time.Hour))
+	defer server.Close()
+
+	before := runtime.NumGoroutine()
+
+	pins := make([]string, 30)
+	for i := range pins {
+		pins[i] = fmt.Sprintf("P1%08dZ", i)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := client.VerifyPINsBatch(ctx, pins); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	client.httpClient.client.CloseIdleConnections()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Fatalf("goroutines leaked by cancelled batch: before=%d after=%d", before, after)
+	}
+}
+
+func TestClientDoWithRetry(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("DoWithRetry should not call the API itself")
+	}
+	client, server := newClientWithServer(t, handler, WithRetry(3, time.Millisecond, time.Millisecond))
+	defer server.Close()
+
+	calls := 0
+	err := client.DoWithRetry(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls <= 2 {
+			return NewAPIError(http.StatusServiceUnavailable, "unavailable", "/verify-pin", "")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DoWithRetry() = %v, want success on the third attempt", err)
+	}
+	if calls != 3 {
+		t.Errorf("fn called %d times, want 3", calls)
+	}
+
+	// Permanent errors are not retried
+	calls = 0
+	err = client.DoWithRetry(context.Background(), func(ctx context.Context) error {
+		calls++
+		return NewAPIError(http.StatusNotFound, "not found", "/verify-pin", "")
+	})
+	if !isNotFound(err) {
+		t.Fatalf("expected the not found error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("fn called %d times for a permanent error, want 1", calls)
+	}
+
+	// Permanent errors stay permanent when wrapped or specialized
+	permanent := map[string]func() error{
+		"wrapped 4xx": func() error {
+			return fmt.Errorf("step failed: %w", NewAPIError(http.StatusBadRequest, "bad request", "/verify-pin", ""))
+		},
+		"wrapped validation": func() error {
+			return fmt.Errorf("step failed: %w", NewValidationError("pin", "PIN number is required"))
+		},
+		"malformed PIN": func() error {
+			_, err := client.VerifyPIN(context.Background(), "bad")
+			return err
+		},
+	}
+	for name, fn := range permanent {
+		calls = 0
+		err = client.DoWithRetry(context.Background(), func(ctx context.Context) error {
+			calls++
+			return fn()
+		})
+		if err == nil || calls != 1 {
+			t.Errorf("%s: DoWithRetry() = %v after %d calls, want the error after 1", name, err, calls)
+		}
+	}
+
+	// Exhausted retries return the last error
+	calls = 0
+	err = client.DoWithRetry(context.Background(), func(ctx context.Context) error {
+		calls++
+		return NewAPIError(http.StatusBadGateway, "bad gateway", "/verify-pin", "")
+	})
+	if err == nil || calls != 4 {
+		t.Errorf("DoWithRetry() = %v after %d calls, want an error after 4", err, calls)
+	}
+}
+
+func TestClientVerifyTCCPINMismatch(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"isValid": true,
+				"status":  "active",
+				"kraPin":  "P059999999Z",
+			},
+		})
+	}
+	req := &TCCVerificationRequest{KraPIN: "P051234567A", TCCNumber: "TCC123456"}
+
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	res, err := client.VerifyTCC(context.Background(), req)
+	if err != nil {
+		t.Fatalf("VerifyTCC() error = %v", err)
+	}
+	if !res.PINMismatch() {
+		t.Errorf("PINMismatch() = false for certificate issued to %s, requested %s", res.PINNumber, res.RequestedPIN)
+	}
+
+	strict, strictServer := newClientWithServer(t, handler, WithStrictTCCPINMatch(true))
+	defer strictServer.Close()
+
+	if _, err := strict.VerifyTCC(context.Background(), req); !errors.Is(err, ErrTCCPINMismatch) {
+		t.Fatalf("expected ErrTCCPINMismatch, got %v", err)
+	}
+	// Cached mismatches are rejected as well
+	if _, err := strict.VerifyTCC(context.Background(), req); !errors.Is(err, ErrTCCPINMismatch) {
+		t.Fatalf("expected ErrTCCPINMismatch from cache, got %v", err)
+	}
+}
+
+func TestClientResultTagsFromContext(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/checker/v1/pinbypin":
+			writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"isValid": true, "status": "active"}})
+		case "/dtd/return/v1/nil":
+			writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"status": "accepted"}})
+		default:
+			t.Fatalf("unexpected endpoint: %s", r.URL.Path)
+		}
+	}
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	ctx := ContextWithTag(context.Background(), "job_id", "job-1")
+	ctx = ContextWithTag(ctx, "batch", "b7")
+
+	first, err := client.VerifyPIN(ctx, "P051234567A")
+	if err != nil {
+		t.Fatalf("VerifyPIN() error = %v", err)
+	}
+	if first.ResultTags["job_id"] != "job-1" || first.ResultTags["batch"] != "b7" {
+		t.Fatalf("ResultTags = %v, want job_id and batch tags", first.ResultTags)
+	}
+
+	// Cached results carry the tags of the call that retrieved them
+	second, err := client.VerifyPIN(ContextWithTag(context.Background(), "job_id", "job-2"), "P051234567A")
+	if err != nil {
+		t.Fatalf("VerifyPIN() error = %v", err)
+	}
+	if second.ResultTags["job_id"] != "job-2" || len(second.ResultTags) != 1 {
+		t.Errorf("cached ResultTags = %v, want only job_id=job-2", second.ResultTags)
+	}
+	if first.ResultTags["job_id"] != "job-1" {
+		t.Errorf("earlier result's tags changed to %v", first.ResultTags)
+	}
+
+	untagged, err := client.VerifyPIN(context.Background(), "P051234567A")
+	if err != nil {
+		t.Fatalf("VerifyPIN() error = %v", err)
+	}
+	if untagged.ResultTags != nil {
+		t.Errorf("untagged call got ResultTags = %v", untagged.ResultTags)
+	}
+
+	filed, err := client.FileNILReturn(ctx, &NILReturnRequest{
+		PINNumber:      "P051234567A",
+		ObligationCode: 1,
+		Month:          1,
+		Year:           2024,
+	})
+	if err != nil {
+		t.Fatalf("FileNILReturn() error = %v", err)
+	}
+	if filed.ResultTags["job_id"] != "job-1" {
+		t.Errorf("FileNILReturn ResultTags = %v, want job_id=job-1", filed.ResultTags)
+	}
+}
+
+func TestClientBatchInvalidInputPolicy(t *testing.T) {
+	var requests int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active"},
+		})
+	}
+	pins := []string{"P051234567A", "not-a-pin", "P051234567B", ""}
+	ctx := context.Background()
+
+	t.Run("skip", func(t *testing.T) {
+		atomic.StoreInt32(&requests, 0)
+		client, server := newClientWithServer(t, handler, WithoutCache())
+		defer server.Close()
+
+		results, err := client.VerifyPINsBatch(ctx, pins)
+		var inputErr *BatchInputError
+		if !errors.As(err, &inputErr) {
+			t.Fatalf("expected BatchInputError, got %v", err)
+		}
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("expected BatchInputError to unwrap to a ValidationError")
+		}
+		for i, wantInvalid := range []bool{false, true, false, true} {
+			if gotInvalid := inputErr.Errors[i] != nil; gotInvalid != wantInvalid {
+				t.Errorf("Errors[%d] = %v, want invalid=%v", i, inputErr.Errors[i], wantInvalid)
+			}
+			if gotResult := results[i] != nil; gotResult == wantInvalid {
+				t.Errorf("results[%d] = %v, want result=%v", i, results[i], !wantInvalid)
+			}
+		}
+		if got := atomic.LoadInt32(&requests); got != 2 {
+			t.Errorf("expected 2 requests for the valid PINs, got %d", got)
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		atomic.StoreInt32(&requests, 0)
+		client, server := newClientWithServer(t, handler, WithoutCache(), WithInvalidInputPolicy(PolicyFail))
+		defer server.Close()
+
+		results, err := client.VerifyPINsBatch(ctx, pins)
+		var inputErr *BatchInputError
+		if !errors.As(err, &inputErr) || results != nil {
+			t.Fatalf("expected nil results and BatchInputError, got %v, %v", results, err)
+		}
+		if inputErr.Errors[1] == nil || inputErr.Errors[0] != nil {
+			t.Errorf("Errors = %v, want only invalid slots set", inputErr.Errors)
+		}
+		if _, err := client.ValidateEslipsBatch(ctx, []string{"1234567890", "ABC"}); !errors.As(err, &inputErr) {
+			t.Errorf("expected BatchInputError for e-slips, got %v", err)
+		}
+		if got := atomic.LoadInt32(&requests); got != 0 {
+			t.Errorf("PolicyFail should not send requests, got %d", got)
+		}
+
+		if _, err := client.VerifyPINsBatch(ctx, pins[:1]); err != nil {
+			t.Errorf("VerifyPINsBatch() with valid input error = %v", err)
+		}
+	})
+
+	if err := WithInvalidInputPolicy(InvalidInputPolicy(7))(DefaultConfig()); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestClientFileNILReturnFilingSuccess(t *testing.T) {
+	var status atomic.Value
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"status": status.Load().(string), "message": "filing " + status.Load().(string)},
+		})
+	}
+	req := &NILReturnRequest{PINNumber: "P051234567A", ObligationCode: 1, Month: 1, Year: 2024}
+	ctx := context.Background()
+
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{"accepted", true},
+		{"queued", true},
+		{"processing", true},
+		{"error", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		status.Store(tt.status)
+		result, err := client.FileNILReturn(ctx, req)
+		if err != nil {
+			t.Fatalf("FileNILReturn(%q) error = %v", tt.status, err)
+		}
+		if result.Success != tt.want {
+			t.Errorf("status %q: Success = %v, want %v", tt.status, result.Success, tt.want)
+		}
+		if result.Message != "filing "+tt.status {
+			t.Errorf("status %q: Message = %q, want it preserved", tt.status, result.Message)
+		}
+	}
+
+	custom, customServer := newClientWithServer(t, handler, WithFilingSuccessPredicate(func(status string) bool {
+		return status == "accepted"
+	}))
+	defer customServer.Close()
+
+	status.Store("queued")
+	if result, err := custom.FileNILReturn(ctx, req); err != nil || result.Success {
+		t.Errorf("custom predicate: FileNILReturn() = %+v, %v, want Success=false for queued", result, err)
+	}
+}
+
+func TestClientExpiryAwareTCCCacheTTL(t *testing.T) {
+	var expiry atomic.Value
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active", "expiryDate": expiry.Load().(string)},
+		})
+	}
+	ttl := 24 * time.Hour
+	client, server := newClientWithServer(t, handler,
+		WithCustomCacheTTLs(time.Hour, ttl, time.Hour, time.Hour, time.Hour),
+		WithExpiryAwareTTL(true),
+	)
+	defer server.Close()
+
+	cachedFor := func(tcc string) time.Duration {
+		t.Helper()
+		if _, err := client.VerifyTCC(context.Background(), &TCCVerificationRequest{KraPIN: "P051234567A", TCCNumber: tcc}); err != nil {
+			t.Fatalf("VerifyTCC() error = %v", err)
+		}
+		key := client.cacheKey(context.Background(), "tcc_verification", "P051234567A_"+tcc)
+		client.cacheManager.mu.Lock()
+		defer client.cacheManager.mu.Unlock()
+		return time.Until(client.cacheManager.expiries[key])
+	}
+
+	// Expiring today: cached only until the end of the expiry day
+	expiry.Store(time.Now().UTC().Format("2006-01-02"))
+	lapse := time.Until(time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour))
+	if got := cachedFor("TCC123456"); got > lapse+time.Second {
+		t.Errorf("near-expiry TCC cached for %v, want at most %v", got, lapse)
+	}
+
+	// Far from expiry: the configured TTL applies
+	expiry.Store(time.Now().AddDate(1, 0, 0).Format("2006-01-02"))
+	if got := cachedFor("TCC123457"); got < ttl-time.Minute {
+		t.Errorf("far-expiry TCC cached for %v, want about %v", got, ttl)
+	}
+}
+
+func TestClientAbortCancelsInFlightRequests(t *testing.T) {
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}
+
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+	defer close(release)
+
+	errs := make(chan error, 3)
+	for _, pin := range []string{"P051234567A", "P051234567B", "P051234567C"} {
+		go func(pin string) {
+			_, err := client.VerifyPIN(context.Background(), pin)
+			errs <- err
+		}(pin)
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("requests did not reach the server")
+		}
+	}
+
+	start := time.Now()
+	client.Abort()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, ErrAborted) || !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected ErrAborted wrapping context.Canceled, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("request did not return promptly after Abort")
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Abort took %v to unblock requests", elapsed)
+	}
+
+	if _, err := client.VerifyPIN(context.Background(), "P051234567A"); err == nil {
+		t.Fatalf("expected error after Abort")
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("expected Close after Abort to be a no-op, got %v", err)
+	}
+}
+
+func TestClientResultPostProcessor(t *testing.T) {
+	var calls int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "taxpayerName": "Jane Doe", "status": "active"},
+		})
+	}
+
+	var operations []string
+	processor := func(ctx context.Context, operation string, result interface{}) error {
+		operations = append(operations, operation)
+		if pin, ok := result.(*PINVerificationResult); ok {
+			if pin.PINNumber == "P051234567B" {
+				return errors.New("rejected by processor")
+			}
+			pin.TaxpayerName = "[redacted]"
+		}
+		return nil
+	}
+	client, server := newClientWithServer(t, handler, WithResultPostProcessor(processor))
+	defer server.Close()
+
+	ctx := context.Background()
+	result, err := client.VerifyPIN(ctx, "P051234567A")
+	if err != nil {
+		t.Fatalf("VerifyPIN() error = %v", err)
+	}
+	if result.TaxpayerName != "[redacted]" {
+		t.Fatalf("expected processed result, got %q", result.TaxpayerName)
+	}
+
+	cached, found := client.cacheManager.Get(GenerateCacheKey("pin_verification", "P051234567A"))
+	if !found || cached.(*PINVerificationResult).TaxpayerName != "[redacted]" {
+		t.Fatalf("expected processed result in cache, got %+v", cached)
+	}
+
+	// A cache hit returns the processed result without processing it again
+	again, err := client.VerifyPIN(ctx, "P051234567A")
+	if err != nil || again.TaxpayerName != "[redacted]" {
+		t.Fatalf("VerifyPIN() from cache = %+v, %v", again, err)
+	}
+	if len(operations) != 1 || operations[0] != OperationPINVerification {
+		t.Fatalf("expected one pin_verification call, got %v", operations)
+	}
+
+	if _, err := client.VerifyPIN(ctx, "P051234567B"); err == nil || err.Error() != "rejected by processor" {
+		t.Fatalf("expected processor error, got %v", err)
+	}
+	if _, found := client.cacheManager.Get(GenerateCacheKey("pin_verification", "P051234567B")); found {
+		t.Fatal("expected rejected result not to be cached")
+	}
+
+	if _, err := client.ValidateEslip(ctx, "1234567890"); err != nil {
+		t.Fatalf("ValidateEslip() error = %v", err)
+	}
+	if operations[len(operations)-1] != OperationEslipValidation {
+		t.Fatalf("expected eslip_validation operation, got %v", operations)
+	}
+
+	if _, err := NewClient(WithAPIKey(testAPIKey), WithResultPostProcessor(nil)); err == nil {
+		t.Fatal("expected error for nil processor")
+	}
+}
+
+func TestClientDebugRequestBody(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"isValid": true, "status": "active"}})
+	}
+
+	for _, enabled := range []bool{false, true} {
+		var buf bytes.Buffer
+		client, server := newClientWithServer(t, handler,
+			WithDebug(true),
+			WithLogger(log.New(&buf, "", 0)),
+			WithDebugRequestBody(enabled),
+		)
+
+		if _, err := client.VerifyPIN(context.Background(), "P051234567A"); err != nil {
+			t.Fatalf("VerifyPIN() error = %v", err)
+		}
+		server.Close()
+
+		logged := buf.String()
+		if strings.Contains(logged, "REQUEST BODY") != enabled {
+			t.Fatalf("DebugRequestBody=%v: unexpected log %q", enabled, logged)
+		}
+		if enabled && !strings.Contains(logged, `"KRAPIN":"****567A"`) {
+			t.Fatalf("expected masked PIN in request body, got %q", logged)
+		}
+		if strings.Contains(logged, "P051234567A\"") {
+			t.Fatalf("expected PIN not to appear in a logged body, got %q", logged)
+		}
+	}
 }
@@ -0,0 +1,57 @@
+package kra
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// CacheCodec serializes cache values for backends that cannot hold live pointers
+//
+// The in-memory CacheManager stores values as-is and never uses a codec.
+// Disk-backed or remote cache backends encode results before storing them and
+// decode them into a value of the expected result type on lookup.
+type CacheCodec interface {
+	// Encode serializes a cache value
+	Encode(value interface{}) ([]byte, error)
+	// Decode deserializes data into the value pointed to by target
+	Decode(data []byte, target interface{}) error
+}
+
+// JSONCodec encodes cache values as JSON
+//
+// It is the default choice for cache backends. Numbers inside AdditionalData
+// and RawData maps decode as float64, matching how API responses are parsed in
+// the first place.
+type JSONCodec struct{}
+
+// Encode serializes the value as JSON
+func (JSONCodec) Encode(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Decode deserializes JSON data into target
+func (JSONCodec) Decode(data []byte, target interface{}) error {
+	return json.Unmarshal(data, target)
+}
+
+// GobCodec encodes cache values with encoding/gob
+//
+// Gob is more compact than JSON for repeated values, but concrete types held
+// in interface-typed fields (such as nested maps in RawData) must be
+// registered with gob.Register before they can be encoded.
+type GobCodec struct{}
+
+// Encode serializes the value with gob
+func (GobCodec) Encode(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode deserializes gob data into target
+func (GobCodec) Decode(data []byte, target interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(target)
+}
@@ -1,6 +1,10 @@
 package kra
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 )
 
@@ -8,22 +12,64 @@ import (
 type Config struct {
 	// API configuration
 	APIKey       string
+	APIKeys      []string
+	BearerToken  string
 	ClientID     string
 	ClientSecret string
 	BaseURL      string
 	TokenURL     string
 	Timeout      time.Duration
 
+	// TokenRefreshHook is called after every OAuth token refresh attempt
+	TokenRefreshHook func(err error)
+
+	// ResultPostProcessor transforms every parsed result before it is cached
+	ResultPostProcessor ResultPostProcessor
+
+	// ClockSkewWarning is the server clock skew above which a warning is logged
+	ClockSkewWarning time.Duration
+
+	// SLA is the duration a successful request may take before SLAViolationHook fires
+	SLA              time.Duration
+	SLAViolationHook func(endpoint string, actual time.Duration)
+
+	// AcceptLanguage is sent as the Accept-Language header on every request
+	AcceptLanguage string
+
+	// ContextHeaders maps context keys to request header names
+	ContextHeaders map[interface{}]string
+
+	// TransportWrapper wraps the default HTTP transport, e.g. with middleware
+	TransportWrapper func(http.RoundTripper) http.RoundTripper
+
+	// DisableKeepAlives closes connections after each request instead of pooling them
+	DisableKeepAlives bool
+
 	// Retry configuration
 	MaxRetries   int
 	InitialDelay time.Duration
 	MaxDelay     time.Duration
 
+	// EndpointRetries overrides MaxRetries for specific endpoint paths
+	EndpointRetries map[string]int
+
+	// EndpointTimeouts overrides Timeout for specific endpoint paths
+	EndpointTimeouts map[string]time.Duration
+
+	// RetryableStatusCodes are HTTP statuses retried in addition to 5xx and 429
+	RetryableStatusCodes []int
+
+	// RetryJitterSeed seeds the backoff jitter source; nil means a random seed
+	RetryJitterSeed *int64
+
 	// Rate limiting configuration
 	RateLimitEnabled bool
 	MaxRequests      int
 	RateLimitWindow  time.Duration
 
+	// RateLimiterBackend replaces the in-memory token bucket when set
+	RateLimiterBackend RateLimiterBackend
+
 	// Cache configuration
 	CacheEnabled       bool
 	PINVerificationTTL time.Duration
@@ -32,9 +78,65 @@ type Config struct {
 	TaxpayerDetailsTTL time.Duration
 	NILReturnTTL       time.Duration
 	CacheMaxEntries    int
+	CacheKeyPrefix     string
+	CacheKeyFunc       CacheKeyFunc
+
+	// SlowCacheCompute is the duration a cache-miss computation may take
+	// before it is logged and SlowCacheComputeHook fires
+	SlowCacheCompute     time.Duration
+	SlowCacheComputeHook func(key string, elapsed time.Duration)
+
+	// RawCacheTTL enables caching of idempotent Raw calls when positive
+	RawCacheTTL time.Duration
+	// RawCacheablePOSTs lists additional POST endpoints whose Raw responses may be cached
+	RawCacheablePOSTs []string
+
+	// Response handling configuration
+	StrictResponseValidation bool
+
+	// StrictTCCPINMatch makes VerifyTCC fail when the certificate belongs to another PIN
+	StrictTCCPINMatch bool
+
+	// ExpiryAwareTTL caps cached TCC results at the certificate's remaining validity
+	ExpiryAwareTTL bool
+
+	// ErrorBodyLimit caps the response body bytes kept on API errors; 0 keeps it all
+	ErrorBodyLimit int
+
+	// ResponseHistory is the number of recent responses kept for LastResponses; 0 disables it
+	ResponseHistory int
+
+	// ObligationHistoryMaxPages bounds how many pages GetObligationHistory fetches
+	ObligationHistoryMaxPages int
+
+	// MaxBatchSize caps the number of items accepted by a single batch call
+	MaxBatchSize int
+
+	// InvalidInputPolicy controls how batch calls treat malformed entries
+	InvalidInputPolicy InvalidInputPolicy
+
+	// DueSoonDays is the window used by the *DueSoonDefault helpers
+	DueSoonDays int
+
+	// NILFieldNames are the field names used in the NIL return request body
+	NILFieldNames FieldNameMap
+
+	// FilingSuccessPredicate decides filing success from the status; nil means DefaultFilingSuccess
+	FilingSuccessPredicate FilingSuccessPredicate
+
+	// DryRun validates inputs and builds payloads without calling the API
+	DryRun bool
 
 	// Debug configuration
-	DebugMode bool
+	DebugMode  bool
+	ClientName string
+	Logger     Logger
+
+	// DebugRequestBody adds PIN-masked request bodies to debug logs
+	DebugRequestBody bool
+
+	// SummaryOnClose logs a one-line usage summary when the client is closed
+	SummaryOnClose bool
 }
 
 // Option is a functional option for configuring the KRA Connect client
@@ -63,10 +165,51 @@ func DefaultConfig() *Config {
 		NILReturnTTL:       24 * time.Hour,
 		CacheMaxEntries:    1024,
 
+		ObligationHistoryMaxPages: 20,
+		MaxBatchSize:              10000,
+		DueSoonDays:               DefaultDueSoonDays,
+		NILFieldNames:             DefaultNILFieldNames(),
+		ErrorBodyLimit:            4096,
+
 		DebugMode: false,
 	}
 }
 
+// clone returns a copy of c that shares no slices or maps with it
+func (c Config) clone() *Config {
+	c.APIKeys = append([]string(nil), c.APIKeys...)
+	c.RawCacheablePOSTs = append([]string(nil), c.RawCacheablePOSTs...)
+	c.RetryableStatusCodes = append([]int(nil), c.RetryableStatusCodes...)
+
+	if c.EndpointRetries != nil {
+		retries := make(map[string]int, len(c.EndpointRetries))
+		for endpoint, n := range c.EndpointRetries {
+			retries[endpoint] = n
+		}
+		c.EndpointRetries = retries
+	}
+	if c.EndpointTimeouts != nil {
+		timeouts := make(map[string]time.Duration, len(c.EndpointTimeouts))
+		for endpoint, timeout := range c.EndpointTimeouts {
+			timeouts[endpoint] = timeout
+		}
+		c.EndpointTimeouts = timeouts
+	}
+	if c.ContextHeaders != nil {
+		headers := make(map[interface{}]string, len(c.ContextHeaders))
+		for key, name := range c.ContextHeaders {
+			headers[key] = name
+		}
+		c.ContextHeaders = headers
+	}
+	if c.RetryJitterSeed != nil {
+		seed := *c.RetryJitterSeed
+		c.RetryJitterSeed = &seed
+	}
+
+	return &c
+}
+
 // WithAPIKey sets the API key for authentication
 //
 // The API key is required and must be at least 16 characters long.
@@ -82,6 +225,40 @@ func WithAPIKey(apiKey string) Option {
 			return err
 		}
 		c.APIKey = apiKey
+		c.APIKeys = nil
+		c.BearerToken = ""
+		c.ClientID = ""
+		c.ClientSecret = ""
+		return nil
+	}
+}
+
+// WithAPIKeys sets several API keys that are used in rotation
+//
+// Requests use the keys round-robin to spread load across quota buckets. When
+// a request fails with an authentication (401/403) or rate limit (429) error,
+// it is retried immediately with the next key after the one it used, trying
+// each key at most once, before normal retry handling applies. Each key must
+// pass the same validation as WithAPIKey.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKeys(os.Getenv("KRA_API_KEY_1"), os.Getenv("KRA_API_KEY_2")),
+//	)
+func WithAPIKeys(keys ...string) Option {
+	return func(c *Config) error {
+		if len(keys) == 0 {
+			return NewValidationError("api_keys", "At least one API key is required")
+		}
+		for _, key := range keys {
+			if err := ValidateAPIKey(key); err != nil {
+				return err
+			}
+		}
+		c.APIKeys = append([]string(nil), keys...)
+		c.APIKey = keys[0]
+		c.BearerToken = ""
 		c.ClientID = ""
 		c.ClientSecret = ""
 		return nil
@@ -101,6 +278,33 @@ func WithClientCredentials(clientID, clientSecret string) Option {
 		c.ClientID = clientID
 		c.ClientSecret = clientSecret
 		c.APIKey = ""
+		c.APIKeys = nil
+		c.BearerToken = ""
+		return nil
+	}
+}
+
+// WithBearerToken authenticates with a pre-fetched bearer token
+//
+// The token is sent verbatim in the Authorization header, bypassing both the
+// API key and client-credentials flows. Use this when a sidecar or platform
+// service manages OAuth tokens; rotate the token with Client.SetBearerToken.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithBearerToken(os.Getenv("KRA_ACCESS_TOKEN")),
+//	)
+func WithBearerToken(token string) Option {
+	return func(c *Config) error {
+		if strings.TrimSpace(token) == "" {
+			return NewValidationError("bearer_token", "Bearer token is required")
+		}
+		c.BearerToken = token
+		c.APIKey = ""
+		c.APIKeys = nil
+		c.ClientID = ""
+		c.ClientSecret = ""
 		return nil
 	}
 }
@@ -136,283 +340,1266 @@ func WithTokenURL(tokenURL string) Option {
 	}
 }
 
-// WithTimeout sets the HTTP request timeout
+// WithAcceptLanguage requests localized response messages
 //
-// Default: 30 seconds
-// Valid range: 1 second to 10 minutes
+// The value is sent as the Accept-Language header on every API request,
+// for example "sw" for Swahili or "en-KE" for Kenyan English.
 //
 // Example:
 //
 //	client, err := kra.NewClient(
 //	    kra.WithAPIKey("your-api-key"),
-//	    kra.WithTimeout(60 * time.Second),
+//	    kra.WithAcceptLanguage("sw"),
 //	)
-func WithTimeout(timeout time.Duration) Option {
+func WithAcceptLanguage(lang string) Option {
 	return func(c *Config) error {
-		if err := ValidateTimeout(timeout); err != nil {
-			return err
+		lang = strings.TrimSpace(lang)
+		if lang == "" {
+			return NewValidationError("accept_language", "Accept-Language cannot be empty")
 		}
-		c.Timeout = timeout
+		c.AcceptLanguage = lang
 		return nil
 	}
 }
 
-// WithRetry configures retry behavior for failed requests
-//
-// Default: maxRetries=3, initialDelay=1s, maxDelay=32s
+// WithContextHeaderMapping forwards context values as request headers
 //
-// The retry mechanism uses exponential backoff with jitter.
+// Each entry maps a context key to a header name. When a request's context
+// carries a value for a mapped key, its string form is sent in that header;
+// absent or empty values are skipped. This lets tracing IDs, tenant IDs and
+// similar values flow to the API without a dedicated option for each one.
+// Mappings accumulate across calls.
 //
 // Example:
 //
 //	client, err := kra.NewClient(
 //	    kra.WithAPIKey("your-api-key"),
-//	    kra.WithRetry(5, 2*time.Second, 60*time.Second),
+//	    kra.WithContextHeaderMapping(map[interface{}]string{
+//	        traceIDKey{}: "X-Trace-ID",
+//	        tenantKey{}:  "X-Tenant-ID",
+//	    }),
 //	)
-func WithRetry(maxRetries int, initialDelay, maxDelay time.Duration) Option {
+func WithContextHeaderMapping(mapping map[interface{}]string) Option {
 	return func(c *Config) error {
-		if err := ValidateRetryConfig(maxRetries, initialDelay, maxDelay); err != nil {
-			return err
+		if len(mapping) == 0 {
+			return NewValidationError("context_headers", "Context header mapping cannot be empty")
 		}
-		c.MaxRetries = maxRetries
-		c.InitialDelay = initialDelay
-		c.MaxDelay = maxDelay
+		headers := make(map[interface{}]string, len(c.ContextHeaders)+len(mapping))
+		for key, header := range c.ContextHeaders {
+			headers[key] = header
+		}
+		for key, header := range mapping {
+			if key == nil {
+				return NewValidationError("context_headers", "Context key cannot be nil")
+			}
+			header = strings.TrimSpace(header)
+			if header == "" {
+				return NewValidationError("context_headers", "Header name cannot be empty")
+			}
+			headers[key] = header
+		}
+		c.ContextHeaders = headers
 		return nil
 	}
 }
 
-// WithRateLimit configures rate limiting for API requests
-//
-// Default: enabled=true, maxRequests=100, window=1 minute
+// WithTimeout sets the HTTP request timeout
 //
-// Rate limiting uses a token bucket algorithm to prevent exceeding API limits.
+// Default: 30 seconds
+// Valid range: 1 second to 10 minutes
 //
 // Example:
 //
 //	client, err := kra.NewClient(
 //	    kra.WithAPIKey("your-api-key"),
-//	    kra.WithRateLimit(200, 1*time.Minute),
+//	    kra.WithTimeout(60 * time.Second),
 //	)
-func WithRateLimit(maxRequests int, window time.Duration) Option {
+func WithTimeout(timeout time.Duration) Option {
 	return func(c *Config) error {
-		if err := ValidateRateLimitConfig(maxRequests, window); err != nil {
+		if err := ValidateTimeout(timeout); err != nil {
 			return err
 		}
-		c.RateLimitEnabled = true
-		c.MaxRequests = maxRequests
-		c.RateLimitWindow = window
+		c.Timeout = timeout
 		return nil
 	}
 }
 
-// WithoutRateLimit disables rate limiting
+// WithTokenRefreshHook registers a callback invoked after each OAuth token
+// refresh attempt
 //
-// Use this option if you have your own rate limiting mechanism
-// or if the API you're connecting to doesn't have rate limits.
+// The hook receives nil on success and the refresh error otherwise, making it
+// possible to alert on failing or unusually frequent refreshes. It is only
+// called when the client uses OAuth client credentials.
 //
 // Example:
 //
 //	client, err := kra.NewClient(
-//	    kra.WithAPIKey("your-api-key"),
-//	    kra.WithoutRateLimit(),
+//	    kra.WithClientCredentials(clientID, clientSecret),
+//	    kra.WithTokenRefreshHook(func(err error) {
+//	        if err != nil {
+//	            metrics.Inc("kra_token_refresh_failures")
+//	        }
+//	    }),
 //	)
-func WithoutRateLimit() Option {
+func WithTokenRefreshHook(hook func(err error)) Option {
 	return func(c *Config) error {
-		c.RateLimitEnabled = false
+		if hook == nil {
+			return NewValidationError("token_refresh_hook", "Token refresh hook cannot be nil")
+		}
+		c.TokenRefreshHook = hook
 		return nil
 	}
 }
 
-// WithCache enables caching with custom TTL values
+// ResultPostProcessor inspects or modifies a freshly parsed result
 //
-// Default TTLs:
-//   - PIN verification: 1 hour
-//   - TCC verification: 30 minutes
-//   - E-slip validation: 15 minutes
-//   - Taxpayer details: 2 hours
-//   - NIL return: 24 hours
+// operation is one of the Operation constants and result is the pointer the
+// client method returns: *PINVerificationResult for OperationPINVerification,
+// *TCCVerificationResult, *EslipValidationResult, *NILReturnResult or
+// *TaxpayerDetails for the others. Returning an error fails the call, and the
+// result is not cached.
+type ResultPostProcessor func(ctx context.Context, operation string, result interface{}) error
+
+// WithResultPostProcessor registers a hook that runs on every parsed result
+//
+// The processor runs after a response is parsed and before the result is
+// cached and returned, so changes it makes, such as attaching a computed
+// score or redacting fields, are seen by the caller and by later cache hits
+// alike. It runs once per KRA response: cache hits and callers sharing an
+// in-flight request get the already processed result, and ctx is the context
+// of the call that made the request. Results derived from other calls, such as
+// VerifyPINWithOptions or batches, are processed through the calls they make.
 //
 // Example:
 //
 //	client, err := kra.NewClient(
 //	    kra.WithAPIKey("your-api-key"),
-//	    kra.WithCache(true, 2*time.Hour),
+//	    kra.WithResultPostProcessor(func(ctx context.Context, operation string, result interface{}) error {
+//	        if details, ok := result.(*kra.TaxpayerDetails); ok {
+//	            details.PhoneNumber = ""
+//	        }
+//	        return nil
+//	    }),
 //	)
-func WithCache(enabled bool, defaultTTL time.Duration) Option {
+func WithResultPostProcessor(processor ResultPostProcessor) Option {
 	return func(c *Config) error {
-		if enabled && defaultTTL > 0 {
-			if err := ValidateCacheTTL(defaultTTL); err != nil {
-				return err
-			}
-		}
-		c.CacheEnabled = enabled
-		if defaultTTL > 0 {
-			c.PINVerificationTTL = defaultTTL
-			c.TCCVerificationTTL = defaultTTL
-			c.EslipValidationTTL = defaultTTL
-			c.TaxpayerDetailsTTL = defaultTTL
-			c.NILReturnTTL = defaultTTL
+		if processor == nil {
+			return NewValidationError("result_post_processor", "Result post-processor cannot be nil")
 		}
+		c.ResultPostProcessor = processor
 		return nil
 	}
 }
 
-// WithCacheCapacity sets the maximum number of entries the cache can hold before evicting
+// WithClockSkewWarning logs a warning when KRA's clock differs from the local
+// clock by more than threshold
 //
-// Default: 1024 entries
+// The skew is measured from the Date header of every response, as reported by
+// ServerTimeSkew. The warning is logged through the configured Logger once,
+// and again only after the skew has returned within the threshold and
+// exceeded it anew.
+//
+// Default: 0 (no warning)
 //
 // Example:
 //
 //	client, err := kra.NewClient(
 //	    kra.WithAPIKey("your-api-key"),
-//	    kra.WithCacheCapacity(2048),
+//	    kra.WithClockSkewWarning(2*time.Minute),
 //	)
-func WithCacheCapacity(entries int) Option {
+func WithClockSkewWarning(threshold time.Duration) Option {
 	return func(c *Config) error {
-		if entries <= 0 {
-			return NewValidationError("cache_max_entries", "Cache max entries must be positive")
+		if threshold <= 0 {
+			return NewValidationError("clock_skew_warning", "Clock skew warning threshold must be positive")
 		}
-		c.CacheMaxEntries = entries
+		c.ClockSkewWarning = threshold
 		return nil
 	}
 }
 
-// WithCustomCacheTTLs sets custom TTL values for each operation type
+// WithSLA registers a callback for successful requests slower than d
 //
-// This allows fine-grained control over cache duration for different operations.
+// Unlike a timeout, an SLA never fails a request: it is a soft alert for
+// calls that succeed but are slow. The measured duration covers everything
+// the caller waited for, including rate limit waits and retries. The callback
+// runs synchronously on the request's goroutine, so it should return quickly.
 //
 // Example:
 //
 //	client, err := kra.NewClient(
 //	    kra.WithAPIKey("your-api-key"),
-//	    kra.WithCustomCacheTTLs(
-//	        2*time.Hour,    // PIN verification
-//	        1*time.Hour,    // TCC verification
-//	        30*time.Minute, // E-slip validation
-//	        4*time.Hour,    // Taxpayer details
-//	        48*time.Hour,   // NIL return
-//	    ),
+//	    kra.WithSLA(2*time.Second, func(endpoint string, actual time.Duration) {
+//	        log.Printf("slow KRA call to %s: %v", endpoint, actual)
+//	    }),
 //	)
-func WithCustomCacheTTLs(
-	pinTTL, tccTTL, eslipTTL, taxpayerTTL, nilReturnTTL time.Duration,
-) Option {
+func WithSLA(d time.Duration, onViolation func(endpoint string, actual time.Duration)) Option {
 	return func(c *Config) error {
-		if err := ValidateCacheTTL(pinTTL); err != nil {
-			return err
-		}
-		if err := ValidateCacheTTL(tccTTL); err != nil {
-			return err
-		}
-		if err := ValidateCacheTTL(eslipTTL); err != nil {
-			return err
+		if d <= 0 {
+			return NewValidationError("sla", "SLA must be positive")
 		}
-		if err := ValidateCacheTTL(taxpayerTTL); err != nil {
-			return err
-		}
-		if err := ValidateCacheTTL(nilReturnTTL); err != nil {
-			return err
+		if onViolation == nil {
+			return NewValidationError("sla", "SLA violation callback cannot be nil")
 		}
-
-		c.PINVerificationTTL = pinTTL
-		c.TCCVerificationTTL = tccTTL
-		c.EslipValidationTTL = eslipTTL
-		c.TaxpayerDetailsTTL = taxpayerTTL
-		c.NILReturnTTL = nilReturnTTL
-
+		c.SLA = d
+		c.SLAViolationHook = onViolation
 		return nil
 	}
 }
 
-// WithoutCache disables caching
+// WithTransportWrapper wraps the SDK's HTTP transport with custom middleware
 //
-// Use this option if you want to always get fresh data from the API
-// or if you have your own caching mechanism.
+// The wrapper receives the default transport at construction and returns the
+// RoundTripper used for every request, including OAuth token requests. This
+// lets existing RoundTripper-based logging, tracing or auth libraries be
+// composed with the SDK.
 //
 // Example:
 //
 //	client, err := kra.NewClient(
 //	    kra.WithAPIKey("your-api-key"),
-//	    kra.WithoutCache(),
+//	    kra.WithTransportWrapper(func(next http.RoundTripper) http.RoundTripper {
+//	        return otelhttp.NewTransport(next)
+//	    }),
 //	)
-func WithoutCache() Option {
+func WithTransportWrapper(wrapper func(http.RoundTripper) http.RoundTripper) Option {
 	return func(c *Config) error {
-		c.CacheEnabled = false
+		if wrapper == nil {
+			return NewValidationError("transport_wrapper", "Transport wrapper cannot be nil")
+		}
+		c.TransportWrapper = wrapper
 		return nil
 	}
 }
 
-// WithDebug enables debug mode
+// WithDisableKeepAlives disables HTTP keep-alive connections
 //
-// In debug mode, the client logs detailed information about requests,
-// responses, retries, cache hits/misses, and rate limiting.
+// Short-lived tools such as CLIs make a handful of calls and exit, so pooled
+// keep-alive connections are never reused and only delay shutdown. With
+// keep-alives disabled every request uses a fresh connection that is closed
+// once the response is read. A transport wrapper that returns its own
+// transport instead of wrapping the one it is given ignores this setting.
+//
+// Default: false (keep-alives enabled)
 //
 // Example:
 //
 //	client, err := kra.NewClient(
 //	    kra.WithAPIKey("your-api-key"),
-//	    kra.WithDebug(true),
+//	    kra.WithDisableKeepAlives(true),
 //	)
-func WithDebug(enabled bool) Option {
+func WithDisableKeepAlives(disabled bool) Option {
 	return func(c *Config) error {
-		c.DebugMode = enabled
+		c.DisableKeepAlives = disabled
 		return nil
 	}
 }
 
-// Validate validates the configuration
-func (c *Config) Validate() error {
-	if c.APIKey == "" {
-		if c.ClientID == "" || c.ClientSecret == "" {
-			return NewValidationError("auth", "Provide either an API key or OAuth client credentials")
+// transport returns the RoundTripper used for SDK requests
+func (c *Config) transport() http.RoundTripper {
+	base := http.DefaultTransport
+	if c.DisableKeepAlives {
+		if t, ok := base.(*http.Transport); ok {
+			t = t.Clone()
+			t.DisableKeepAlives = true
+			base = t
 		}
-	} else {
-		if err := ValidateAPIKey(c.APIKey); err != nil {
-			return err
-		}
-	}
-
-	if c.BaseURL == "" {
-		return NewValidationError("base_url", "Base URL is required")
-	}
-
-	if c.TokenURL == "" {
-		return NewValidationError("token_url", "Token URL is required")
-	}
-
-	if err := ValidateTimeout(c.Timeout); err != nil {
-		return err
 	}
 
-	if err := ValidateRetryConfig(c.MaxRetries, c.InitialDelay, c.MaxDelay); err != nil {
-		return err
+	if c.TransportWrapper == nil {
+		return base
 	}
+	return c.TransportWrapper(base)
+}
 
-	if c.RateLimitEnabled {
-		if err := ValidateRateLimitConfig(c.MaxRequests, c.RateLimitWindow); err != nil {
+// WithRetry configures retry behavior for failed requests
+//
+// Default: maxRetries=3, initialDelay=1s, maxDelay=32s
+//
+// The retry mechanism uses exponential backoff with jitter.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithRetry(5, 2*time.Second, 60*time.Second),
+//	)
+func WithRetry(maxRetries int, initialDelay, maxDelay time.Duration) Option {
+	return func(c *Config) error {
+		if err := ValidateRetryConfig(maxRetries, initialDelay, maxDelay); err != nil {
 			return err
 		}
+		c.MaxRetries = maxRetries
+		c.InitialDelay = initialDelay
+		c.MaxDelay = maxDelay
+		return nil
 	}
+}
 
-	if c.CacheEnabled {
-		if c.CacheMaxEntries <= 0 {
-			return NewValidationError("cache_max_entries", "Cache max entries must be positive")
-		}
-		if err := ValidateCacheTTL(c.PINVerificationTTL); err != nil {
-			return err
-		}
-		if err := ValidateCacheTTL(c.TCCVerificationTTL); err != nil {
-			return err
+// WithEndpointRetries overrides the maximum number of retries for one endpoint
+//
+// The endpoint is matched exactly against the request path, e.g.
+// "/dtd/return/v1/nil". Use it to retry mutating endpoints less aggressively
+// than read-only ones; other endpoints keep the WithRetry setting. Like
+// WithRetry, maxRetries must be between 0 and 10.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithRetry(5, time.Second, 32*time.Second),
+//	    kra.WithEndpointRetries("/dtd/return/v1/nil", 0),
+//	)
+func WithEndpointRetries(endpoint string, maxRetries int) Option {
+	return func(c *Config) error {
+		if strings.TrimSpace(endpoint) == "" {
+			return NewValidationError("endpoint", "Endpoint is required")
 		}
-		if err := ValidateCacheTTL(c.EslipValidationTTL); err != nil {
-			return err
+		if maxRetries < 0 {
+			return NewValidationError("max_retries", "Max retries cannot be negative")
 		}
-		if err := ValidateCacheTTL(c.TaxpayerDetailsTTL); err != nil {
-			return err
+		if maxRetries > 10 {
+			return NewValidationError("max_retries", "Max retries cannot exceed 10")
 		}
-		if err := ValidateCacheTTL(c.NILReturnTTL); err != nil {
-			return err
+		if c.EndpointRetries == nil {
+			c.EndpointRetries = make(map[string]int)
 		}
+		c.EndpointRetries[endpoint] = maxRetries
+		return nil
 	}
+}
 
-	return nil
+// WithEndpointTimeouts overrides the request timeout for specific endpoints
+//
+// Endpoints are matched exactly against the request path, e.g.
+// "/checker/v1/pinbypin". Each attempt against a listed endpoint is limited
+// to its timeout instead of the WithTimeout setting, which still applies to
+// every other endpoint. Use it to give slow lookups more time without letting
+// fast ones hang. A per-call ContextWithAttemptTimeout takes precedence.
+// Repeated calls add to the map; each timeout is validated like WithTimeout.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithTimeout(10*time.Second),
+//	    kra.WithEndpointTimeouts(map[string]time.Duration{
+//	        "/dtd/checker/v1/taxpayer": 60 * time.Second,
+//	    }),
+//	)
+func WithEndpointTimeouts(timeouts map[string]time.Duration) Option {
+	return func(c *Config) error {
+		for endpoint, timeout := range timeouts {
+			if strings.TrimSpace(endpoint) == "" {
+				return NewValidationError("endpoint", "Endpoint is required")
+			}
+			if err := ValidateTimeout(timeout); err != nil {
+				return err
+			}
+		}
+		if c.EndpointTimeouts == nil {
+			c.EndpointTimeouts = make(map[string]time.Duration, len(timeouts))
+		}
+		for endpoint, timeout := range timeouts {
+			c.EndpointTimeouts[endpoint] = timeout
+		}
+		return nil
+	}
+}
+
+// attemptTimeoutFor returns the per-attempt timeout for an endpoint: its
+// entry in EndpointTimeouts, or Timeout if it has none
+func (c *Config) attemptTimeoutFor(endpoint string) time.Duration {
+	if timeout, ok := c.EndpointTimeouts[endpoint]; ok {
+		return timeout
+	}
+	return c.Timeout
+}
+
+// WithRetryableStatusCodes adds HTTP statuses that are retried like server errors
+//
+// By default, API errors with a 5xx or 429 status are retried and other 4xx
+// statuses fail immediately. The codes given here are retried as well, which
+// suits gateways that report transient failures with a custom status.
+// Repeated calls add to the set.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithRetryableStatusCodes(409, 425),
+//	)
+func WithRetryableStatusCodes(codes ...int) Option {
+	return func(c *Config) error {
+		if len(codes) == 0 {
+			return NewValidationError("retryable_status_codes", "At least one status code is required")
+		}
+		for _, code := range codes {
+			if code < 100 || code > 599 {
+				return NewValidationError("retryable_status_codes", fmt.Sprintf("Invalid HTTP status code: %d", code))
+			}
+		}
+		c.RetryableStatusCodes = append(c.RetryableStatusCodes, codes...)
+		return nil
+	}
+}
+
+// retryableStatus returns true if code was added with WithRetryableStatusCodes
+func (c *Config) retryableStatus(code int) bool {
+	for _, retryable := range c.RetryableStatusCodes {
+		if retryable == code {
+			return true
+		}
+	}
+	return false
+}
+
+// maxRetriesFor returns the retry limit for an endpoint
+func (c *Config) maxRetriesFor(endpoint string) int {
+	if maxRetries, ok := c.EndpointRetries[endpoint]; ok {
+		return maxRetries
+	}
+	return c.MaxRetries
+}
+
+// WithRetryJitterSeed makes retry backoff jitter deterministic
+//
+// Each client has its own jitter source. By default it is randomly seeded;
+// with a fixed seed, clients given the same retry inputs produce identical
+// backoff sequences, which makes load tests reproducible.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithRetryJitterSeed(42),
+//	)
+func WithRetryJitterSeed(seed int64) Option {
+	return func(c *Config) error {
+		c.RetryJitterSeed = &seed
+		return nil
+	}
+}
+
+// WithRateLimit configures rate limiting for API requests
+//
+// Default: enabled=true, maxRequests=100, window=1 minute
+//
+// Rate limiting uses a token bucket algorithm to prevent exceeding API limits.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithRateLimit(200, 1*time.Minute),
+//	)
+func WithRateLimit(maxRequests int, window time.Duration) Option {
+	return func(c *Config) error {
+		if err := ValidateRateLimitConfig(maxRequests, window); err != nil {
+			return err
+		}
+		c.RateLimitEnabled = true
+		c.MaxRequests = maxRequests
+		c.RateLimitWindow = window
+		return nil
+	}
+}
+
+// WithoutRateLimit disables rate limiting
+//
+// Use this option if you have your own rate limiting mechanism
+// or if the API you're connecting to doesn't have rate limits.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithoutRateLimit(),
+//	)
+func WithoutRateLimit() Option {
+	return func(c *Config) error {
+		c.RateLimitEnabled = false
+		return nil
+	}
+}
+
+// WithDistributedRateLimiter draws request tokens from a shared backend
+//
+// Use it when several processes share one KRA quota: with the default
+// in-memory limiter each process may use the full limit on its own. The
+// backend replaces the in-memory token bucket, so the WithRateLimit values
+// no longer apply. Rate limiting is enabled by this option; a later
+// WithoutRateLimit disables it again.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithDistributedRateLimiter(redisBucket),
+//	)
+func WithDistributedRateLimiter(backend RateLimiterBackend) Option {
+	return func(c *Config) error {
+		if backend == nil {
+			return NewValidationError("rate_limiter_backend", "Rate limiter backend cannot be nil")
+		}
+		c.RateLimiterBackend = backend
+		c.RateLimitEnabled = true
+		return nil
+	}
+}
+
+// RecommendedMaxEslipTTL is the longest e-slip validation cache TTL that
+// keeps payment status reasonably fresh. Longer TTLs are allowed but logged
+// as a warning when the client is created.
+const RecommendedMaxEslipTTL = 1 * time.Hour
+
+// WithCache enables caching with custom TTL values
+//
+// The TTL applies to every operation and may be CacheForever. Because e-slip
+// payment status changes quickly, a TTL above RecommendedMaxEslipTTL logs a
+// warning.
+//
+// Default TTLs:
+//   - PIN verification: 1 hour
+//   - TCC verification: 30 minutes
+//   - E-slip validation: 15 minutes
+//   - Taxpayer details: 2 hours
+//   - NIL return: 24 hours
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithCache(true, 2*time.Hour),
+//	)
+func WithCache(enabled bool, defaultTTL time.Duration) Option {
+	return func(c *Config) error {
+		if enabled && defaultTTL > 0 {
+			if err := ValidateCacheTTL(defaultTTL); err != nil {
+				return err
+			}
+		}
+		c.CacheEnabled = enabled
+		if defaultTTL > 0 {
+			c.PINVerificationTTL = defaultTTL
+			c.TCCVerificationTTL = defaultTTL
+			c.EslipValidationTTL = defaultTTL
+			c.TaxpayerDetailsTTL = defaultTTL
+			c.NILReturnTTL = defaultTTL
+		}
+		return nil
+	}
+}
+
+// WithSlowCacheCompute reports cache computations slower than threshold
+//
+// On a cache miss, the client times the upstream lookup that computes the
+// value, such as the API call made by VerifyPIN. Computations that take
+// longer than threshold are logged through the configured Logger with their
+// cache key and duration, even outside debug mode, and passed to onSlow, which
+// can feed a metrics system. onSlow may be nil to only log. It runs synchronously
+// on the computing goroutine, so it should return quickly.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithSlowCacheCompute(500*time.Millisecond, func(key string, elapsed time.Duration) {
+//	        slowCompute.Observe(elapsed.Seconds())
+//	    }),
+//	)
+func WithSlowCacheCompute(threshold time.Duration, onSlow func(key string, elapsed time.Duration)) Option {
+	return func(c *Config) error {
+		if threshold <= 0 {
+			return NewValidationError("slow_cache_compute", "Slow cache compute threshold must be positive")
+		}
+		c.SlowCacheCompute = threshold
+		c.SlowCacheComputeHook = onSlow
+		return nil
+	}
+}
+
+// WithCacheCapacity sets the maximum number of entries the cache can hold before evicting
+//
+// Default: 1024 entries
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithCacheCapacity(2048),
+//	)
+func WithCacheCapacity(entries int) Option {
+	return func(c *Config) error {
+		if entries <= 0 {
+			return NewValidationError("cache_max_entries", "Cache max entries must be positive")
+		}
+		c.CacheMaxEntries = entries
+		return nil
+	}
+}
+
+// WithErrorBodyLimit caps how much of an error response body is kept on
+// APIError
+//
+// Bodies longer than limit bytes are truncated and marked with an ellipsis in
+// both APIError.ResponseBody and Details["response_body"], keeping large error
+// pages out of logs. A limit of 0 keeps the full body.
+//
+// Default: 4096 bytes
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithErrorBodyLimit(1024),
+//	)
+func WithErrorBodyLimit(limit int) Option {
+	return func(c *Config) error {
+		if limit < 0 {
+			return NewValidationError("error_body_limit", "Error body limit cannot be negative")
+		}
+		c.ErrorBodyLimit = limit
+		return nil
+	}
+}
+
+// WithObligationHistoryMaxPages limits the number of pages fetched by
+// GetObligationHistory
+//
+// Pagination stops once this many pages have been read, even if KRA reports
+// more, in which case GetObligationHistory returns ErrHistoryTruncated with
+// the records read. This bounds the work done for taxpayers with long filing
+// histories.
+//
+// Default: 20 pages
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithObligationHistoryMaxPages(5),
+//	)
+func WithObligationHistoryMaxPages(pages int) Option {
+	return func(c *Config) error {
+		if pages <= 0 {
+			return NewValidationError("obligation_history_max_pages", "Obligation history max pages must be positive")
+		}
+		c.ObligationHistoryMaxPages = pages
+		return nil
+	}
+}
+
+// WithMaxBatchSize limits how many items a single batch call accepts
+//
+// VerifyPINsBatch, VerifyTCCsBatch, ValidateEslipsBatch, ReconcileEslips and
+// GenerateComplianceReport return a ValidationError for larger inputs instead
+// of allocating results for, and sending requests for, every item. Split
+// large inputs into chunks or use VerifyPINsChunked.
+//
+// Default: 10000 items
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithMaxBatchSize(500),
+//	)
+func WithMaxBatchSize(n int) Option {
+	return func(c *Config) error {
+		if n <= 0 {
+			return NewValidationError("max_batch_size", "Max batch size must be positive")
+		}
+		c.MaxBatchSize = n
+		return nil
+	}
+}
+
+// WithFilingSuccessPredicate overrides how NIL return filings are judged successful
+//
+// The predicate receives the lowercased filing status and is consulted only
+// when KRA's response has no explicit success flag. The result's Status and
+// Message are kept either way, so a failed filing still explains itself.
+//
+// Default: DefaultFilingSuccess (only accepted and pending statuses succeed)
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithFilingSuccessPredicate(func(status string) bool {
+//	        return status == "accepted" || status == "filed"
+//	    }),
+//	)
+func WithFilingSuccessPredicate(predicate FilingSuccessPredicate) Option {
+	return func(c *Config) error {
+		if predicate == nil {
+			return NewValidationError("filing_success_predicate", "Filing success predicate cannot be nil")
+		}
+		c.FilingSuccessPredicate = predicate
+		return nil
+	}
+}
+
+// InvalidInputPolicy controls how batch methods treat malformed entries
+type InvalidInputPolicy int
+
+// Invalid input policies for WithInvalidInputPolicy
+const (
+	// PolicySkip processes the valid entries and reports the invalid ones in a
+	// *BatchInputError
+	PolicySkip InvalidInputPolicy = iota
+	// PolicyFail rejects the whole batch with a *BatchInputError before any
+	// request is sent
+	PolicyFail
+)
+
+// WithInvalidInputPolicy sets how batch methods treat malformed entries
+//
+// VerifyPINsBatch, VerifyTCCsBatch and ValidateEslipsBatch validate every
+// entry before dispatching. Under PolicySkip, valid entries are processed and
+// invalid entries keep a nil result; if no request failed, the method returns
+// the results with a *BatchInputError whose Errors hold a ValidationError in
+// each invalid entry's slot. Under PolicyFail, a single invalid entry rejects
+// the batch with a *BatchInputError and no requests are sent.
+//
+// Default: PolicySkip
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithInvalidInputPolicy(kra.PolicyFail),
+//	)
+func WithInvalidInputPolicy(policy InvalidInputPolicy) Option {
+	return func(c *Config) error {
+		if policy != PolicySkip && policy != PolicyFail {
+			return NewValidationError("invalid_input_policy", "Invalid input policy must be PolicySkip or PolicyFail")
+		}
+		c.InvalidInputPolicy = policy
+		return nil
+	}
+}
+
+// WithDueSoonWindow sets the company-wide window for filings due soon
+//
+// Obligations and taxpayer details retrieved by the client carry the window,
+// so TaxObligation.IsFilingDueSoonDefault,
+// TaxpayerDetails.ObligationsDueSoonDefault and TaxpayerDetails.ComplianceLevel
+// apply it without repeating the number at each call site.
+//
+// Default: DefaultDueSoonDays (7 days)
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithDueSoonWindow(14),
+//	)
+func WithDueSoonWindow(days int) Option {
+	return func(c *Config) error {
+		if days <= 0 {
+			return NewValidationError("due_soon_days", "Due soon window must be positive")
+		}
+		c.DueSoonDays = days
+		return nil
+	}
+}
+
+// WithNILFieldNames overrides the field names in the NIL return request body
+//
+// Use it to follow a change in the names KRA expects, such as
+// "obligationCode" instead of "ObligationCode", without waiting for a
+// library release. Empty fields keep their current names.
+//
+// Default: DefaultNILFieldNames
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithNILFieldNames(kra.FieldNameMap{ObligationCode: "obligationCode"}),
+//	)
+func WithNILFieldNames(names FieldNameMap) Option {
+	return func(c *Config) error {
+		if names == (FieldNameMap{}) {
+			return NewValidationError("nil_field_names", "At least one NIL return field name must be set")
+		}
+		c.NILFieldNames = names.overlay(c.NILFieldNames.withDefaults())
+		return nil
+	}
+}
+
+// CacheKeyFunc builds the cache key for an operation and its parameters
+type CacheKeyFunc func(ctx context.Context, operation string, params ...string) string
+
+// WithCacheKeyFunc overrides how cache keys are built
+//
+// The function receives the request context, so keys can include dimensions
+// carried in it such as an environment or tenant tag. Any prefix configured
+// with WithCacheKeyPrefix is still applied to the returned key.
+//
+// Default: GenerateCacheKey
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithCacheKeyFunc(func(ctx context.Context, operation string, params ...string) string {
+//	        env, _ := ctx.Value(envKey).(string)
+//	        return env + ":" + kra.GenerateCacheKey(operation, params...)
+//	    }),
+//	)
+func WithCacheKeyFunc(fn CacheKeyFunc) Option {
+	return func(c *Config) error {
+		if fn == nil {
+			return NewValidationError("cache_key_func", "Cache key function cannot be nil")
+		}
+		c.CacheKeyFunc = fn
+		return nil
+	}
+}
+
+// WithRawCacheTTL caches responses of idempotent Raw calls for the given TTL
+//
+// Responses are keyed by method, endpoint and a SHA-256 hash of the request
+// body. GET and HEAD requests are cached, as are POSTs to KRA's read-only
+// checker endpoints (paths containing "/checker/"). Other POSTs may mutate
+// state and are only cached when allowed with WithRawCacheablePOST.
+//
+// Default: 0 (Raw responses are not cached)
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithRawCacheTTL(10*time.Minute),
+//	)
+func WithRawCacheTTL(ttl time.Duration) Option {
+	return func(c *Config) error {
+		if err := ValidateCacheTTL(ttl); err != nil {
+			return err
+		}
+		c.RawCacheTTL = ttl
+		return nil
+	}
+}
+
+// WithRawCacheablePOST allows Raw POST responses from the given endpoints to
+// be cached when WithRawCacheTTL is set
+//
+// Only list endpoints that are read-only despite using POST; caching a
+// mutating endpoint would suppress repeated submissions.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithRawCacheTTL(10*time.Minute),
+//	    kra.WithRawCacheablePOST("/custom/v1/lookup"),
+//	)
+func WithRawCacheablePOST(endpoints ...string) Option {
+	return func(c *Config) error {
+		for _, endpoint := range endpoints {
+			if strings.TrimSpace(endpoint) == "" {
+				return NewValidationError("endpoint", "Endpoint is required")
+			}
+		}
+		c.RawCacheablePOSTs = append(c.RawCacheablePOSTs, endpoints...)
+		return nil
+	}
+}
+
+// WithCacheKeyPrefix namespaces all cache keys generated by the client
+//
+// Use this when several clients or applications share a cache backend so that
+// their entries cannot collide. Keys take the form "prefix:operation:params".
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithCacheKeyPrefix("tenant-a"),
+//	)
+func WithCacheKeyPrefix(prefix string) Option {
+	return func(c *Config) error {
+		prefix = strings.TrimSpace(prefix)
+		if prefix == "" {
+			return NewValidationError("cache_key_prefix", "Cache key prefix cannot be empty")
+		}
+		c.CacheKeyPrefix = prefix
+		return nil
+	}
+}
+
+// WithCustomCacheTTLs sets custom TTL values for each operation type
+//
+// This allows fine-grained control over cache duration for different operations.
+// Pass CacheForever for data that never changes. An e-slip TTL above
+// RecommendedMaxEslipTTL logs a warning, since cached payment status can
+// otherwise go stale.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithCustomCacheTTLs(
+//	        2*time.Hour,    // PIN verification
+//	        1*time.Hour,    // TCC verification
+//	        30*time.Minute, // E-slip validation
+//	        4*time.Hour,    // Taxpayer details
+//	        48*time.Hour,   // NIL return
+//	    ),
+//	)
+func WithCustomCacheTTLs(
+	pinTTL, tccTTL, eslipTTL, taxpayerTTL, nilReturnTTL time.Duration,
+) Option {
+	return func(c *Config) error {
+		if err := ValidateCacheTTL(pinTTL); err != nil {
+			return err
+		}
+		if err := ValidateCacheTTL(tccTTL); err != nil {
+			return err
+		}
+		if err := ValidateCacheTTL(eslipTTL); err != nil {
+			return err
+		}
+		if err := ValidateCacheTTL(taxpayerTTL); err != nil {
+			return err
+		}
+		if err := ValidateCacheTTL(nilReturnTTL); err != nil {
+			return err
+		}
+
+		c.PINVerificationTTL = pinTTL
+		c.TCCVerificationTTL = tccTTL
+		c.EslipValidationTTL = eslipTTL
+		c.TaxpayerDetailsTTL = taxpayerTTL
+		c.NILReturnTTL = nilReturnTTL
+
+		return nil
+	}
+}
+
+// WithoutCache disables caching
+//
+// Use this option if you want to always get fresh data from the API
+// or if you have your own caching mechanism.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithoutCache(),
+//	)
+func WithoutCache() Option {
+	return func(c *Config) error {
+		c.CacheEnabled = false
+		return nil
+	}
+}
+
+// WithResponseHistory keeps summaries of the last n responses in memory
+//
+// Each record holds the endpoint, status, duration and response body of one
+// HTTP attempt, available through Client.LastResponses. This allows post-hoc
+// debugging in production without enabling debug logging. Bodies are
+// truncated to the error body limit (see WithErrorBodyLimit) and may contain
+// taxpayer data, so expose them with care.
+//
+// Default: disabled
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithResponseHistory(20),
+//	)
+func WithResponseHistory(n int) Option {
+	return func(c *Config) error {
+		if n <= 0 {
+			return NewValidationError("response_history", "Response history size must be positive")
+		}
+		c.ResponseHistory = n
+		return nil
+	}
+}
+
+// WithStrictResponseValidation rejects response envelopes without a recognizable payload
+//
+// When enabled, a successful HTTP response whose envelope has neither a data
+// payload ("responseData" or "data") nor error metadata is reported as an
+// APIError instead of producing a result with blank fields. The same applies
+// when the payload is present but is not a JSON object, such as an array or
+// a scalar; lenient clients fall back to the envelope in that case.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithStrictResponseValidation(true),
+//	)
+func WithStrictResponseValidation(enabled bool) Option {
+	return func(c *Config) error {
+		c.StrictResponseValidation = enabled
+		return nil
+	}
+}
+
+// WithStrictTCCPINMatch makes VerifyTCC reject certificates issued to another PIN
+//
+// When enabled, VerifyTCC returns an error wrapping ErrTCCPINMismatch if the
+// PIN KRA reports for the certificate differs from the requested PIN. When
+// disabled, the result is returned and callers can check PINMismatch.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithStrictTCCPINMatch(true),
+//	)
+func WithStrictTCCPINMatch(enabled bool) Option {
+	return func(c *Config) error {
+		c.StrictTCCPINMatch = enabled
+		return nil
+	}
+}
+
+// WithExpiryAwareTTL caps the cache TTL of TCC results at the certificate's
+// remaining validity
+//
+// When enabled, a TCC that lapses sooner than the configured TCC verification
+// TTL is cached only until it lapses (see TCCVerificationResult.CacheAdvisory),
+// so a certificate expiring tonight is not reported from the cache as valid
+// tomorrow morning.
+//
+// Default: false
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithExpiryAwareTTL(true),
+//	)
+func WithExpiryAwareTTL(enabled bool) Option {
+	return func(c *Config) error {
+		c.ExpiryAwareTTL = enabled
+		return nil
+	}
+}
+
+// WithDryRun enables dry-run mode
+//
+// In dry-run mode every client method performs its usual validation and
+// payload construction, then returns a synthetic result with
+// Metadata.DryRun set instead of calling the API. No network requests are
+// made, no rate-limit tokens are consumed, and nothing is cached.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithDryRun(true),
+//	)
+func WithDryRun(enabled bool) Option {
+	return func(c *Config) error {
+		c.DryRun = enabled
+		return nil
+	}
+}
+
+// WithDebug enables debug mode
+//
+// In debug mode, the client logs detailed information about requests,
+// responses, retries, cache hits/misses, and rate limiting.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithDebug(true),
+//	)
+func WithDebug(enabled bool) Option {
+	return func(c *Config) error {
+		c.DebugMode = enabled
+		return nil
+	}
+}
+
+// WithDebugRequestBody adds request bodies to the debug log
+//
+// Each request's JSON body is logged after its method and URL, with every
+// KRA PIN masked to its last four characters. Bodies can still contain other
+// sensitive data, such as taxpayer names, so enable this only while
+// diagnosing a problem. It has no effect unless WithDebug is enabled.
+//
+// Default: false
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithDebug(true),
+//	    kra.WithDebugRequestBody(true),
+//	)
+func WithDebugRequestBody(enabled bool) Option {
+	return func(c *Config) error {
+		c.DebugRequestBody = enabled
+		return nil
+	}
+}
+
+// WithLogger sets the logger that receives debug output and warnings
+//
+// Default: standard output
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithLogger(log.New(os.Stderr, "kra: ", log.LstdFlags)),
+//	)
+func WithLogger(logger Logger) Option {
+	return func(c *Config) error {
+		if logger == nil {
+			return NewValidationError("logger", "Logger cannot be nil")
+		}
+		c.Logger = logger
+		return nil
+	}
+}
+
+// WithSummaryOnClose logs a one-line usage summary when the client is closed
+//
+// The summary includes total requests, cache hit ratio, retries, rate-limit
+// waits, and errors by type. It is always logged in debug mode.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithSummaryOnClose(true),
+//	)
+func WithSummaryOnClose(enabled bool) Option {
+	return func(c *Config) error {
+		c.SummaryOnClose = enabled
+		return nil
+	}
+}
+
+// WithClientName labels the client instance
+//
+// The name is prefixed to every debug log line so that traffic from several
+// clients (for example, one per tenant) can be told apart in aggregated logs.
+//
+// Example:
+//
+//	client, err := kra.NewClient(
+//	    kra.WithAPIKey("your-api-key"),
+//	    kra.WithClientName("tenant-a"),
+//	)
+func WithClientName(name string) Option {
+	return func(c *Config) error {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return NewValidationError("client_name", "Client name cannot be empty")
+		}
+		c.ClientName = name
+		return nil
+	}
+}
+
+// logAdvisories logs warnings for settings that are valid but likely to
+// surprise, such as stale e-slip payment status
+func (c *Config) logAdvisories() {
+	if c.CacheEnabled && c.EslipValidationTTL > RecommendedMaxEslipTTL {
+		logf(c.Logger, c.ClientName, "[Config] WARN: E-slip validation cache TTL %v exceeds the recommended maximum of %v; payment status may be stale\n",
+			c.EslipValidationTTL, RecommendedMaxEslipTTL)
+	}
+}
+
+// Validate validates the configuration, returning the first problem found
+//
+// NewClient calls Validate after applying options. Call it directly to check a
+// Config assembled from files or environment variables before constructing a
+// client; use ValidateAll to report every problem at once.
+func (c *Config) Validate() error {
+	if errs := c.ValidateAll(); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// ValidateAll validates the configuration and returns every problem found
+//
+// The result is empty when the configuration is valid. Errors are returned in
+// the same order Validate checks them, so errs[0] matches Validate's result.
+//
+// Example:
+//
+//	cfg := loadConfigFromEnv()
+//	for _, err := range cfg.ValidateAll() {
+//	    log.Printf("config: %v", err)
+//	}
+func (c *Config) ValidateAll() []error {
+	var errs []error
+	add := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.APIKey == "" {
+		if c.BearerToken == "" && (c.ClientID == "" || c.ClientSecret == "") {
+			add(NewValidationError("auth", "Provide an API key, a bearer token, or OAuth client credentials"))
+		}
+	} else {
+		add(ValidateAPIKey(c.APIKey))
+		for _, key := range c.APIKeys {
+			add(ValidateAPIKey(key))
+		}
+	}
+
+	if c.BaseURL == "" {
+		add(NewValidationError("base_url", "Base URL is required"))
+	}
+
+	if c.TokenURL == "" {
+		add(NewValidationError("token_url", "Token URL is required"))
+	}
+
+	add(ValidateTimeout(c.Timeout))
+	for _, timeout := range c.EndpointTimeouts {
+		add(ValidateTimeout(timeout))
+	}
+
+	add(ValidateRetryConfig(c.MaxRetries, c.InitialDelay, c.MaxDelay))
+
+	if c.RateLimitEnabled {
+		add(ValidateRateLimitConfig(c.MaxRequests, c.RateLimitWindow))
+	}
+
+	if c.ErrorBodyLimit < 0 {
+		add(NewValidationError("error_body_limit", "Error body limit cannot be negative"))
+	}
+
+	if c.ObligationHistoryMaxPages <= 0 {
+		add(NewValidationError("obligation_history_max_pages", "Obligation history max pages must be positive"))
+	}
+
+	if c.MaxBatchSize <= 0 {
+		add(NewValidationError("max_batch_size", "Max batch size must be positive"))
+	}
+
+	if c.CacheEnabled {
+		if c.CacheMaxEntries <= 0 {
+			add(NewValidationError("cache_max_entries", "Cache max entries must be positive"))
+		}
+		add(ValidateCacheTTL(c.PINVerificationTTL))
+		add(ValidateCacheTTL(c.TCCVerificationTTL))
+		add(ValidateCacheTTL(c.EslipValidationTTL))
+		add(ValidateCacheTTL(c.TaxpayerDetailsTTL))
+		add(ValidateCacheTTL(c.NILReturnTTL))
+	}
+
+	return errs
 }
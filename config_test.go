@@ -1,6 +1,9 @@
 package kra
 
 import (
+	"bytes"
+	"errors"
+	"log"
 	"strings"
 	"testing"
 	"time"
@@ -121,6 +124,42 @@ func TestConfigValidateErrors(t *testing.T) {
 	}
 }
 
+func TestConfigValidateAllCollectsEveryError(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.BaseURL = ""
+	cfg.Timeout = 0
+	cfg.MaxRequests = 0
+	cfg.PINVerificationTTL = -time.Minute
+
+	errs := cfg.ValidateAll()
+
+	var fields []string
+	for _, err := range errs {
+		validationErr, ok := err.(*ValidationError)
+		if !ok {
+			t.Fatalf("expected *ValidationError, got %T", err)
+		}
+		fields = append(fields, validationErr.Field)
+	}
+
+	want := []string{"auth", "base_url", "timeout", "max_requests", "cache_ttl"}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Fatalf("ValidateAll() fields = %v, want %v", fields, want)
+	}
+
+	if err := cfg.Validate(); err == nil || err.Error() != errs[0].Error() {
+		t.Fatalf("Validate() = %v, want first ValidateAll error %v", err, errs[0])
+	}
+}
+
+func TestConfigValidateAllValid(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.APIKey = strings.Repeat("E", 16)
+	if errs := cfg.ValidateAll(); len(errs) != 0 {
+		t.Fatalf("ValidateAll() = %v, want no errors", errs)
+	}
+}
+
 func TestWithCustomCacheTTLsInvalid(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.APIKey = strings.Repeat("I", 16)
@@ -167,3 +206,168 @@ func TestConfigOptionValidationErrors(t *testing.T) {
 		t.Fatal("expected WithTimeout to fail for zero duration")
 	}
 }
+
+func TestExcessiveEslipTTLLogsWarning(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := NewClient(
+		WithAPIKey(strings.Repeat("A", 16)),
+		WithLogger(log.New(&buf, "", 0)),
+		WithCustomCacheTTLs(time.Hour, 30*time.Minute, 24*time.Hour, 2*time.Hour, 24*time.Hour),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "WARN: E-slip validation cache TTL 24h0m0s exceeds") {
+		t.Fatalf("expected e-slip TTL warning, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if _, err := NewClient(WithAPIKey(strings.Repeat("A", 16)), WithLogger(log.New(&buf, "", 0))); err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected no warning for default TTLs, got %q", buf.String())
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv("KRA_API_KEY", "ABCDEFGHIJKLMNOP")
+	t.Setenv("KRA_BASE_URL", "https://sandbox.example.com")
+	t.Setenv("KRA_TIMEOUT", "5s")
+	t.Setenv("KRA_RATE_LIMIT", "200")
+	t.Setenv("KRA_RATE_LIMIT_WINDOW", "30s")
+	t.Setenv("KRA_MAX_RETRIES", "1")
+	t.Setenv("KRA_CACHE_TTL", "10m")
+	t.Setenv("KRA_DEBUG", "false")
+
+	client, err := NewClientFromEnv("KRA_")
+	if err != nil {
+		t.Fatalf("NewClientFromEnv() error = %v", err)
+	}
+	defer client.Close()
+
+	cfg := client.config
+	if cfg.APIKey != "ABCDEFGHIJKLMNOP" || cfg.BaseURL != "https://sandbox.example.com" {
+		t.Fatalf("unexpected credentials/base URL: %q, %q", cfg.APIKey, cfg.BaseURL)
+	}
+	if cfg.Timeout != 5*time.Second {
+		t.Fatalf("Timeout = %v, want 5s", cfg.Timeout)
+	}
+	if !cfg.RateLimitEnabled || cfg.MaxRequests != 200 || cfg.RateLimitWindow != 30*time.Second {
+		t.Fatalf("unexpected rate limit: enabled=%v max=%d window=%v", cfg.RateLimitEnabled, cfg.MaxRequests, cfg.RateLimitWindow)
+	}
+	if cfg.MaxRetries != 1 || cfg.InitialDelay != DefaultConfig().InitialDelay {
+		t.Fatalf("unexpected retry config: max=%d initial=%v", cfg.MaxRetries, cfg.InitialDelay)
+	}
+	if cfg.PINVerificationTTL != 10*time.Minute || !cfg.CacheEnabled {
+		t.Fatalf("unexpected cache config: enabled=%v ttl=%v", cfg.CacheEnabled, cfg.PINVerificationTTL)
+	}
+
+	t.Setenv("KRA_TIMEOUT", "soon")
+	_, err = NewClientFromEnv("KRA")
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "KRA_TIMEOUT" {
+		t.Fatalf("expected KRA_TIMEOUT validation error, got %v", err)
+	}
+
+	t.Setenv("KRA_TIMEOUT", "")
+	t.Setenv("KRA_API_KEY", "")
+	if _, err := NewClientFromEnv("KRA"); err == nil {
+		t.Fatal("expected error when no credentials are set")
+	}
+}
+
+func TestClientConfigSnapshot(t *testing.T) {
+	apiKey := "SECRETKEY1234WXYZ"
+	client, err := NewClient(
+		WithAPIKey(apiKey),
+		WithRetry(4, 200*time.Millisecond, 5*time.Second),
+		WithRateLimit(50, time.Minute),
+		WithCustomCacheTTLs(2*time.Hour, time.Hour, 30*time.Minute, 4*time.Hour, 12*time.Hour),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	snap := client.Config()
+	if strings.Contains(snap.APIKey, "SECRETKEY") || snap.APIKey != "****WXYZ" {
+		t.Errorf("APIKey = %q, want it masked to ****WXYZ", snap.APIKey)
+	}
+	if snap.MaxRetries != 4 || snap.InitialDelay != 200*time.Millisecond || snap.MaxDelay != 5*time.Second {
+		t.Errorf("retry policy = %d/%v/%v, want 4/200ms/5s", snap.MaxRetries, snap.InitialDelay, snap.MaxDelay)
+	}
+	if !snap.RateLimitEnabled || snap.MaxRequests != 50 || snap.RateLimitWindow != time.Minute {
+		t.Errorf("rate limit = %v %d/%v, want enabled 50/1m", snap.RateLimitEnabled, snap.MaxRequests, snap.RateLimitWindow)
+	}
+
+	wantTTLs := map[string]time.Duration{
+		"VerifyPIN":          2 * time.Hour,
+		"VerifyTCC":          time.Hour,
+		"ValidateEslip":      30 * time.Minute,
+		"GetTaxpayerDetails": 4 * time.Hour,
+		"FileNILReturn":      12 * time.Hour,
+	}
+	for op, want := range wantTTLs {
+		if got := snap.CacheTTLs[op]; got != want {
+			t.Errorf("CacheTTLs[%s] = %v, want %v", op, got, want)
+		}
+	}
+
+	// The snapshot is a copy
+	snap.CacheTTLs["VerifyPIN"] = 0
+	if client.Config().CacheTTLs["VerifyPIN"] != 2*time.Hour {
+		t.Error("modifying the snapshot changed the client configuration")
+	}
+}
+
+func TestNewClientFromConfig(t *testing.T) {
+	cfg := *DefaultConfig()
+	cfg.APIKey = strings.Repeat("K", 16)
+	cfg.MaxRetries = 5
+	cfg.EndpointRetries = map[string]int{"/dtd/return/v1/nil": 1}
+
+	client, err := NewClientFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewClientFromConfig() error = %v", err)
+	}
+	defer client.Close()
+
+	if got := client.Config().MaxRetries; got != 5 {
+		t.Errorf("MaxRetries = %d, want 5", got)
+	}
+
+	// The client keeps its own copy
+	cfg.EndpointRetries["/dtd/return/v1/nil"] = 9
+	cfg.MaxRetries = 0
+	if client.config.EndpointRetries["/dtd/return/v1/nil"] != 1 || client.config.MaxRetries != 5 {
+		t.Error("modifying the source config changed the client")
+	}
+
+	invalid := []struct {
+		name  string
+		cfg   Config
+		field string
+	}{
+		{"zero config", Config{}, "auth"},
+		{"short api key", func() Config { c := *DefaultConfig(); c.APIKey = "short"; return c }(), "api_key"},
+		{"missing base url", func() Config {
+			c := *DefaultConfig()
+			c.APIKey = strings.Repeat("K", 16)
+			c.BaseURL = ""
+			return c
+		}(), "base_url"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClientFromConfig(tt.cfg)
+			var vErr *ValidationError
+			if !errors.As(err, &vErr) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if vErr.Field != tt.field {
+				t.Errorf("Field = %q, want %q", vErr.Field, tt.field)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package kra
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// contextKey is the type for context keys defined by the SDK
+type contextKey int
+
+const (
+	attemptTimeoutKey contextKey = iota
+	apiKeyKey
+	resultTagsKey
+)
+
+// ContextWithAttemptTimeout returns a context that limits each HTTP attempt
+// made with it to the given duration
+//
+// The timeout applies per attempt, so retries each get a fresh budget, and it
+// takes precedence over the client-wide timeout for calls made with this
+// context. This lets a single client serve both fast lookups and slow calls.
+//
+// Example:
+//
+//	ctx := kra.ContextWithAttemptTimeout(context.Background(), 2*time.Second)
+//	result, err := client.VerifyPIN(ctx, "P051234567A")
+func ContextWithAttemptTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, attemptTimeoutKey, timeout)
+}
+
+// ContextWithAPIKey returns a context whose requests authenticate with the
+// given API key instead of the client's configured credentials
+//
+// This lets one client serve many tenants, each with its own KRA key. The key
+// must pass the same validation as WithAPIKey; an invalid key fails the
+// request with a ValidationError. API key failover does not apply to
+// overridden keys.
+//
+// Cache keys of calls made with an overridden key carry a fingerprint of it,
+// so tenants never share cached results or in-flight requests, and the key
+// itself never appears in cache keys.
+//
+// Example:
+//
+//	ctx := kra.ContextWithAPIKey(r.Context(), tenant.KRAKey)
+//	result, err := client.VerifyPIN(ctx, "P051234567A")
+func ContextWithAPIKey(ctx context.Context, apiKey string) context.Context {
+	return context.WithValue(ctx, apiKeyKey, apiKey)
+}
+
+// apiKeyFingerprint returns a short, non-reversible identifier for an API key
+func apiKeyFingerprint(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+// apiKeyFromContext returns the API key override stored in the context, if any
+func apiKeyFromContext(ctx context.Context) (string, bool) {
+	apiKey, ok := ctx.Value(apiKeyKey).(string)
+	return apiKey, ok
+}
+
+// ContextWithTag returns a context that attaches a caller-defined tag to the
+// results of calls made with it
+//
+// Tags are never sent to KRA; they are copied onto the ResultTags field of
+// each result so callers can correlate results with their own records, such
+// as a job ID, in concurrent pipelines. Tags accumulate across calls, and a
+// repeated key replaces the earlier value.
+//
+// Example:
+//
+//	ctx := kra.ContextWithTag(ctx, "job_id", job.ID)
+//	result, err := client.VerifyPIN(ctx, job.PIN)
+//	if err == nil {
+//	    log.Printf("job %s verified", result.ResultTags["job_id"])
+//	}
+func ContextWithTag(ctx context.Context, key, value string) context.Context {
+	parent, _ := ctx.Value(resultTagsKey).(map[string]string)
+	tags := make(map[string]string, len(parent)+1)
+	for k, v := range parent {
+		tags[k] = v
+	}
+	tags[key] = value
+	return context.WithValue(ctx, resultTagsKey, tags)
+}
+
+// resultTagsFromContext returns a copy of the tags stored in the context, or nil if none
+func resultTagsFromContext(ctx context.Context) map[string]string {
+	stored, _ := ctx.Value(resultTagsKey).(map[string]string)
+	if len(stored) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(stored))
+	for k, v := range stored {
+		tags[k] = v
+	}
+	return tags
+}
+
+// contextHeaderValue returns the string form of a context value, or "" if unset
+func contextHeaderValue(ctx context.Context, key interface{}) string {
+	switch value := ctx.Value(key).(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case fmt.Stringer:
+		return value.String()
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// attemptTimeoutFromContext returns the per-attempt timeout stored in the context, if any
+func attemptTimeoutFromContext(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(attemptTimeoutKey).(time.Duration)
+	if !ok || timeout <= 0 {
+		return 0, false
+	}
+	return timeout, true
+}
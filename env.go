@@ -0,0 +1,150 @@
+package kra
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewClientFromEnv creates a client configured from environment variables
+//
+// Variables are read with the given prefix, so a prefix of "KRA" reads
+// KRA_API_KEY, KRA_BASE_URL and so on; see OptionsFromEnv for the full list.
+// Additional options are applied after the environment, so they take
+// precedence.
+//
+// Example:
+//
+//	// KRA_API_KEY=... KRA_TIMEOUT=10s KRA_RATE_LIMIT=200 ./service
+//	client, err := kra.NewClientFromEnv("KRA")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer client.Close()
+func NewClientFromEnv(prefix string, opts ...Option) (*Client, error) {
+	envOpts, err := OptionsFromEnv(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(append(envOpts, opts...)...)
+}
+
+// OptionsFromEnv builds client options from environment variables
+//
+// Unset or empty variables are skipped and keep their defaults. Recognized
+// variables, shown without the "PREFIX_" part:
+//   - API_KEY: API key (WithAPIKey)
+//   - CLIENT_ID, CLIENT_SECRET: OAuth client credentials (WithClientCredentials)
+//   - BASE_URL, TOKEN_URL: API and token endpoints
+//   - TIMEOUT: request timeout as a duration, e.g. "30s"
+//   - RATE_LIMIT: requests allowed per window; "0" disables rate limiting
+//   - RATE_LIMIT_WINDOW: rate limit window as a duration
+//   - MAX_RETRIES: maximum retries, keeping the default backoff delays
+//   - CACHE_TTL: cache TTL for every operation as a duration; "0" disables caching
+//   - DEBUG: enables debug logging when true
+//
+// Malformed values are reported as a ValidationError naming the variable.
+func OptionsFromEnv(prefix string) ([]Option, error) {
+	prefix = strings.TrimSuffix(strings.TrimSpace(prefix), "_")
+	name := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "_" + key
+	}
+	get := func(key string) string {
+		return strings.TrimSpace(os.Getenv(name(key)))
+	}
+
+	var opts []Option
+
+	if apiKey := get("API_KEY"); apiKey != "" {
+		opts = append(opts, WithAPIKey(apiKey))
+	}
+	if clientID, secret := get("CLIENT_ID"), get("CLIENT_SECRET"); clientID != "" || secret != "" {
+		opts = append(opts, WithClientCredentials(clientID, secret))
+	}
+	if baseURL := get("BASE_URL"); baseURL != "" {
+		opts = append(opts, WithBaseURL(baseURL))
+	}
+	if tokenURL := get("TOKEN_URL"); tokenURL != "" {
+		opts = append(opts, WithTokenURL(tokenURL))
+	}
+
+	if value := get("TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, envValueError(name("TIMEOUT"), value, "a duration such as 30s")
+		}
+		opts = append(opts, WithTimeout(timeout))
+	}
+
+	window := time.Duration(0)
+	if value := get("RATE_LIMIT_WINDOW"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, envValueError(name("RATE_LIMIT_WINDOW"), value, "a duration such as 1m")
+		}
+		window = parsed
+	}
+	if value := get("RATE_LIMIT"); value != "" {
+		maxRequests, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, envValueError(name("RATE_LIMIT"), value, "an integer")
+		}
+		if maxRequests == 0 {
+			opts = append(opts, WithoutRateLimit())
+		} else {
+			opts = append(opts, func(c *Config) error {
+				w := window
+				if w == 0 {
+					w = c.RateLimitWindow
+				}
+				return WithRateLimit(maxRequests, w)(c)
+			})
+		}
+	} else if window > 0 {
+		opts = append(opts, func(c *Config) error {
+			return WithRateLimit(c.MaxRequests, window)(c)
+		})
+	}
+
+	if value := get("MAX_RETRIES"); value != "" {
+		maxRetries, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, envValueError(name("MAX_RETRIES"), value, "an integer")
+		}
+		opts = append(opts, func(c *Config) error {
+			return WithRetry(maxRetries, c.InitialDelay, c.MaxDelay)(c)
+		})
+	}
+
+	if value := get("CACHE_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, envValueError(name("CACHE_TTL"), value, "a duration such as 1h")
+		}
+		if ttl == 0 {
+			opts = append(opts, WithoutCache())
+		} else {
+			opts = append(opts, WithCache(true, ttl))
+		}
+	}
+
+	if value := get("DEBUG"); value != "" {
+		debug, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, envValueError(name("DEBUG"), value, "a boolean")
+		}
+		opts = append(opts, WithDebug(debug))
+	}
+
+	return opts, nil
+}
+
+// envValueError reports a malformed environment variable
+func envValueError(variable, value, want string) error {
+	return NewValidationError(variable, fmt.Sprintf("%s=%q must be %s", variable, value, want))
+}
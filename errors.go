@@ -1,8 +1,10 @@
 package kra
 
 import (
+	"errors"
 	"fmt"
 	"time"
+	"unicode/utf8"
 )
 
 // Error types for the KRA Connect SDK
@@ -13,6 +15,11 @@ type SDKError struct {
 	Details    map[string]interface{}
 	StatusCode int
 	Err        error
+
+	// Code is the KRA error code reported with the failure, if any
+	Code string
+
+	kind ErrorKind
 }
 
 func (e *SDKError) Error() string {
@@ -26,6 +33,100 @@ func (e *SDKError) Unwrap() error {
 	return e.Err
 }
 
+// Kind returns the category of the error
+//
+// Errors built with the SDK's constructors always report a specific kind;
+// an SDKError constructed directly reports KindUnknown.
+func (e *SDKError) Kind() ErrorKind {
+	return e.kind
+}
+
+// KRACode returns the KRA error code reported with the failure, or an empty
+// string if the API did not provide one
+func (e *SDKError) KRACode() string {
+	return e.Code
+}
+
+// setKRACode records the KRA error code on the error
+func (e *SDKError) setKRACode(code string) {
+	e.Code = code
+}
+
+// Coded is implemented by errors that carry a KRA error code
+//
+// Every SDK error type implements it through the embedded SDKError.
+type Coded interface {
+	KRACode() string
+}
+
+// KRACode returns the KRA error code carried by err or any error it wraps
+//
+// It returns an empty string when no error in the chain carries a code.
+//
+// Example:
+//
+//	if code := kra.KRACode(err); code != "" {
+//	    metrics.Inc("kra_error", code)
+//	}
+func KRACode(err error) string {
+	var coded Coded
+	if errors.As(err, &coded) {
+		return coded.KRACode()
+	}
+	return ""
+}
+
+// withKRACode records code on err if err is an SDK error and code is known
+func withKRACode(err error, code string) error {
+	if code == "" {
+		return err
+	}
+	if setter, ok := err.(interface{ setKRACode(string) }); ok {
+		setter.setKRACode(code)
+	}
+	return err
+}
+
+// ErrorKind is a stable category label for SDK errors, suitable for metrics
+// and structured logging
+type ErrorKind int
+
+// Error kinds reported by SDKError.Kind
+const (
+	KindUnknown ErrorKind = iota
+	KindValidation
+	KindAuth
+	KindRateLimit
+	KindTimeout
+	KindNetwork
+	KindAPI
+	KindCache
+)
+
+var errorKindNames = map[ErrorKind]string{
+	KindUnknown:    "unknown",
+	KindValidation: "validation",
+	KindAuth:       "authentication",
+	KindRateLimit:  "rate_limit",
+	KindTimeout:    "timeout",
+	KindNetwork:    "network",
+	KindAPI:        "api",
+	KindCache:      "cache",
+}
+
+// String returns the short label for the kind, e.g. "rate_limit"
+func (k ErrorKind) String() string {
+	if name, ok := errorKindNames[k]; ok {
+		return name
+	}
+	return errorKindNames[KindUnknown]
+}
+
+// MarshalText encodes the kind as its String label
+func (k ErrorKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
 // ValidationError represents input validation errors
 type ValidationError struct {
 	SDKError
@@ -40,6 +141,7 @@ func NewValidationError(field, message string) *ValidationError {
 			Details: map[string]interface{}{
 				"field": field,
 			},
+			kind: KindValidation,
 		},
 		Field: field,
 	}
@@ -55,7 +157,7 @@ type InvalidPINFormatError struct {
 func NewInvalidPINFormatError(pin string) *InvalidPINFormatError {
 	base := NewValidationError(
 		"pin",
-		fmt.Sprintf("Invalid PIN format: '%s'. Expected format: P followed by 9 digits and a letter (e.g., P051234567A)", pin),
+		fmt.Sprintf("Invalid PIN format: '%s'. Expected format: A or P followed by 9 digits and a letter (e.g., P051234567A)", pin),
 	)
 
 	return &InvalidPINFormatError{
@@ -94,6 +196,7 @@ func NewAuthenticationError(message string) *AuthenticationError {
 		SDKError: SDKError{
 			Message:    message,
 			StatusCode: 401,
+			kind:       KindAuth,
 		},
 	}
 }
@@ -122,6 +225,7 @@ func NewRateLimitError(retryAfter time.Duration, limit int, window time.Duration
 				"limit":       limit,
 				"window":      window,
 			},
+			kind: KindRateLimit,
 		},
 		RetryAfter: retryAfter,
 		Limit:      limit,
@@ -148,6 +252,7 @@ func NewTimeoutError(endpoint string, timeout time.Duration, attemptNumber int)
 				"timeout":        timeout,
 				"attempt_number": attemptNumber,
 			},
+			kind: KindTimeout,
 		},
 		Endpoint:      endpoint,
 		Timeout:       timeout,
@@ -172,12 +277,37 @@ func NewAPIError(statusCode int, message, endpoint, responseBody string) *APIErr
 				"endpoint":      endpoint,
 				"response_body": responseBody,
 			},
+			kind: KindAPI,
 		},
 		Endpoint:     endpoint,
 		ResponseBody: responseBody,
 	}
 }
 
+// truncateBody limits the stored response body to limit bytes
+func (e *APIError) truncateBody(limit int) {
+	e.ResponseBody = truncateErrorBody(e.ResponseBody, limit)
+	if e.Details != nil {
+		e.Details["response_body"] = e.ResponseBody
+	}
+}
+
+// truncateErrorBody shortens body to at most limit bytes, appending an
+// ellipsis marker when anything was removed. A limit of 0 disables truncation.
+func truncateErrorBody(body string, limit int) string {
+	if limit <= 0 || len(body) <= limit {
+		return body
+	}
+
+	// Avoid splitting a multi-byte character
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+
+	return fmt.Sprintf("%s... (truncated %d bytes)", body[:cut], len(body)-cut)
+}
+
 // IsServerError returns true if the error is a server error (5xx)
 func (e *APIError) IsServerError() bool {
 	return e.StatusCode >= 500 && e.StatusCode < 600
@@ -188,6 +318,44 @@ func (e *APIError) IsClientError() bool {
 	return e.StatusCode >= 400 && e.StatusCode < 500
 }
 
+// BatchInputError reports the malformed entries of a batch call
+//
+// Errors is aligned with the batch input: it holds the validation error for
+// each invalid entry and nil for valid ones. Under PolicySkip the valid
+// entries were still processed; under PolicyFail nothing was sent.
+type BatchInputError struct {
+	Errors []error
+}
+
+func (e *BatchInputError) Error() string {
+	invalid, first := 0, -1
+	for i, err := range e.Errors {
+		if err != nil {
+			invalid++
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+	if first < 0 {
+		return "batch contains no invalid inputs"
+	}
+	return fmt.Sprintf("%d of %d batch inputs are invalid; first at index %d: %v",
+		invalid, len(e.Errors), first, e.Errors[first])
+}
+
+// Unwrap returns the validation errors of the invalid entries, so errors.As
+// finds the first *ValidationError
+func (e *BatchInputError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errors {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
 // NetworkError represents network-related errors
 type NetworkError struct {
 	SDKError
@@ -203,6 +371,7 @@ func NewNetworkError(endpoint string, err error) *NetworkError {
 			Details: map[string]interface{}{
 				"endpoint": endpoint,
 			},
+			kind: KindNetwork,
 		},
 		Endpoint: endpoint,
 	}
@@ -225,6 +394,7 @@ func NewCacheError(operation, key, reason string) *CacheError {
 				"key":       key,
 				"reason":    reason,
 			},
+			kind: KindCache,
 		},
 		Operation: operation,
 		Key:       key,
@@ -2,6 +2,7 @@ package kra
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -49,3 +50,65 @@ func TestTimeoutAndNetworkErrors(t *testing.T) {
 		t.Fatalf("unexpected cache error message: %s", cacheErr.Message)
 	}
 }
+
+func TestErrorKinds(t *testing.T) {
+	tests := []struct {
+		err  interface{ Kind() ErrorKind }
+		want ErrorKind
+		name string
+	}{
+		{NewValidationError("pin", "bad"), KindValidation, "validation"},
+		{NewInvalidPINFormatError("X"), KindValidation, "validation"},
+		{NewInvalidTCCFormatError("X"), KindValidation, "validation"},
+		{NewAuthenticationError("denied"), KindAuth, "authentication"},
+		{NewRateLimitError(time.Second, 10, time.Minute), KindRateLimit, "rate_limit"},
+		{NewTimeoutError("/verify", time.Second, 1), KindTimeout, "timeout"},
+		{NewNetworkError("/verify", errors.New("refused")), KindNetwork, "network"},
+		{NewAPIError(500, "boom", "/verify", ""), KindAPI, "api"},
+		{NewCacheError("get", "key", "boom"), KindCache, "cache"},
+		{&SDKError{Message: "raw"}, KindUnknown, "unknown"},
+	}
+
+	for _, tt := range tests {
+		kind := tt.err.Kind()
+		if kind != tt.want {
+			t.Errorf("%T.Kind() = %v, want %v", tt.err, kind, tt.want)
+		}
+		if kind.String() != tt.name {
+			t.Errorf("%v.String() = %q, want %q", kind, kind.String(), tt.name)
+		}
+		text, err := kind.MarshalText()
+		if err != nil || string(text) != tt.name {
+			t.Errorf("%v.MarshalText() = %q, %v", kind, text, err)
+		}
+	}
+}
+
+func TestKRACode(t *testing.T) {
+	h := &HTTPClient{config: DefaultConfig()}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"api error", withKRACode(NewAPIError(500, "failed", "/x", ""), "E500"), "E500"},
+		{"wrapped api error", fmt.Errorf("filing: %w", withKRACode(NewAPIError(400, "bad", "/x", ""), "E400")), "E400"},
+		{"authentication error from response", h.handleErrorResponse(401, []byte(`{"errorCode":"AUTH01"}`), "/x", 1, time.Second), "AUTH01"},
+		{"rate limit error from response", h.handleErrorResponse(429, []byte(`{"error":{"code":"RL01"}}`), "/x", 1, time.Second), "RL01"},
+		{"validation error without code", NewValidationError("pin", "bad"), ""},
+		{"non-SDK error", errors.New("boom"), ""},
+		{"nil", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KRACode(tt.err); got != tt.want {
+				t.Errorf("KRACode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var _ Coded = NewTimeoutError("/x", time.Second, 1)
+	var _ Coded = NewNetworkError("/x", errors.New("down"))
+}
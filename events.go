@@ -0,0 +1,89 @@
+package kra
+
+import "time"
+
+// EventSchemaVersion is the version of the VerificationEvent schema. It is
+// incremented whenever fields are added, removed, or change meaning.
+const EventSchemaVersion = 1
+
+// Operation names used in VerificationEvent.Operation and passed to a
+// ResultPostProcessor
+const (
+	OperationPINVerification = "pin_verification"
+	OperationTCCVerification = "tcc_verification"
+	OperationEslipValidation = "eslip_validation"
+	OperationNILReturn       = "nil_return"
+	OperationTaxpayerDetails = "taxpayer_details"
+)
+
+// VerificationEvent is a uniform envelope for publishing results to a
+// message bus
+//
+// Subject identifies what was checked: the PIN for PIN verifications and
+// NIL returns, the TCC number for TCC verifications, and the e-slip number
+// for e-slip validations. Payload holds the originating result, e.g. a
+// *PINVerificationResult, so consumers can switch on Operation and assert
+// the concrete type.
+type VerificationEvent struct {
+	SchemaVersion int         `json:"schema_version"`
+	Operation     string      `json:"operation"`
+	Subject       string      `json:"subject"`
+	Valid         bool        `json:"valid"`
+	Timestamp     time.Time   `json:"timestamp"`
+	Payload       interface{} `json:"payload"`
+}
+
+// ToEvent converts the result into a VerificationEvent
+//
+// Example:
+//
+//	event := result.ToEvent()
+//	msg, _ := json.Marshal(event)
+//	producer.Publish("kra.verifications", msg)
+func (r *PINVerificationResult) ToEvent() VerificationEvent {
+	return VerificationEvent{
+		SchemaVersion: EventSchemaVersion,
+		Operation:     OperationPINVerification,
+		Subject:       r.PINNumber,
+		Valid:         r.IsValid,
+		Timestamp:     r.VerifiedAt,
+		Payload:       r,
+	}
+}
+
+// ToEvent converts the result into a VerificationEvent
+func (r *TCCVerificationResult) ToEvent() VerificationEvent {
+	return VerificationEvent{
+		SchemaVersion: EventSchemaVersion,
+		Operation:     OperationTCCVerification,
+		Subject:       r.TCCNumber,
+		Valid:         r.IsValid,
+		Timestamp:     r.VerifiedAt,
+		Payload:       r,
+	}
+}
+
+// ToEvent converts the result into a VerificationEvent
+func (r *EslipValidationResult) ToEvent() VerificationEvent {
+	return VerificationEvent{
+		SchemaVersion: EventSchemaVersion,
+		Operation:     OperationEslipValidation,
+		Subject:       r.EslipNumber,
+		Valid:         r.IsValid,
+		Timestamp:     r.ValidatedAt,
+		Payload:       r,
+	}
+}
+
+// ToEvent converts the result into a VerificationEvent; Valid reports
+// whether the filing succeeded
+func (r *NILReturnResult) ToEvent() VerificationEvent {
+	return VerificationEvent{
+		SchemaVersion: EventSchemaVersion,
+		Operation:     OperationNILReturn,
+		Subject:       r.PINNumber,
+		Valid:         r.Success,
+		Timestamp:     r.FiledAt,
+		Payload:       r,
+	}
+}
@@ -0,0 +1,60 @@
+package kra
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestResultsToEvent(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	pin := &PINVerificationResult{PINNumber: "P051234567A", IsValid: true, VerifiedAt: now}
+	tcc := &TCCVerificationResult{TCCNumber: "TCC123456", IsValid: false, VerifiedAt: now}
+	eslip := &EslipValidationResult{EslipNumber: "1234567890", IsValid: true, ValidatedAt: now}
+	nilReturn := &NILReturnResult{PINNumber: "P051234567A", Success: true, FiledAt: now}
+
+	tests := []struct {
+		event     VerificationEvent
+		operation string
+		subject   string
+		valid     bool
+		payload   interface{}
+	}{
+		{pin.ToEvent(), OperationPINVerification, "P051234567A", true, pin},
+		{tcc.ToEvent(), OperationTCCVerification, "TCC123456", false, tcc},
+		{eslip.ToEvent(), OperationEslipValidation, "1234567890", true, eslip},
+		{nilReturn.ToEvent(), OperationNILReturn, "P051234567A", true, nilReturn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operation, func(t *testing.T) {
+			event := tt.event
+			if event.SchemaVersion != EventSchemaVersion || event.Operation != tt.operation ||
+				event.Subject != tt.subject || event.Valid != tt.valid || !event.Timestamp.Equal(now) {
+				t.Fatalf("unexpected event: %+v", event)
+			}
+			if event.Payload != tt.payload {
+				t.Fatalf("expected payload to be the originating result, got %T", event.Payload)
+			}
+		})
+	}
+}
+
+func TestVerificationEventJSON(t *testing.T) {
+	event := (&PINVerificationResult{PINNumber: "P051234567A", IsValid: true}).ToEvent()
+
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	payload, ok := decoded["payload"].(map[string]interface{})
+	if decoded["operation"] != OperationPINVerification || !ok || payload["pin_number"] != "P051234567A" {
+		t.Fatalf("unexpected event JSON: %s", encoded)
+	}
+}
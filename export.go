@@ -0,0 +1,147 @@
+package kra
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// ObligationExportRecord is a single JSON line written by ExportObligations
+type ObligationExportRecord struct {
+	PINNumber   string          `json:"pin_number"`
+	Obligations []TaxObligation `json:"obligations,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// ExportStats summarizes an ExportObligations run
+type ExportStats struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+
+	// Errors maps each failed PIN to its error message
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// ExportObligations fetches the obligations of every PIN and streams them to
+// w as JSON lines, one ObligationExportRecord per PIN
+//
+// Records are written as results arrive, so output order may differ from the
+// input order and memory use does not grow with the number of PINs. At most
+// 10 PINs are fetched at once, and every request goes through the client's
+// rate limiter. A PIN that fails is still written, with its error recorded
+// in the line and in the returned stats, and does not stop the export.
+//
+// The export stops early and returns an error if ctx is cancelled, the
+// client is closed, or writing to w fails.
+//
+// Example:
+//
+//	f, err := os.Create("obligations.jsonl")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer f.Close()
+//
+//	stats, err := client.ExportObligations(ctx, pins, f)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("exported %d/%d PINs\n", stats.Succeeded, stats.Total)
+func (c *Client) ExportObligations(ctx context.Context, pins []string, w io.Writer) (ExportStats, error) {
+	stats := ExportStats{Total: len(pins), Errors: make(map[string]string)}
+
+	endBatch, err := c.beginBatch()
+	if err != nil {
+		return stats, err
+	}
+	defer endBatch()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	encoder := json.NewEncoder(w)
+	var (
+		mu       sync.Mutex
+		writeErr error
+	)
+
+	workers := batchWorkers
+	if len(pins) < workers {
+		workers = len(pins)
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pin := range jobs {
+				record := c.exportRecord(ctx, pin)
+
+				mu.Lock()
+				if writeErr == nil {
+					if err := encoder.Encode(record); err != nil {
+						writeErr = err
+						cancel()
+					} else if record.Error != "" {
+						stats.Failed++
+						stats.Errors[pin] = record.Error
+					} else {
+						stats.Succeeded++
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	// Dispatch PINs until done, cancelled, or the client is closed
+	var stopErr error
+dispatch:
+	for _, pin := range pins {
+		if err := ctx.Err(); err != nil {
+			stopErr = err
+			break
+		}
+		select {
+		case jobs <- pin:
+		case <-ctx.Done():
+			stopErr = ctx.Err()
+			break dispatch
+		case <-c.done:
+			stopErr = ErrClosedDuringBatch
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if writeErr != nil {
+		return stats, writeErr
+	}
+	return stats, stopErr
+}
+
+// exportRecord fetches the obligations for a single PIN
+func (c *Client) exportRecord(ctx context.Context, pin string) ObligationExportRecord {
+	record := ObligationExportRecord{PINNumber: pin}
+
+	normalizedPIN, err := ValidateAndNormalizePIN(pin)
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+	record.PINNumber = normalizedPIN
+
+	obligations, _, err := c.fetchObligations(ctx, normalizedPIN)
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+	record.Obligations = obligations
+
+	return record
+}
@@ -0,0 +1,91 @@
+package kra
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup deduplicates concurrent calls that share a key
+//
+// Unlike a plain singleflight group, the shared call does not run on the
+// context of the caller that started it: it runs on a context that keeps that
+// caller's values but not its cancellation, and every caller waits on its own
+// context. One caller giving up therefore never fails the others. The shared
+// call is cancelled only once every caller waiting on it has given up.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightCall is a shared call in progress
+type flightCall struct {
+	done    chan struct{}
+	value   interface{}
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do runs fn once among concurrent callers of key and returns its result
+//
+// fn receives a context carrying the values of the first caller's ctx. A
+// caller whose ctx ends before fn returns gets ctx.Err() while the remaining
+// callers keep waiting.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	call, ok := g.calls[key]
+	if !ok {
+		sharedCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+		go g.run(sharedCtx, key, call, fn)
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		g.leave(key, call)
+		return nil, ctx.Err()
+	}
+}
+
+// run executes a shared call and publishes its result
+func (g *flightGroup) run(ctx context.Context, key string, call *flightCall, fn func(ctx context.Context) (interface{}, error)) {
+	defer call.cancel()
+
+	call.value, call.err = fn(ctx)
+
+	g.mu.Lock()
+	g.forget(key, call)
+	g.mu.Unlock()
+	close(call.done)
+}
+
+// leave records that a caller stopped waiting, cancelling the call once no
+// callers remain
+func (g *flightGroup) leave(key string, call *flightCall) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	call.waiters--
+	if call.waiters == 0 {
+		// Later callers must start afresh rather than join a cancelled call
+		g.forget(key, call)
+		call.cancel()
+	}
+}
+
+// forget removes call from the group if it is still registered under key
+//
+// The caller must hold g.mu.
+func (g *flightGroup) forget(key string, call *flightCall) {
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,76 @@
+package kra
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFlightGroupCancelsOnceEveryCallerLeaves(t *testing.T) {
+	var g flightGroup
+	started := make(chan struct{})
+	sharedErr := make(chan error, 1)
+	fn := func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		sharedErr <- ctx.Err()
+		return nil, ctx.Err()
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	go func() {
+		_, err := g.do(ctx1, "key", fn)
+		errs <- err
+	}()
+	<-started
+	go func() {
+		_, err := g.do(ctx2, "key", fn)
+		errs <- err
+	}()
+	waitForWaiters(t, &g, "key", 2)
+
+	cancel1()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("first caller error = %v, want context.Canceled", err)
+	}
+	select {
+	case <-sharedErr:
+		t.Fatal("shared call cancelled while a caller was still waiting")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cancel2()
+	<-errs
+	select {
+	case <-sharedErr:
+	case <-time.After(time.Second):
+		t.Fatal("shared call not cancelled after every caller left")
+	}
+
+	value, err := g.do(context.Background(), "key", func(context.Context) (interface{}, error) {
+		return "fresh", nil
+	})
+	if err != nil || value != "fresh" {
+		t.Fatalf("later call = %v, %v; want a fresh call", value, err)
+	}
+}
+
+// waitForWaiters blocks until n callers are waiting on key
+func waitForWaiters(t *testing.T, g *flightGroup, key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		call := g.calls[key]
+		joined := call != nil && call.waiters == n
+		g.mu.Unlock()
+		if joined {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d callers on %q", n, key)
+}
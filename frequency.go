@@ -0,0 +1,69 @@
+package kra
+
+import "strings"
+
+// ObligationFrequency is a normalized filing frequency for a tax obligation
+//
+// KRA reports frequencies as free text with inconsistent casing and
+// occasionally as single-letter codes. ParseObligationFrequency maps those
+// variants onto the constants below.
+type ObligationFrequency int
+
+// Normalized obligation frequencies
+const (
+	FrequencyUnknown ObligationFrequency = iota
+	FrequencyMonthly
+	FrequencyQuarterly
+	FrequencyAnnual
+)
+
+// frequencyAliases maps lowercased raw frequencies onto their normalized values
+var frequencyAliases = map[string]ObligationFrequency{
+	"m":         FrequencyMonthly,
+	"month":     FrequencyMonthly,
+	"monthly":   FrequencyMonthly,
+	"q":         FrequencyQuarterly,
+	"quarter":   FrequencyQuarterly,
+	"quarterly": FrequencyQuarterly,
+	"a":         FrequencyAnnual,
+	"y":         FrequencyAnnual,
+	"annual":    FrequencyAnnual,
+	"annually":  FrequencyAnnual,
+	"year":      FrequencyAnnual,
+	"yearly":    FrequencyAnnual,
+}
+
+// frequencyNames holds the String form of each frequency
+var frequencyNames = map[ObligationFrequency]string{
+	FrequencyUnknown:   "unknown",
+	FrequencyMonthly:   "monthly",
+	FrequencyQuarterly: "quarterly",
+	FrequencyAnnual:    "annual",
+}
+
+// String returns the lowercase name of the frequency
+func (f ObligationFrequency) String() string {
+	if name, ok := frequencyNames[f]; ok {
+		return name
+	}
+	return frequencyNames[FrequencyUnknown]
+}
+
+// ParseObligationFrequency maps a raw KRA frequency onto an ObligationFrequency
+//
+// Matching is case-insensitive and ignores surrounding whitespace, so
+// "Monthly", "MONTHLY" and "M" all map to FrequencyMonthly. Empty or
+// unrecognized values return FrequencyUnknown.
+//
+// Example:
+//
+//	kra.ParseObligationFrequency("QUARTERLY") // kra.FrequencyQuarterly
+//	kra.ParseObligationFrequency("Y")         // kra.FrequencyAnnual
+func ParseObligationFrequency(frequency string) ObligationFrequency {
+	return frequencyAliases[strings.ToLower(strings.TrimSpace(frequency))]
+}
+
+// ParsedFrequency returns the obligation's filing frequency as an ObligationFrequency
+func (o *TaxObligation) ParsedFrequency() ObligationFrequency {
+	return ParseObligationFrequency(o.Frequency)
+}
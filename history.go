@@ -0,0 +1,55 @@
+package kra
+
+import (
+	"sync"
+	"time"
+)
+
+// ResponseRecord summarizes one HTTP response received by the client
+type ResponseRecord struct {
+	Method     string        `json:"method"`
+	Endpoint   string        `json:"endpoint"`
+	StatusCode int           `json:"status_code"`
+	Attempt    int           `json:"attempt"`
+	Duration   time.Duration `json:"duration"`
+	Body       string        `json:"body"`
+	ReceivedAt time.Time     `json:"received_at"`
+}
+
+// responseHistory is a fixed-size ring buffer of the most recent responses
+type responseHistory struct {
+	mu      sync.Mutex
+	records []ResponseRecord
+	next    int
+	full    bool
+}
+
+// newResponseHistory creates a ring buffer holding up to size records
+func newResponseHistory(size int) *responseHistory {
+	return &responseHistory{records: make([]ResponseRecord, size)}
+}
+
+// add stores a record, overwriting the oldest once the buffer is full
+func (h *responseHistory) add(record ResponseRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// snapshot returns the stored records, oldest first
+func (h *responseHistory) snapshot() []ResponseRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.full {
+		return append([]ResponseRecord(nil), h.records[:h.next]...)
+	}
+	out := make([]ResponseRecord, 0, len(h.records))
+	out = append(out, h.records[h.next:]...)
+	return append(out, h.records[:h.next]...)
+}
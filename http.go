@@ -4,12 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"math"
 	"math/rand"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,21 +18,81 @@ import (
 type HTTPClient struct {
 	client       *http.Client
 	config       *Config
-	rateLimiter  *RateLimiter
+	rateLimiter  RateLimiterBackend
 	cacheManager *CacheManager
 	auth         *authProvider
+	stats        *clientStats
+	history      *responseHistory // nil unless WithResponseHistory is set
+
+	// jitter is the client's own backoff jitter source, guarded by jitterMu
+	jitter   *rand.Rand
+	jitterMu sync.Mutex
+
+	// skew is the last observed difference between KRA's and the local clock
+	skew clockSkew
+
+	// aborted is cancelled by abort; every request context derives from it
+	aborted context.Context
+	abort   context.CancelFunc
+}
+
+// withAbort derives a context that is also cancelled when the client is
+// aborted. The returned release function must be called once the request is
+// done.
+func (h *HTTPClient) withAbort(ctx context.Context) (context.Context, func()) {
+	if h.aborted == nil {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(h.aborted, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// abortError reports ErrAborted in place of err once the client has been
+// aborted, so callers can tell an Abort apart from their own cancellation
+func (h *HTTPClient) abortError(err error) error {
+	if err != nil && h.aborted != nil && h.aborted.Err() != nil {
+		return ErrAborted
+	}
+	return err
 }
 
 // NewHTTPClient creates a new HTTP client
 func NewHTTPClient(config *Config, rateLimiter *RateLimiter, cacheManager *CacheManager) *HTTPClient {
+	seed := time.Now().UnixNano()
+	if config.RetryJitterSeed != nil {
+		seed = *config.RetryJitterSeed
+	}
+
+	var limiter RateLimiterBackend = localBackend{limiter: rateLimiter}
+	if config.RateLimiterBackend != nil {
+		limiter = config.RateLimiterBackend
+	}
+
+	var history *responseHistory
+	if config.ResponseHistory > 0 {
+		history = newResponseHistory(config.ResponseHistory)
+	}
+
+	aborted, abort := context.WithCancel(context.Background())
+
 	return &HTTPClient{
+		// Attempts are bounded by context deadlines in execute, not Timeout
 		client: &http.Client{
-			Timeout: config.Timeout,
+			Transport: config.transport(),
 		},
 		config:       config,
-		rateLimiter:  rateLimiter,
+		rateLimiter:  limiter,
 		cacheManager: cacheManager,
 		auth:         newAuthProvider(config),
+		stats:        newClientStats(),
+		history:      history,
+		jitter:       rand.New(rand.NewSource(seed)),
+		aborted:      aborted,
+		abort:        abort,
 	}
 }
 
@@ -41,6 +102,10 @@ type apiRequest struct {
 	Endpoint string
 	Body     interface{}
 	Headers  map[string]string
+
+	// keyIndex selects the key from Config.APIKeys used by the request; it
+	// advances on failover
+	keyIndex uint64
 }
 
 // Post sends a POST request to the API with retry logic
@@ -54,6 +119,17 @@ func (h *HTTPClient) Post(ctx context.Context, endpoint string, body interface{}
 	return h.executeWithRetry(ctx, req)
 }
 
+// Send sends a request with an arbitrary method to the API with retry logic
+func (h *HTTPClient) Send(ctx context.Context, method, endpoint string, body interface{}) (*APIResponse, error) {
+	req := &apiRequest{
+		Method:   method,
+		Endpoint: endpoint,
+		Body:     body,
+	}
+
+	return h.executeWithRetry(ctx, req)
+}
+
 // Get sends a GET request to the API with retry logic
 func (h *HTTPClient) Get(ctx context.Context, endpoint string) (*APIResponse, error) {
 	req := &apiRequest{
@@ -65,56 +141,72 @@ func (h *HTTPClient) Get(ctx context.Context, endpoint string) (*APIResponse, er
 }
 
 // executeWithRetry executes a request with exponential backoff retry logic
-func (h *HTTPClient) executeWithRetry(ctx context.Context, req *apiRequest) (*APIResponse, error) {
+func (h *HTTPClient) executeWithRetry(ctx context.Context, req *apiRequest) (resp *APIResponse, err error) {
+	ctx, release := h.withAbort(ctx)
+	defer release()
+
+	defer func() {
+		err = h.abortError(err)
+		if err != nil {
+			h.stats.recordError(err)
+		}
+	}()
+
+	if h.config.DryRun {
+		return h.dryRun(req)
+	}
+
+	start := time.Now()
 	var lastErr error
 	delay := h.config.InitialDelay
+	failovers := 0
+	maxRetries := h.config.maxRetriesFor(req.Endpoint)
+	req.keyIndex = h.auth.rotateKey()
 
-	for attempt := 0; attempt <= h.config.MaxRetries; attempt++ {
+	for attempt := 0; attempt <= maxRetries; attempt++ {
 		// Check if context is cancelled
 		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
 
 		// Wait for rate limiter
-		if !h.waitForRateLimit(ctx) {
-			return nil, ctx.Err()
+		if err := h.waitForRateLimit(ctx); err != nil {
+			return nil, err
 		}
 
 		// Execute the request
 		response, err := h.execute(ctx, req, attempt+1)
 		if err == nil {
+			response.Meta.Attempts = attempt + 1
+			h.checkSLA(req.Endpoint, time.Since(start))
 			return response, nil
 		}
 
 		lastErr = err
 
-		// Don't retry on client errors (4xx) except 429 (rate limit)
-		if apiErr, ok := err.(*APIError); ok {
-			if apiErr.IsClientError() && apiErr.StatusCode != 429 {
-				return nil, err
-			}
+		// Fail over to the next API key without consuming a retry attempt
+		if _, overridden := apiKeyFromContext(ctx); !overridden &&
+			h.auth.canFailover(err) && failovers < len(h.config.APIKeys)-1 {
+			failovers++
+			req.keyIndex++
+			h.debugf("[HTTP] FAILOVER: Switching API key for %s after error: %v\n", req.Endpoint, err)
+			attempt--
+			continue
 		}
 
-		// Don't retry on validation errors
-		if _, ok := err.(*ValidationError); ok {
-			return nil, err
-		}
-
-		// Don't retry on authentication errors
-		if _, ok := err.(*AuthenticationError); ok {
+		if !h.isRetryable(err) {
 			return nil, err
 		}
 
 		// Last attempt - don't wait
-		if attempt >= h.config.MaxRetries {
+		if attempt >= maxRetries {
 			break
 		}
 
 		// Log retry attempt
-		if h.config.DebugMode {
-			fmt.Printf("[HTTP] RETRY: Attempt %d/%d for %s after error: %v\n",
-				attempt+1, h.config.MaxRetries+1, req.Endpoint, err)
-		}
+		h.stats.recordRetry()
+		h.debugf("[HTTP] RETRY: Attempt %d/%d for %s after error: %v\n",
+			attempt+1, maxRetries+1, req.Endpoint, err)
 
 		// Calculate backoff with jitter
 		backoff := h.calculateBackoff(delay, attempt)
@@ -127,25 +219,138 @@ func (h *HTTPClient) executeWithRetry(ctx context.Context, req *apiRequest) (*AP
 			return nil, ctx.Err()
 		}
 
-		// Exponential backoff for next iteration
-		delay = time.Duration(float64(delay) * 2)
-		if delay > h.config.MaxDelay {
+		// Exponential backoff for next iteration, guarding against overflow
+		if delay > h.config.MaxDelay/2 {
 			delay = h.config.MaxDelay
+		} else {
+			delay *= 2
 		}
 	}
 
 	return nil, lastErr
 }
 
+// isRetryable reports whether err is worth retrying under the client's retry policy
+//
+// Client errors (4xx) other than 429 and the statuses added with
+// WithRetryableStatusCodes, validation errors, and authentication errors are
+// permanent.
+//
+// SDK errors are recognized through errors.As, so they are classified the
+// same when a caller wraps them with %w.
+func (h *HTTPClient) isRetryable(err error) bool {
+	// Don't retry on client errors (4xx) except 429 (rate limit) and configured statuses
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if h.config.retryableStatus(apiErr.StatusCode) {
+			return true
+		}
+		if apiErr.IsClientError() && apiErr.StatusCode != 429 {
+			return false
+		}
+	}
+
+	// Don't retry on validation or authentication errors, including
+	// specialized ones such as InvalidPINFormatError
+	var kinded interface{ Kind() ErrorKind }
+	if errors.As(err, &kinded) {
+		switch kinded.Kind() {
+		case KindValidation, KindAuth:
+			return false
+		}
+	}
+
+	return true
+}
+
+// doWithRetry runs fn under the configured retry policy
+//
+// It uses the same attempt count, exponential backoff and jitter as API
+// requests, but does not touch the rate limiter or request statistics; the
+// SDK calls made inside fn account for those themselves.
+func (h *HTTPClient) doWithRetry(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	ctx, release := h.withAbort(ctx)
+	defer release()
+	defer func() { err = h.abortError(err) }()
+
+	var lastErr error
+	delay := h.config.InitialDelay
+
+	for attempt := 0; attempt <= h.config.MaxRetries; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		// The operation's own context ending is not a transient failure
+		if ctx.Err() != nil || !h.isRetryable(err) || attempt >= h.config.MaxRetries {
+			break
+		}
+
+		h.debugf("[HTTP] RETRY: Attempt %d/%d of caller operation after error: %v\n",
+			attempt+1, h.config.MaxRetries+1, err)
+
+		timer := time.NewTimer(h.calculateBackoff(delay, attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+
+		if delay > h.config.MaxDelay/2 {
+			delay = h.config.MaxDelay
+		} else {
+			delay *= 2
+		}
+	}
+
+	return lastErr
+}
+
+// dryRun builds the request body as execute would and returns a synthetic empty response
+func (h *HTTPClient) dryRun(req *apiRequest) (*APIResponse, error) {
+	if req.Body != nil {
+		if _, err := json.Marshal(req.Body); err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+
+	h.debugf("[HTTP] DRY_RUN: %s %s\n", req.Method, req.Endpoint)
+
+	return &APIResponse{
+		Data: map[string]interface{}{},
+		Meta: ResponseMetadata{DryRun: true},
+		Raw:  map[string]interface{}{},
+	}, nil
+}
+
 // execute sends a single HTTP request
 func (h *HTTPClient) execute(ctx context.Context, apiReq *apiRequest, attemptNumber int) (*APIResponse, error) {
+	// Every attempt is bounded by a context deadline rather than the HTTP
+	// client's Timeout, so a per-call timeout can exceed the client default
+	parentCtx := ctx
+	attemptTimeout, ok := attemptTimeoutFromContext(ctx)
+	if !ok {
+		attemptTimeout = h.config.attemptTimeoutFor(apiReq.Endpoint)
+	}
+	ctx, cancel := context.WithTimeout(ctx, attemptTimeout)
+	defer cancel()
+
 	// Build full URL
 	url := strings.TrimRight(h.config.BaseURL, "/") + apiReq.Endpoint
 
 	// Create request body
 	var bodyReader io.Reader
+	var jsonBody []byte
 	if apiReq.Body != nil {
-		jsonBody, err := json.Marshal(apiReq.Body)
+		var err error
+		jsonBody, err = json.Marshal(apiReq.Body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
@@ -161,13 +366,22 @@ func (h *HTTPClient) execute(ctx context.Context, apiReq *apiRequest, attemptNum
 	// Set headers
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "application/json")
-	token, err := h.auth.Token(ctx)
+	token, err := h.auth.Token(ctx, apiReq.keyIndex)
 	if err != nil {
 		return nil, err
 	}
 	httpReq.Header.Set("Authorization", "Bearer "+token)
 
 	httpReq.Header.Set("User-Agent", fmt.Sprintf("KRA-Connect-Go-SDK/%s", Version))
+	if h.config.AcceptLanguage != "" {
+		httpReq.Header.Set("Accept-Language", h.config.AcceptLanguage)
+	}
+
+	for key, header := range h.config.ContextHeaders {
+		if value := contextHeaderValue(ctx, key); value != "" {
+			httpReq.Header.Set(header, value)
+		}
+	}
 
 	// Add custom headers
 	for key, value := range apiReq.Headers {
@@ -175,27 +389,30 @@ func (h *HTTPClient) execute(ctx context.Context, apiReq *apiRequest, attemptNum
 	}
 
 	// Log request
-	if h.config.DebugMode {
-		fmt.Printf("[HTTP] REQUEST: %s %s (attempt %d)\n", apiReq.Method, url, attemptNumber)
+	h.debugf("[HTTP] REQUEST: %s %s (attempt %d)\n", apiReq.Method, url, attemptNumber)
+	if h.config.DebugRequestBody && jsonBody != nil {
+		h.debugf("[HTTP] REQUEST BODY: %s\n", maskPINs(string(jsonBody)))
 	}
 
 	// Send request
+	h.stats.recordRequest()
 	startTime := time.Now()
 	httpResp, err := h.client.Do(httpReq)
 	duration := time.Since(startTime)
 
 	if err != nil {
-		if h.config.DebugMode {
-			fmt.Printf("[HTTP] ERROR: Request failed after %v: %v\n", duration, err)
+		h.debugf("[HTTP] ERROR: Request failed after %v: %v\n", duration, err)
+		if parentCtx.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, NewTimeoutError(apiReq.Endpoint, attemptTimeout, attemptNumber)
 		}
 		return nil, NewNetworkError(apiReq.Endpoint, err)
 	}
 	defer httpResp.Body.Close()
 
 	// Log response
-	if h.config.DebugMode {
-		fmt.Printf("[HTTP] RESPONSE: %d in %v\n", httpResp.StatusCode, duration)
-	}
+	h.debugf("[HTTP] RESPONSE: %d in %v\n", httpResp.StatusCode, duration)
+	h.stats.recordLatency(apiReq.Endpoint, duration)
+	h.observeServerTime(httpResp.Header.Get("Date"), time.Now())
 
 	// Read response body
 	respBody, err := io.ReadAll(httpResp.Body)
@@ -203,44 +420,119 @@ func (h *HTTPClient) execute(ctx context.Context, apiReq *apiRequest, attemptNum
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	if h.history != nil {
+		h.history.add(ResponseRecord{
+			Method:     apiReq.Method,
+			Endpoint:   apiReq.Endpoint,
+			StatusCode: httpResp.StatusCode,
+			Attempt:    attemptNumber,
+			Duration:   duration,
+			Body:       truncateErrorBody(string(respBody), h.config.ErrorBodyLimit),
+			ReceivedAt: time.Now(),
+		})
+	}
+
 	// Handle non-200 status codes
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, h.handleErrorResponse(httpResp.StatusCode, respBody, apiReq.Endpoint)
+	if !isSuccessStatus(httpResp.StatusCode) {
+		return nil, h.handleErrorResponse(httpResp.StatusCode, respBody, apiReq.Endpoint, attemptNumber, attemptTimeout)
+	}
+
+	// An empty 200 is seen during partial KRA outages; report it as a
+	// retryable API error rather than a JSON parse failure
+	if len(bytes.TrimSpace(respBody)) == 0 {
+		return nil, NewAPIError(
+			httpResp.StatusCode,
+			"Empty response from KRA",
+			apiReq.Endpoint,
+			string(respBody),
+		)
 	}
 
 	// Parse response
 	var raw map[string]interface{}
 	if err := json.Unmarshal(respBody, &raw); err != nil {
-		return nil, NewAPIError(
+		return nil, h.limitErrorBody(NewAPIError(
 			httpResp.StatusCode,
 			"Failed to parse API response",
 			apiReq.Endpoint,
 			string(respBody),
-		)
+		))
 	}
 
-	apiResponse, err := normalizeAPIResponse(raw, httpResp.StatusCode, apiReq.Endpoint, respBody)
+	apiResponse, err := normalizeAPIResponse(raw, httpResp.StatusCode, apiReq.Endpoint, respBody, h.config.StrictResponseValidation)
 	if err != nil {
-		return nil, err
+		return nil, h.limitErrorBody(err)
 	}
 
 	return apiResponse, nil
 }
 
 // handleErrorResponse handles HTTP error responses
-func (h *HTTPClient) handleErrorResponse(statusCode int, body []byte, endpoint string) error {
+//
+// attemptNumber and timeout describe the attempt that received the response;
+// they are reported on a TimeoutError for 408 responses.
+func (h *HTTPClient) handleErrorResponse(statusCode int, body []byte, endpoint string, attemptNumber int, timeout time.Duration) error {
+	return classifyErrorResponse(h.config, statusCode, body, endpoint, attemptNumber, timeout)
+}
+
+// ClassifyHTTPStatus maps an HTTP error response onto the SDK error a client
+// would return for it
+//
+// It applies the same classification as live requests: 401 and 403 become an
+// *AuthenticationError, 429 a *RateLimitError, 408 a *TimeoutError, and other
+// statuses an *APIError, with any KRA error code in body attached (see
+// KRACode). Settings that would come from a client, such as the rate limit
+// reported on a RateLimitError, use their defaults. Only 200 is a success and
+// returns nil; like a live request, any other status, including the rest of
+// the 2xx range, is an error. Use it to reuse the SDK's error handling in
+// custom transports, gateways and tests.
+//
+// Example:
+//
+//	if err := kra.ClassifyHTTPStatus(resp.StatusCode, body, "/checker/v1/pinbypin"); err != nil {
+//	    return err
+//	}
+func ClassifyHTTPStatus(statusCode int, body []byte, endpoint string) error {
+	if isSuccessStatus(statusCode) {
+		return nil
+	}
+	cfg := DefaultConfig()
+	return classifyErrorResponse(cfg, statusCode, body, endpoint, 1, cfg.Timeout)
+}
+
+// isSuccessStatus reports whether a KRA response status is a success
+//
+// GavaConnect answers every successful call with 200, so any other status,
+// including the rest of the 2xx range, is treated as an error.
+func isSuccessStatus(statusCode int) bool {
+	return statusCode == http.StatusOK
+}
+
+// classifyErrorResponse builds the SDK error for an HTTP error response
+func classifyErrorResponse(cfg *Config, statusCode int, body []byte, endpoint string, attemptNumber int, timeout time.Duration) error {
 	bodyStr := string(body)
 
+	var meta ResponseMetadata
 	var raw map[string]interface{}
 	if err := json.Unmarshal(body, &raw); err == nil {
-		meta := ResponseMetadata{
+		meta = ResponseMetadata{
 			ErrorCode:    firstString(raw, "ErrorCode", "errorCode", "code"),
 			ErrorMessage: firstString(raw, "ErrorMessage", "errorMessage", "message"),
 		}
+		if errMap, ok := raw["error"].(map[string]interface{}); ok && meta.ErrorCode == "" {
+			meta.ErrorCode = firstString(errMap, "code")
+		}
 		if meta.ErrorMessage != "" {
 			bodyStr = meta.ErrorMessage
 		}
 	}
+	bodyStr = truncateErrorBody(bodyStr, cfg.ErrorBodyLimit)
+
+	return withKRACode(statusError(cfg, statusCode, bodyStr, endpoint, attemptNumber, timeout), meta.ErrorCode)
+}
+
+// statusError maps an HTTP error status to the matching SDK error
+func statusError(cfg *Config, statusCode int, bodyStr, endpoint string, attemptNumber int, timeout time.Duration) error {
 
 	// Handle specific status codes
 	switch statusCode {
@@ -253,10 +545,10 @@ func (h *HTTPClient) handleErrorResponse(statusCode int, body []byte, endpoint s
 	case http.StatusTooManyRequests:
 		// Try to extract retry-after from response
 		retryAfter := 60 * time.Second
-		return NewRateLimitError(retryAfter, h.config.MaxRequests, h.config.RateLimitWindow)
+		return NewRateLimitError(retryAfter, cfg.MaxRequests, cfg.RateLimitWindow)
 
 	case http.StatusRequestTimeout:
-		return NewTimeoutError(endpoint, h.config.Timeout, 1)
+		return NewTimeoutError(endpoint, timeout, attemptNumber)
 
 	case http.StatusBadRequest:
 		return NewAPIError(statusCode, "Bad request: "+bodyStr, endpoint, bodyStr)
@@ -265,55 +557,145 @@ func (h *HTTPClient) handleErrorResponse(statusCode int, body []byte, endpoint s
 		return NewAPIError(statusCode, "Endpoint not found: "+endpoint, endpoint, bodyStr)
 
 	default:
-		return NewAPIError(statusCode, bodyStr, endpoint, bodyStr)
+		message := bodyStr
+		if strings.TrimSpace(message) == "" {
+			message = fmt.Sprintf("HTTP %d %s", statusCode, http.StatusText(statusCode))
+		}
+		return NewAPIError(statusCode, message, endpoint, bodyStr)
+	}
+}
+
+// limitErrorBody truncates the response body stored on API errors to the
+// configured ErrorBodyLimit
+func (h *HTTPClient) limitErrorBody(err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		apiErr.truncateBody(h.config.ErrorBodyLimit)
 	}
+	return err
 }
 
-// waitForRateLimit waits for rate limiter with context support
-func (h *HTTPClient) waitForRateLimit(ctx context.Context) bool {
+// checkSLA reports a successful request that took longer than the configured SLA
+func (h *HTTPClient) checkSLA(endpoint string, elapsed time.Duration) {
+	if h.config.SLAViolationHook == nil || elapsed <= h.config.SLA {
+		return
+	}
+	h.debugf("[HTTP] SLA: %s took %v (SLA %v)\n", endpoint, elapsed, h.config.SLA)
+	h.config.SLAViolationHook(endpoint, elapsed)
+}
+
+// waitForRateLimit waits for a rate limiter token
+//
+// It returns the context error if ctx ends first, and the backend's error,
+// wrapped, if the backend fails.
+func (h *HTTPClient) waitForRateLimit(ctx context.Context) error {
 	if !h.config.RateLimitEnabled {
-		return true
+		return nil
 	}
 
 	// Try to acquire without blocking first
-	if h.rateLimiter.TryAcquire() {
-		return true
+	if ok, err := h.tryAcquire(ctx); ok || err != nil {
+		return err
 	}
 
-	// Need to wait - check estimated wait time
-	waitTime := h.rateLimiter.EstimateWaitTime()
+	h.stats.recordRateLimitWait()
 
-	if h.config.DebugMode {
-		fmt.Printf("[HTTP] RATE_LIMIT: Waiting %v for token\n", waitTime)
+	// Poll the backend, sleeping for its estimated wait between attempts;
+	// another client may take the token first when the bucket is shared
+	for {
+		waitTime, err := h.rateLimiter.EstimateWaitTime(ctx)
+		if err != nil {
+			return fmt.Errorf("rate limiter backend failed: %w", err)
+		}
+		if waitTime < minRateLimitPoll {
+			waitTime = minRateLimitPoll
+		}
+
+		h.debugf("[HTTP] RATE_LIMIT: Waiting %v for token\n", waitTime)
+
+		// Wait with context cancellation support
+		timer := time.NewTimer(waitTime)
+		select {
+		case <-timer.C:
+			if ok, err := h.tryAcquire(ctx); ok || err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
 	}
+}
 
-	// Wait with context cancellation support
-	select {
-	case <-time.After(waitTime):
-		h.rateLimiter.Wait()
-		return true
-	case <-ctx.Done():
-		return false
+// tryAcquire takes a token from the rate limiter backend without waiting
+func (h *HTTPClient) tryAcquire(ctx context.Context) (bool, error) {
+	ok, err := h.rateLimiter.TryAcquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("rate limiter backend failed: %w", err)
 	}
+	return ok, nil
 }
 
+// minRateLimitPoll is the shortest pause between rate limiter token attempts
+const minRateLimitPoll = 10 * time.Millisecond
+
+// debugf writes a debug log line when debug mode is enabled
+func (h *HTTPClient) debugf(format string, args ...interface{}) {
+	if h.config.DebugMode {
+		logf(h.config.Logger, h.config.ClientName, format, args...)
+	}
+}
+
+// minBackoff is the shortest delay calculateBackoff returns between retries
+const minBackoff = 100 * time.Millisecond
+
 // calculateBackoff calculates backoff duration with jitter
+//
+// The result always lies within [100ms, MaxDelay], or equals MaxDelay when
+// MaxDelay is configured below 100ms.
 func (h *HTTPClient) calculateBackoff(baseDelay time.Duration, attempt int) time.Duration {
-	// Exponential backoff: baseDelay * 2^attempt
-	backoff := float64(baseDelay) * math.Pow(2, float64(attempt))
+	maxDelay := h.config.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = minBackoff
+	}
+
+	// Never sleep less than minBackoff, unless MaxDelay itself is smaller
+	floor := minBackoff
+	if floor > maxDelay {
+		floor = maxDelay
+	}
+
+	if baseDelay <= 0 {
+		baseDelay = floor
+	}
+	if attempt < 0 {
+		attempt = 0
+	}
+
+	// Exponential backoff: baseDelay * 2^attempt, doubling only until the
+	// cap is reached so large attempts never produce huge intermediates
+	backoff := float64(baseDelay)
+	for i := 0; i < attempt && backoff < float64(maxDelay); i++ {
+		backoff *= 2
+	}
 
 	// Cap at max delay
-	if backoff > float64(h.config.MaxDelay) {
-		backoff = float64(h.config.MaxDelay)
+	if backoff > float64(maxDelay) {
+		backoff = float64(maxDelay)
 	}
 
 	// Add jitter (±25%)
-	jitter := backoff * 0.25 * (rand.Float64()*2 - 1)
+	h.jitterMu.Lock()
+	jitter := backoff * 0.25 * (h.jitter.Float64()*2 - 1)
+	h.jitterMu.Unlock()
 	backoff += jitter
 
-	// Ensure minimum delay of 100ms
-	if backoff < 100 {
-		backoff = 100
+	// Keep the jittered delay within [floor, maxDelay]
+	if backoff < float64(floor) {
+		backoff = float64(floor)
+	}
+	if backoff > float64(maxDelay) {
+		backoff = float64(maxDelay)
 	}
 
 	return time.Duration(backoff)
@@ -1,9 +1,16 @@
 package kra
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -63,32 +70,98 @@ func TestHTTPClientHandleErrorResponse(t *testing.T) {
 	cacheManager := NewCacheManager(false, cfg.DebugMode, cfg.CacheMaxEntries)
 	client := NewHTTPClient(cfg, rateLimiter, cacheManager)
 
-	err := client.handleErrorResponse(http.StatusUnauthorized, []byte(`{"error":{"message":"bad"}}`), "/checker/v1/pinbypin")
+	err := client.handleErrorResponse(http.StatusUnauthorized, []byte(`{"error":{"message":"bad"}}`), "/checker/v1/pinbypin", 1, time.Second)
 	if _, ok := err.(*AuthenticationError); !ok {
 		t.Fatalf("expected AuthenticationError, got %v", err)
 	}
 
-	err = client.handleErrorResponse(http.StatusTooManyRequests, []byte(`{"error":{"message":"limit"}}`), "/checker/v1/pinbypin")
+	err = client.handleErrorResponse(http.StatusTooManyRequests, []byte(`{"error":{"message":"limit"}}`), "/checker/v1/pinbypin", 1, time.Second)
 	if _, ok := err.(*RateLimitError); !ok {
 		t.Fatalf("expected RateLimitError, got %v", err)
 	}
 
-	err = client.handleErrorResponse(http.StatusBadRequest, []byte(`{"error":{"message":"bad","details":"oops"}}`), "/checker/v1/pinbypin")
+	err = client.handleErrorResponse(http.StatusBadRequest, []byte(`{"error":{"message":"bad","details":"oops"}}`), "/checker/v1/pinbypin", 1, time.Second)
 	if _, ok := err.(*APIError); !ok {
 		t.Fatalf("expected APIError for bad request, got %v", err)
 	}
 
-	err = client.handleErrorResponse(http.StatusNotFound, []byte(`{}`), "/unknown")
+	err = client.handleErrorResponse(http.StatusNotFound, []byte(`{}`), "/unknown", 1, time.Second)
 	if _, ok := err.(*APIError); !ok {
 		t.Fatalf("expected APIError for not found, got %v", err)
 	}
 
-	err = client.handleErrorResponse(http.StatusRequestTimeout, []byte(`{}`), "/slow")
+	err = client.handleErrorResponse(http.StatusRequestTimeout, []byte(`{}`), "/slow", 1, time.Second)
 	if _, ok := err.(*TimeoutError); !ok {
 		t.Fatalf("expected TimeoutError, got %v", err)
 	}
 }
 
+func TestClassifyHTTPStatus(t *testing.T) {
+	if err := ClassifyHTTPStatus(http.StatusOK, []byte(`{}`), "/checker/v1/pinbypin"); err != nil {
+		t.Fatalf("expected nil for 200, got %v", err)
+	}
+
+	err := ClassifyHTTPStatus(http.StatusNoContent, nil, "/checker/v1/pinbypin")
+	if apiErr, ok := err.(*APIError); !ok || apiErr.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected APIError for 204, got %v", err)
+	}
+
+	err = ClassifyHTTPStatus(http.StatusUnauthorized, []byte(`{"error":{"message":"bad"}}`), "/checker/v1/pinbypin")
+	if _, ok := err.(*AuthenticationError); !ok {
+		t.Fatalf("expected AuthenticationError for 401, got %v", err)
+	}
+
+	err = ClassifyHTTPStatus(http.StatusForbidden, nil, "/checker/v1/pinbypin")
+	if _, ok := err.(*AuthenticationError); !ok {
+		t.Fatalf("expected AuthenticationError for 403, got %v", err)
+	}
+
+	err = ClassifyHTTPStatus(http.StatusTooManyRequests, []byte(`{"error":{"code":"RL01"}}`), "/checker/v1/pinbypin")
+	if _, ok := err.(*RateLimitError); !ok || KRACode(err) != "RL01" {
+		t.Fatalf("expected RateLimitError with KRA code, got %v", err)
+	}
+
+	err = ClassifyHTTPStatus(http.StatusRequestTimeout, []byte(`{}`), "/slow")
+	if timeoutErr, ok := err.(*TimeoutError); !ok || timeoutErr.Endpoint != "/slow" || timeoutErr.AttemptNumber != 1 {
+		t.Fatalf("expected TimeoutError for /slow, got %v", err)
+	}
+
+	err = ClassifyHTTPStatus(http.StatusBadRequest, []byte(`{"errorMessage":"PIN missing","errorCode":"E01"}`), "/checker/v1/pinbypin")
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != http.StatusBadRequest || !strings.Contains(apiErr.Message, "PIN missing") || KRACode(err) != "E01" {
+		t.Fatalf("expected APIError for bad request, got %v", err)
+	}
+
+	err = ClassifyHTTPStatus(http.StatusNotFound, []byte(`{}`), "/unknown")
+	if apiErr, ok := err.(*APIError); !ok || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected APIError for not found, got %v", err)
+	}
+
+	err = ClassifyHTTPStatus(http.StatusBadGateway, nil, "/checker/v1/pinbypin")
+	if apiErr, ok := err.(*APIError); !ok || apiErr.Message != "HTTP 502 Bad Gateway" {
+		t.Fatalf("expected APIError with status text for empty 502, got %v", err)
+	}
+}
+
+func TestClassifyHTTPStatusMatchesLiveRequest(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+	client, server := newClientWithServer(t, handler, WithRetry(0, time.Millisecond, time.Millisecond))
+	defer server.Close()
+
+	_, liveErr := client.httpClient.Post(context.Background(), "/checker/v1/pinbypin", map[string]string{})
+	classified := ClassifyHTTPStatus(http.StatusNoContent, nil, "/checker/v1/pinbypin")
+
+	var liveAPIErr, classifiedAPIErr *APIError
+	if !errors.As(liveErr, &liveAPIErr) || !errors.As(classified, &classifiedAPIErr) {
+		t.Fatalf("expected APIErrors, got live %v and classified %v", liveErr, classified)
+	}
+	if liveAPIErr.StatusCode != classifiedAPIErr.StatusCode || liveAPIErr.Message != classifiedAPIErr.Message {
+		t.Fatalf("live error %v differs from classified error %v", liveErr, classified)
+	}
+}
+
 func TestHTTPClientWaitForRateLimit(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.APIKey = "ABCDEFGHIJKLMNOP"
@@ -101,11 +174,11 @@ func TestHTTPClientWaitForRateLimit(t *testing.T) {
 	client := NewHTTPClient(cfg, rateLimiter, cacheManager)
 
 	ctx := context.Background()
-	if !client.waitForRateLimit(ctx) {
-		t.Fatalf("expected first acquire to succeed")
+	if err := client.waitForRateLimit(ctx); err != nil {
+		t.Fatalf("expected first acquire to succeed, got %v", err)
 	}
-	if !client.waitForRateLimit(ctx) {
-		t.Fatalf("expected second acquire to eventually succeed")
+	if err := client.waitForRateLimit(ctx); err != nil {
+		t.Fatalf("expected second acquire to eventually succeed, got %v", err)
 	}
 }
 
@@ -123,6 +196,42 @@ func TestHTTPClientInvalidJSON(t *testing.T) {
 	}
 }
 
+func TestHTTPClientEmptyResponseIsRetried(t *testing.T) {
+	var attempts int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(" \n\t"))
+			return
+		}
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"is_valid": true}})
+	}
+	client, server := newClientWithServer(t, handler, WithoutCache(), WithRetry(1, time.Millisecond, time.Millisecond))
+	defer server.Close()
+
+	ctx := context.Background()
+	if _, err := client.httpClient.Post(ctx, "/empty", map[string]string{}); err != nil {
+		t.Fatalf("Post() error = %v, expected the empty response to be retried", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+
+	// Without retries the clear error surfaces
+	atomic.StoreInt32(&attempts, 0)
+	noRetry, noRetryServer := newClientWithServer(t, handler, WithoutCache(), WithRetry(0, time.Millisecond, time.Millisecond))
+	defer noRetryServer.Close()
+
+	_, err := noRetry.httpClient.Post(ctx, "/empty", map[string]string{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "Empty response from KRA" {
+		t.Fatalf("expected empty response APIError, got %v", err)
+	}
+	if !noRetry.httpClient.isRetryable(err) {
+		t.Error("expected the empty response error to be retryable")
+	}
+}
+
 func TestHTTPClientAPIFailure(t *testing.T) {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		writeJSON(t, w, apiResponse{
@@ -156,6 +265,32 @@ func TestHTTPClientCalculateBackoff(t *testing.T) {
 	_ = client.calculateBackoff(time.Hour, 10)
 }
 
+func TestHTTPClientCalculateBackoffBounds(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.APIKey = "ABCDEFGHIJKLMNOP"
+	cfg.MaxRetries = 10
+	cfg.InitialDelay = 20 * time.Second
+	cfg.MaxDelay = 32 * time.Second
+	rateLimiter := NewRateLimiter(cfg.MaxRequests, cfg.RateLimitWindow, false, cfg.DebugMode)
+	cacheManager := NewCacheManager(false, cfg.DebugMode, cfg.CacheMaxEntries)
+	client := NewHTTPClient(cfg, rateLimiter, cacheManager)
+
+	for _, base := range []time.Duration{cfg.InitialDelay, time.Duration(1 << 62), -time.Second, 0} {
+		for attempt := -1; attempt <= 1000; attempt++ {
+			backoff := client.calculateBackoff(base, attempt)
+			if backoff < minBackoff || backoff > cfg.MaxDelay {
+				t.Fatalf("calculateBackoff(%v, %d) = %v, want within [%v, %v]", base, attempt, backoff, minBackoff, cfg.MaxDelay)
+			}
+		}
+	}
+
+	// A MaxDelay below the floor caps the backoff at MaxDelay
+	cfg.MaxDelay = 5 * time.Millisecond
+	if backoff := client.calculateBackoff(time.Millisecond, 3); backoff != cfg.MaxDelay {
+		t.Fatalf("calculateBackoff() = %v, want %v", backoff, cfg.MaxDelay)
+	}
+}
+
 func TestHTTPClientContextCancelled(t *testing.T) {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(100 * time.Millisecond)
@@ -194,3 +329,748 @@ func TestHTTPClientClientErrorNoRetry(t *testing.T) {
 		t.Fatalf("expected no retries on client error, got %d attempts", attempts)
 	}
 }
+
+func TestHTTPClientStrictResponseValidation(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}
+
+	lenient, server := newClientWithServer(t, handler, WithoutCache(), WithRetry(0, time.Millisecond, time.Millisecond))
+	defer server.Close()
+	if _, err := lenient.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil); err != nil {
+		t.Fatalf("expected lenient client to accept empty envelope, got %v", err)
+	}
+
+	strict, strictServer := newClientWithServer(t, handler,
+		WithoutCache(),
+		WithRetry(0, time.Millisecond, time.Millisecond),
+		WithStrictResponseValidation(true),
+	)
+	defer strictServer.Close()
+
+	_, err := strict.VerifyPIN(context.Background(), "P051234567A")
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	if !strings.Contains(apiErr.Message, "neither a data payload nor error metadata") {
+		t.Fatalf("unexpected error message: %s", apiErr.Message)
+	}
+}
+
+func TestHTTPClientNonObjectPayload(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success": true, "responseData": [{"isValid": true}]}`))
+	}
+
+	lenient, server := newClientWithServer(t, handler, WithoutCache(), WithRetry(0, time.Millisecond, time.Millisecond))
+	defer server.Close()
+	resp, err := lenient.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil)
+	if err != nil {
+		t.Fatalf("expected lenient client to accept array payload, got %v", err)
+	}
+	if _, ok := resp.Data["responseData"]; !ok {
+		t.Fatalf("expected lenient client to fall back to the envelope, got %v", resp.Data)
+	}
+
+	strict, strictServer := newClientWithServer(t, handler,
+		WithoutCache(),
+		WithRetry(0, time.Millisecond, time.Millisecond),
+		WithStrictResponseValidation(true),
+	)
+	defer strictServer.Close()
+
+	_, err = strict.VerifyPIN(context.Background(), "P051234567A")
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	if !strings.Contains(apiErr.Message, `"responseData" is a JSON array`) {
+		t.Fatalf("unexpected error message: %s", apiErr.Message)
+	}
+}
+
+func TestExtractPayloadLegacyNonObjectData(t *testing.T) {
+	for _, body := range []string{`{"success": true, "data": "oops"}`, `{"success": true}`} {
+		var raw map[string]interface{}
+		if err := json.Unmarshal([]byte(body), &raw); err != nil {
+			t.Fatalf("unmarshal %s: %v", body, err)
+		}
+
+		resp, err := normalizeAPIResponse(raw, http.StatusOK, "/checker/v1/pinbypin", []byte(body), false)
+		if err != nil {
+			t.Fatalf("normalizeAPIResponse(%s) error = %v", body, err)
+		}
+		if resp.Data == nil || resp.Data["success"] != true {
+			t.Fatalf("expected envelope fallback for %s, got %v", body, resp.Data)
+		}
+	}
+}
+
+func TestClientResultRecordsAttempts(t *testing.T) {
+	var attempts int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active"},
+		})
+	}
+	client, server := newClientWithServer(t, handler, WithRetry(3, 10*time.Millisecond, 10*time.Millisecond))
+	defer server.Close()
+
+	result, err := client.VerifyPIN(context.Background(), "P051234567A")
+	if err != nil {
+		t.Fatalf("VerifyPIN() error = %v", err)
+	}
+	if result.Metadata.Attempts != 2 {
+		t.Fatalf("expected Attempts == 2, got %d", result.Metadata.Attempts)
+	}
+}
+
+func TestContextWithAttemptTimeout(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active"},
+		})
+	}
+	client, server := newClientWithServer(t, handler, WithoutCache(), WithRetry(0, time.Millisecond, time.Millisecond))
+	defer server.Close()
+
+	// The client default (30s) comfortably covers the slow handler
+	if _, err := client.VerifyPIN(context.Background(), "P051234567A"); err != nil {
+		t.Fatalf("VerifyPIN() with default timeout error = %v", err)
+	}
+
+	ctx := ContextWithAttemptTimeout(context.Background(), 20*time.Millisecond)
+	_, err := client.VerifyPIN(ctx, "P051234567A")
+	timeoutErr, ok := err.(*TimeoutError)
+	if !ok {
+		t.Fatalf("expected TimeoutError, got %v", err)
+	}
+	if timeoutErr.Timeout != 20*time.Millisecond {
+		t.Fatalf("expected per-call timeout in error, got %v", timeoutErr.Timeout)
+	}
+}
+
+func TestContextWithAttemptTimeoutExtendsClientTimeout(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"ok": true}})
+	}
+	client, server := newClientWithServer(t, handler,
+		WithoutCache(),
+		WithRetry(0, time.Millisecond, time.Millisecond),
+		WithTimeout(100*time.Millisecond),
+	)
+	defer server.Close()
+
+	// A slow call may be given more time than the client default
+	ctx := ContextWithAttemptTimeout(context.Background(), 2*time.Second)
+	if _, err := client.httpClient.Post(ctx, "/checker/v1/pinbypin", nil); err != nil {
+		t.Fatalf("Post() with a longer per-call timeout error = %v", err)
+	}
+
+	var timeoutErr *TimeoutError
+	_, err := client.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil)
+	if !errors.As(err, &timeoutErr) || timeoutErr.Timeout != 100*time.Millisecond {
+		t.Fatalf("expected TimeoutError with the client timeout, got %v", err)
+	}
+}
+
+func TestEndpointTimeouts(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(150 * time.Millisecond)
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"ok": true}})
+	}
+	client, server := newClientWithServer(t, handler,
+		WithoutCache(),
+		WithRetry(0, time.Millisecond, time.Millisecond),
+		WithTimeout(50*time.Millisecond),
+		WithEndpointTimeouts(map[string]time.Duration{"/dtd/checker/v1/taxpayer": 2 * time.Second}),
+	)
+	defer server.Close()
+
+	// The listed endpoint gets its own, longer budget
+	if _, err := client.httpClient.Post(context.Background(), "/dtd/checker/v1/taxpayer", nil); err != nil {
+		t.Fatalf("Post() to endpoint with its own timeout error = %v", err)
+	}
+
+	// Other endpoints keep the global timeout
+	_, err := client.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil)
+	timeoutErr, ok := err.(*TimeoutError)
+	if !ok {
+		t.Fatalf("expected TimeoutError, got %v", err)
+	}
+	if timeoutErr.Timeout != 50*time.Millisecond {
+		t.Fatalf("expected global timeout in error, got %v", timeoutErr.Timeout)
+	}
+
+	if _, err := NewClient(WithAPIKey(strings.Repeat("A", 16)), WithEndpointTimeouts(map[string]time.Duration{"/x": 0})); err == nil {
+		t.Fatal("expected error for non-positive endpoint timeout")
+	}
+}
+
+func TestTimeoutErrorReportsAttempt(t *testing.T) {
+	var attempts int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusRequestTimeout)
+	}
+	client, server := newClientWithServer(t, handler, WithoutCache(), WithRetry(2, time.Millisecond, time.Millisecond))
+	defer server.Close()
+
+	// A 408 from KRA reports the attempt that received it and the timeout it ran under
+	ctx := ContextWithAttemptTimeout(context.Background(), 2*time.Second)
+	_, err := client.httpClient.Post(ctx, "/checker/v1/pinbypin", nil)
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected TimeoutError, got %v", err)
+	}
+	if timeoutErr.AttemptNumber != 3 || atomic.LoadInt32(&attempts) != 3 {
+		t.Fatalf("AttemptNumber = %d after %d attempts, want 3", timeoutErr.AttemptNumber, attempts)
+	}
+	if timeoutErr.Timeout != 2*time.Second {
+		t.Fatalf("Timeout = %v, want the 2s attempt timeout", timeoutErr.Timeout)
+	}
+
+	// Without an attempt timeout the client's timeout applies
+	_, err = client.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil)
+	if !errors.As(err, &timeoutErr) || timeoutErr.Timeout != client.config.Timeout {
+		t.Fatalf("expected TimeoutError with the client timeout, got %v", err)
+	}
+}
+
+func TestHTTPClientRotatesAPIKeys(t *testing.T) {
+	keys := []string{strings.Repeat("1", 16), strings.Repeat("2", 16), strings.Repeat("3", 16)}
+
+	var seen []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"ok": true}})
+	}
+	client, server := newClientWithServer(t, handler, WithoutCache(), WithAPIKeys(keys...))
+	defer server.Close()
+
+	for i := 0; i < 4; i++ {
+		if _, err := client.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil); err != nil {
+			t.Fatalf("Post() error = %v", err)
+		}
+	}
+
+	want := []string{keys[0], keys[1], keys[2], keys[0]}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("request %d used key %s, want %s", i, seen[i], want[i])
+		}
+	}
+}
+
+func TestHTTPClientFailsOverOnUnauthorizedKey(t *testing.T) {
+	revoked := strings.Repeat("R", 16)
+	healthy := strings.Repeat("H", 16)
+
+	var seen []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		seen = append(seen, key)
+		if key == revoked {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"ok": true}})
+	}
+	client, server := newClientWithServer(t, handler,
+		WithoutCache(),
+		WithAPIKeys(revoked, healthy),
+		WithRetry(0, time.Millisecond, time.Millisecond),
+	)
+	defer server.Close()
+
+	if _, err := client.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil); err != nil {
+		t.Fatalf("expected failover to succeed, got %v", err)
+	}
+	if len(seen) != 2 || seen[0] != revoked || seen[1] != healthy {
+		t.Fatalf("unexpected key sequence: %v", seen)
+	}
+
+	if err := WithAPIKeys(healthy, "short")(DefaultConfig()); err == nil {
+		t.Fatal("expected validation error for short key")
+	}
+}
+
+func TestHTTPClientFailsOverConcurrently(t *testing.T) {
+	revoked := strings.Repeat("R", 16)
+	healthy := strings.Repeat("H", 16)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ") == revoked {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"ok": true}})
+	}
+	client, server := newClientWithServer(t, handler,
+		WithoutCache(),
+		WithAPIKeys(revoked, healthy),
+		WithRetry(0, time.Millisecond, time.Millisecond),
+	)
+	defer server.Close()
+
+	// Other requests advancing the rotation must not send a failover back to
+	// the key that just failed
+	const callers = 40
+	var failed int32
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil); err != nil {
+				atomic.AddInt32(&failed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&failed); got != 0 {
+		t.Fatalf("expected every request to fail over to the healthy key, %d of %d failed", got, callers)
+	}
+}
+
+func TestHTTPClientSendsAcceptLanguage(t *testing.T) {
+	var got string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Accept-Language")
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"ok": true}})
+	}
+	client, server := newClientWithServer(t, handler, WithoutCache(), WithAcceptLanguage("sw"))
+	defer server.Close()
+
+	if _, err := client.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if got != "sw" {
+		t.Fatalf("Accept-Language = %q, want %q", got, "sw")
+	}
+
+	if err := WithAcceptLanguage(" ")(DefaultConfig()); err == nil {
+		t.Fatal("expected error for blank language")
+	}
+}
+
+type testTraceKey struct{}
+
+func TestHTTPClientForwardsContextHeaders(t *testing.T) {
+	var trace, tenant []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		trace = append(trace, r.Header.Get("X-Trace-ID"))
+		tenant = append(tenant, r.Header.Get("X-Tenant-ID"))
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"ok": true}})
+	}
+	client, server := newClientWithServer(t, handler, WithoutCache(),
+		WithContextHeaderMapping(map[interface{}]string{testTraceKey{}: "X-Trace-ID"}),
+		WithContextHeaderMapping(map[interface{}]string{"tenant": "X-Tenant-ID"}),
+	)
+	defer server.Close()
+
+	ctx := context.WithValue(context.Background(), testTraceKey{}, "trace-123")
+	ctx = context.WithValue(ctx, "tenant", 42) //nolint:staticcheck // exercising non-string values
+	if _, err := client.httpClient.Post(ctx, "/checker/v1/pinbypin", nil); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if _, err := client.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+
+	if trace[0] != "trace-123" || tenant[0] != "42" {
+		t.Fatalf("present keys: trace = %q, tenant = %q", trace[0], tenant[0])
+	}
+	if trace[1] != "" || tenant[1] != "" {
+		t.Fatalf("absent keys should not set headers: trace = %q, tenant = %q", trace[1], tenant[1])
+	}
+
+	if err := WithContextHeaderMapping(nil)(DefaultConfig()); err == nil {
+		t.Fatal("expected error for empty mapping")
+	}
+	if err := WithContextHeaderMapping(map[interface{}]string{testTraceKey{}: " "})(DefaultConfig()); err == nil {
+		t.Fatal("expected error for blank header name")
+	}
+}
+
+func TestHTTPClientContextAPIKeyOverride(t *testing.T) {
+	var auth []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"ok": true}})
+	}
+	client, server := newClientWithServer(t, handler, WithoutCache())
+	defer server.Close()
+
+	tenantA := ContextWithAPIKey(context.Background(), "TENANTAKEY000001")
+	tenantB := ContextWithAPIKey(context.Background(), "TENANTBKEY000002")
+	for _, ctx := range []context.Context{tenantA, tenantB, context.Background()} {
+		if _, err := client.httpClient.Post(ctx, "/checker/v1/pinbypin", nil); err != nil {
+			t.Fatalf("Post() error = %v", err)
+		}
+	}
+
+	want := []string{"Bearer TENANTAKEY000001", "Bearer TENANTBKEY000002", "Bearer " + client.config.APIKey}
+	for i := range want {
+		if auth[i] != want[i] {
+			t.Fatalf("request %d Authorization = %q, want %q", i, auth[i], want[i])
+		}
+	}
+
+	_, err := client.httpClient.Post(ContextWithAPIKey(context.Background(), "short"), "/checker/v1/pinbypin", nil)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError for invalid override key, got %v", err)
+	}
+	if len(auth) != 3 {
+		t.Fatalf("invalid override key should not reach the API, got %d requests", len(auth))
+	}
+}
+
+func TestHTTPClientSLAViolationHook(t *testing.T) {
+	var slow int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&slow) == 1 {
+			time.Sleep(60 * time.Millisecond)
+		}
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"ok": true}})
+	}
+
+	var violations []time.Duration
+	var endpoints []string
+	client, server := newClientWithServer(t, handler, WithoutCache(),
+		WithSLA(30*time.Millisecond, func(endpoint string, actual time.Duration) {
+			endpoints = append(endpoints, endpoint)
+			violations = append(violations, actual)
+		}),
+	)
+	defer server.Close()
+
+	if _, err := client.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("fast request should not violate the SLA, got %v", violations)
+	}
+
+	atomic.StoreInt32(&slow, 1)
+	if _, err := client.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if len(violations) != 1 || endpoints[0] != "/checker/v1/pinbypin" || violations[0] < 60*time.Millisecond {
+		t.Fatalf("expected one violation for the slow request, got %v on %v", violations, endpoints)
+	}
+
+	if err := WithSLA(0, func(string, time.Duration) {})(DefaultConfig()); err == nil {
+		t.Fatal("expected error for non-positive SLA")
+	}
+}
+
+func TestHTTPClientRetryJitterSeedIsDeterministic(t *testing.T) {
+	newSeeded := func(seed int64) *HTTPClient {
+		cfg := DefaultConfig()
+		cfg.APIKey = "ABCDEFGHIJKLMNOP"
+		if err := WithRetryJitterSeed(seed)(cfg); err != nil {
+			t.Fatalf("WithRetryJitterSeed() error = %v", err)
+		}
+		rateLimiter := NewRateLimiter(cfg.MaxRequests, cfg.RateLimitWindow, false, cfg.DebugMode)
+		cacheManager := NewCacheManager(false, cfg.DebugMode, cfg.CacheMaxEntries)
+		return NewHTTPClient(cfg, rateLimiter, cacheManager)
+	}
+
+	first, second, other := newSeeded(42), newSeeded(42), newSeeded(7)
+
+	var sameAsOther = true
+	for attempt := 0; attempt < 5; attempt++ {
+		a := first.calculateBackoff(time.Second, attempt)
+		b := second.calculateBackoff(time.Second, attempt)
+		if a != b {
+			t.Fatalf("attempt %d: backoff %v != %v for identical seeds", attempt, a, b)
+		}
+		if a != other.calculateBackoff(time.Second, attempt) {
+			sameAsOther = false
+		}
+	}
+	if sameAsOther {
+		t.Fatal("expected a different seed to produce a different sequence")
+	}
+}
+
+func TestHTTPClientTruncatesLargeErrorBodies(t *testing.T) {
+	largeBody := strings.Repeat("x", 10000)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(largeBody))
+	}
+
+	client, server := newClientWithServer(t, handler,
+		WithRetry(0, time.Millisecond, time.Millisecond),
+		WithErrorBodyLimit(100),
+	)
+	defer server.Close()
+
+	_, err := client.VerifyPIN(context.Background(), "P051234567A")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %T: %v", err, err)
+	}
+
+	want := strings.Repeat("x", 100) + "... (truncated 9900 bytes)"
+	if apiErr.ResponseBody != want {
+		t.Fatalf("ResponseBody length = %d, want truncated body of length %d", len(apiErr.ResponseBody), len(want))
+	}
+	if apiErr.Details["response_body"] != want {
+		t.Fatalf("expected Details[response_body] to be truncated")
+	}
+	if len(apiErr.Error()) > 200 {
+		t.Fatalf("expected error message to be truncated, got %d bytes", len(apiErr.Error()))
+	}
+}
+
+type countingTransport struct {
+	next  http.RoundTripper
+	count int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.count, 1)
+	return t.next.RoundTrip(req)
+}
+
+func TestHTTPClientTransportWrapperIsInvoked(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"pinStatus": "active"}})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	counter := &countingTransport{}
+	client, err := NewClient(
+		WithAPIKey(strings.Repeat("A", 16)),
+		WithBaseURL(server.URL),
+		WithoutRateLimit(),
+		WithTransportWrapper(func(next http.RoundTripper) http.RoundTripper {
+			counter.next = next
+			return counter
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	for _, pin := range []string{"P051234567A", "P051234567B"} {
+		if _, err := client.VerifyPIN(context.Background(), pin); err != nil {
+			t.Fatalf("VerifyPIN() error = %v", err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&counter.count); got != 2 {
+		t.Fatalf("expected wrapper to see 2 requests, got %d", got)
+	}
+}
+
+func TestHTTPClientDisableKeepAlives(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"pinStatus": "active"}})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	newClient := func(opts ...Option) *Client {
+		opts = append([]Option{
+			WithAPIKey(strings.Repeat("A", 16)),
+			WithBaseURL(server.URL),
+			WithoutRateLimit(),
+			WithDisableKeepAlives(true),
+		}, opts...)
+		client, err := NewClient(opts...)
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		return client
+	}
+
+	client := newClient()
+
+	transport, ok := client.httpClient.client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", client.httpClient.client.Transport)
+	}
+	if !transport.DisableKeepAlives {
+		t.Error("expected DisableKeepAlives to be set on the transport")
+	}
+	if http.DefaultTransport.(*http.Transport).DisableKeepAlives {
+		t.Error("WithDisableKeepAlives must not modify http.DefaultTransport")
+	}
+	if _, err := client.VerifyPIN(context.Background(), "P051234567A"); err != nil {
+		t.Fatalf("VerifyPIN() error = %v", err)
+	}
+
+	// Wrappers receive the keep-alive-free transport as their base
+	var wrapped http.RoundTripper
+	newClient(WithTransportWrapper(func(next http.RoundTripper) http.RoundTripper {
+		wrapped = next
+		return next
+	}))
+
+	if base, ok := wrapped.(*http.Transport); !ok || !base.DisableKeepAlives {
+		t.Errorf("expected wrapper to receive a transport with keep-alives disabled, got %T", wrapped)
+	}
+}
+
+func TestHTTPClientRetryableStatusCodes(t *testing.T) {
+	var attempts int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"is_valid": true}})
+	}
+	ctx := context.Background()
+
+	// 409 is permanent by default
+	client, server := newClientWithServer(t, handler, WithoutCache(), WithRetry(3, time.Millisecond, time.Millisecond))
+	defer server.Close()
+	if _, err := client.httpClient.Post(ctx, "/conflict", map[string]string{}); err == nil {
+		t.Fatal("expected 409 to fail without retries")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Fatalf("expected 1 attempt for a non-retryable status, got %d", got)
+	}
+
+	atomic.StoreInt32(&attempts, 0)
+	retrying, retryingServer := newClientWithServer(t, handler,
+		WithoutCache(),
+		WithRetry(3, time.Millisecond, time.Millisecond),
+		WithRetryableStatusCodes(http.StatusConflict),
+	)
+	defer retryingServer.Close()
+	if _, err := retrying.httpClient.Post(ctx, "/conflict", map[string]string{}); err != nil {
+		t.Fatalf("Post() error = %v, expected 409 to be retried until success", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+
+	for _, codes := range [][]int{nil, {99}, {600}} {
+		if err := WithRetryableStatusCodes(codes...)(DefaultConfig()); err == nil {
+			t.Errorf("expected error for status codes %v", codes)
+		}
+	}
+}
+
+func TestHTTPClientEndpointRetriesOverride(t *testing.T) {
+	var pinAttempts, nilAttempts int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/checker/v1/pinbypin":
+			atomic.AddInt32(&pinAttempts, 1)
+		case "/dtd/return/v1/nil":
+			atomic.AddInt32(&nilAttempts, 1)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	client, server := newClientWithServer(t, handler,
+		WithRetry(3, time.Millisecond, time.Millisecond),
+		WithEndpointRetries("/dtd/return/v1/nil", 1),
+	)
+	defer server.Close()
+
+	ctx := context.Background()
+	if _, err := client.VerifyPIN(ctx, "P051234567A"); err == nil {
+		t.Fatal("expected PIN verification to fail")
+	}
+	if _, err := client.FileNILReturn(ctx, &NILReturnRequest{PINNumber: "P051234567A", ObligationCode: 1, Month: 1, Year: 2024}); err == nil {
+		t.Fatal("expected NIL return to fail")
+	}
+
+	if got := atomic.LoadInt32(&pinAttempts); got != 4 {
+		t.Fatalf("expected 4 PIN attempts, got %d", got)
+	}
+	if got := atomic.LoadInt32(&nilAttempts); got != 2 {
+		t.Fatalf("expected 2 NIL return attempts, got %d", got)
+	}
+
+	if _, err := NewClient(WithAPIKey(strings.Repeat("A", 16)), WithEndpointRetries("/dtd/return/v1/nil", 11)); err == nil {
+		t.Fatal("expected error for endpoint retries above 10")
+	}
+}
+
+func TestResponseMetadataDescription(t *testing.T) {
+	tests := []struct {
+		name string
+		meta ResponseMetadata
+		want string
+	}{
+		{"server description wins", ResponseMetadata{ResponseCode: "404", ResponseDesc: "PIN not registered"}, "PIN not registered"},
+		{"falls back to known code", ResponseMetadata{ResponseCode: "404"}, "Record not found"},
+		{"blank description falls back", ResponseMetadata{ResponseCode: " 429 ", ResponseDesc: "  "}, "Too many requests: rate limit exceeded"},
+		{"unknown code", ResponseMetadata{ResponseCode: "99999"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.meta.Description(); got != tt.want {
+				t.Errorf("Description() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerTimeSkew(t *testing.T) {
+	var skew atomic.Int64
+	skew.Store(int64(10 * time.Minute))
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(time.Duration(skew.Load())).UTC().Format(http.TimeFormat))
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"ok": true}})
+	}
+
+	var buf bytes.Buffer
+	client, server := newClientWithServer(t, handler,
+		WithoutCache(),
+		WithLogger(log.New(&buf, "", 0)),
+		WithClockSkewWarning(time.Minute),
+	)
+	defer server.Close()
+
+	if got := client.ServerTimeSkew(); got != 0 {
+		t.Fatalf("expected no skew before any response, got %v", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil); err != nil {
+			t.Fatalf("Post() error = %v", err)
+		}
+	}
+	if got := client.ServerTimeSkew(); got < 10*time.Minute-time.Second || got > 10*time.Minute+time.Second {
+		t.Fatalf("ServerTimeSkew() = %v, want about 10m", got)
+	}
+	if n := strings.Count(buf.String(), "Server clock differs"); n != 1 {
+		t.Fatalf("expected one skew warning, got %d in %q", n, buf.String())
+	}
+
+	// A server behind the local clock reports a negative skew
+	skew.Store(int64(-5 * time.Minute))
+	if _, err := client.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if got := client.ServerTimeSkew(); got > -5*time.Minute+time.Second || got < -5*time.Minute-time.Second {
+		t.Fatalf("ServerTimeSkew() = %v, want about -5m", got)
+	}
+}
@@ -0,0 +1,37 @@
+package kra
+
+import (
+	"log"
+	"os"
+	"regexp"
+)
+
+// Logger receives diagnostic output from the SDK
+//
+// Debug lines are only written when debug mode is enabled; warnings are
+// always written. *log.Logger satisfies this interface.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// defaultLogger writes to standard output, matching the SDK's historical debug output
+var defaultLogger Logger = log.New(os.Stdout, "", 0)
+
+// logf writes a log line to the logger, prefixed with the client name when one is set
+func logf(logger Logger, name, format string, args ...interface{}) {
+	if logger == nil {
+		logger = defaultLogger
+	}
+	if name != "" {
+		format = "[" + name + "] " + format
+	}
+	logger.Printf(format, args...)
+}
+
+// pinPattern matches KRA PINs embedded in text, in either case
+var pinPattern = regexp.MustCompile(`(?i)\b[AP]\d{9}[A-Z]\b`)
+
+// maskPINs masks every KRA PIN in s with maskSecret
+func maskPINs(s string) string {
+	return pinPattern.ReplaceAllStringFunc(s, maskSecret)
+}
@@ -1,6 +1,11 @@
 package kra
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,15 +17,49 @@ type PINVerificationResult struct {
 	Status           string                 `json:"status,omitempty"`
 	TaxpayerType     string                 `json:"taxpayer_type,omitempty"`
 	RegistrationDate string                 `json:"registration_date,omitempty"`
+	Obligations      []TaxObligation        `json:"obligations,omitempty"`
 	AdditionalData   map[string]interface{} `json:"additional_data,omitempty"`
 	VerifiedAt       time.Time              `json:"verified_at"`
 	Metadata         ResponseMetadata       `json:"metadata"`
 	RawData          map[string]interface{} `json:"raw_data,omitempty"`
+	ResultTags       map[string]string      `json:"result_tags,omitempty"`
+}
+
+// PINVerifyOptions controls how much data VerifyPINWithOptions fetches
+type PINVerifyOptions struct {
+	// IncludeObligations also fetches the taxpayer's obligations for valid PINs
+	IncludeObligations bool
+}
+
+// tagged returns a copy of the result carrying the context's result tags,
+// or the result itself when the context has none
+func (r *PINVerificationResult) tagged(ctx context.Context) *PINVerificationResult {
+	tags := resultTagsFromContext(ctx)
+	if tags == nil {
+		return r
+	}
+	copied := *r
+	copied.ResultTags = tags
+	return &copied
 }
 
 // IsActive returns true if the PIN is valid and active
 func (r *PINVerificationResult) IsActive() bool {
-	return r.IsValid && r.Status == "active"
+	return r.IsValid && NormalizeStatus(r.Status) == StatusActive
+}
+
+// IsSuspended returns true if KRA has suspended or blacklisted the PIN
+func (r *PINVerificationResult) IsSuspended() bool {
+	switch NormalizeStatus(r.Status) {
+	case StatusSuspended, StatusBlacklisted:
+		return true
+	}
+	return false
+}
+
+// IsDormant returns true if the taxpayer behind the PIN is dormant
+func (r *PINVerificationResult) IsDormant() bool {
+	return NormalizeStatus(r.Status) == StatusDormant
 }
 
 // IsCompany returns true if the taxpayer is a company
@@ -33,12 +72,65 @@ func (r *PINVerificationResult) IsIndividual() bool {
 	return r.TaxpayerType == "individual"
 }
 
+// DetectedTypeFromPIN derives the taxpayer type from the PIN structure
+//
+// KRA encodes the taxpayer category in the PIN's leading character: "A" for
+// individuals and "P" for non-individuals such as companies. Returns
+// "individual", "company", or an empty string if the type cannot be determined.
+func (r *PINVerificationResult) DetectedTypeFromPIN() string {
+	return taxpayerTypeFromPIN(r.PINNumber)
+}
+
+// TypeMismatch returns true if the type derived from the PIN disagrees with
+// the TaxpayerType returned by KRA
+//
+// The PIN only distinguishes individuals from non-individuals, so a "P" PIN
+// returned as any non-individual type, such as "partnership" or "trust", is
+// not a mismatch. Returns false when either type is unknown, so only a
+// definite conflict is reported.
+func (r *PINVerificationResult) TypeMismatch() bool {
+	detected := r.DetectedTypeFromPIN()
+	returned := strings.ToLower(strings.TrimSpace(r.TaxpayerType))
+	if detected == "" || returned == "" {
+		return false
+	}
+	return (detected == "individual") != (returned == "individual")
+}
+
+// InvalidPINReason explains why FindInvalidPINs reported a PIN
+type InvalidPINReason string
+
+// Reasons reported by FindInvalidPINs
+const (
+	// InvalidPINValidation means the PIN is malformed and was not sent to KRA
+	InvalidPINValidation InvalidPINReason = "validation"
+	// InvalidPINNotFound means KRA does not know the PIN or reports it as invalid
+	InvalidPINNotFound InvalidPINReason = "not_found"
+	// InvalidPINInactive means the PIN is valid but its status is not active
+	InvalidPINInactive InvalidPINReason = "inactive"
+)
+
+// InvalidPIN is a PIN reported by FindInvalidPINs
+type InvalidPIN struct {
+	// Index is the position of the PIN in the input
+	Index int `json:"index"`
+	// PIN is the input as given
+	PIN    string           `json:"pin"`
+	Reason InvalidPINReason `json:"reason"`
+	// Err is the validation or API error, for validation and not_found
+	Err error `json:"-"`
+	// Result is KRA's verification result, for not_found and inactive
+	// reasons that KRA reported in a successful response
+	Result *PINVerificationResult `json:"result,omitempty"`
+}
+
 // TCCVerificationResult represents the result of a TCC verification request
 type TCCVerificationResult struct {
 	TCCNumber       string                 `json:"tcc_number"`
 	IsValid         bool                   `json:"is_valid"`
 	TaxpayerName    string                 `json:"taxpayer_name,omitempty"`
 	PINNumber       string                 `json:"pin_number,omitempty"`
+	RequestedPIN    string                 `json:"requested_pin,omitempty"`
 	IssueDate       string                 `json:"issue_date,omitempty"`
 	ExpiryDate      string                 `json:"expiry_date,omitempty"`
 	IsExpired       bool                   `json:"is_expired"`
@@ -48,6 +140,7 @@ type TCCVerificationResult struct {
 	VerifiedAt      time.Time              `json:"verified_at"`
 	Metadata        ResponseMetadata       `json:"metadata"`
 	RawData         map[string]interface{} `json:"raw_data,omitempty"`
+	ResultTags      map[string]string      `json:"result_tags,omitempty"`
 }
 
 // TCCVerificationRequest represents the payload required for TCC validation
@@ -56,9 +149,34 @@ type TCCVerificationRequest struct {
 	TCCNumber string `json:"tcc_number"`
 }
 
+// tagged returns a copy of the result carrying the context's result tags,
+// or the result itself when the context has none
+func (r *TCCVerificationResult) tagged(ctx context.Context) *TCCVerificationResult {
+	tags := resultTagsFromContext(ctx)
+	if tags == nil {
+		return r
+	}
+	copied := *r
+	copied.ResultTags = tags
+	return &copied
+}
+
 // IsCurrentlyValid returns true if the TCC is valid and not expired
 func (r *TCCVerificationResult) IsCurrentlyValid() bool {
-	return r.IsValid && !r.IsExpired && r.Status == "active"
+	return r.IsValid && !r.IsExpired && NormalizeStatus(r.Status) == StatusActive
+}
+
+// PINMismatch returns true if KRA reports the certificate under a different
+// PIN than the one it was verified against
+//
+// A mismatch usually means the wrong certificate was submitted for the
+// taxpayer. Returns false when either PIN is unknown.
+func (r *TCCVerificationResult) PINMismatch() bool {
+	returned := strings.ToUpper(strings.TrimSpace(r.PINNumber))
+	if r.RequestedPIN == "" || returned == "" {
+		return false
+	}
+	return returned != r.RequestedPIN
 }
 
 // DaysUntilExpiry returns the number of days until expiry
@@ -82,6 +200,58 @@ func (r *TCCVerificationResult) IsExpiringSoon(days int) bool {
 	return daysUntilExpiry >= 0 && daysUntilExpiry <= days
 }
 
+// IsValidThroughout returns true if the TCC is currently valid and covers the
+// entire period from start to end
+//
+// The issue and expiry dates are treated as inclusive calendar days. Returns
+// false if either date cannot be parsed or if end is before start.
+func (r *TCCVerificationResult) IsValidThroughout(start, end time.Time) bool {
+	if !r.IsCurrentlyValid() || end.Before(start) {
+		return false
+	}
+
+	issueTime, err := time.Parse("2006-01-02", r.IssueDate)
+	if err != nil {
+		return false
+	}
+
+	expiryTime, err := time.Parse("2006-01-02", r.ExpiryDate)
+	if err != nil {
+		return false
+	}
+
+	// The certificate remains valid for the whole of its expiry day
+	validUntil := expiryTime.AddDate(0, 0, 1)
+
+	return !start.Before(issueTime) && end.Before(validUntil)
+}
+
+// CacheAdvisory suggests the longest time the result may be cached before
+// the certificate lapses
+//
+// The certificate is treated as valid for the whole of its expiry day, so the
+// suggestion is the time until the end of that day. It returns 0, meaning no
+// cap is needed, when the expiry date is unknown or has already passed.
+//
+// Example:
+//
+//	ttl := 30 * time.Minute
+//	if advised := result.CacheAdvisory(); advised > 0 && advised < ttl {
+//	    ttl = advised
+//	}
+func (r *TCCVerificationResult) CacheAdvisory() (suggestedTTL time.Duration) {
+	expiryTime, err := time.Parse("2006-01-02", r.ExpiryDate)
+	if err != nil {
+		return 0
+	}
+
+	remaining := time.Until(expiryTime.AddDate(0, 0, 1))
+	if remaining <= 0 {
+		return 0
+	}
+	return remaining
+}
+
 // EslipValidationResult represents the result of an e-slip validation request
 type EslipValidationResult struct {
 	EslipNumber      string                 `json:"eslip_number"`
@@ -99,21 +269,186 @@ type EslipValidationResult struct {
 	ValidatedAt      time.Time              `json:"validated_at"`
 	Metadata         ResponseMetadata       `json:"metadata"`
 	RawData          map[string]interface{} `json:"raw_data,omitempty"`
+	ResultTags       map[string]string      `json:"result_tags,omitempty"`
+
+	// PaymentMatch is set by ValidateEslipExpecting
+	PaymentMatch *PaymentMatch `json:"payment_match,omitempty"`
+}
+
+// tagged returns a copy of the result carrying the context's result tags,
+// or the result itself when the context has none
+func (r *EslipValidationResult) tagged(ctx context.Context) *EslipValidationResult {
+	tags := resultTagsFromContext(ctx)
+	if tags == nil {
+		return r
+	}
+	copied := *r
+	copied.ResultTags = tags
+	return &copied
 }
 
 // IsPaid returns true if the payment has been confirmed
 func (r *EslipValidationResult) IsPaid() bool {
-	return r.IsValid && r.Status == "paid"
+	return r.IsValid && NormalizeStatus(r.Status) == StatusPaid
 }
 
 // IsPending returns true if the payment is pending
 func (r *EslipValidationResult) IsPending() bool {
-	return r.IsValid && r.Status == "pending"
+	return r.IsValid && NormalizeStatus(r.Status) == StatusPending
 }
 
 // IsCancelled returns true if the payment was cancelled
 func (r *EslipValidationResult) IsCancelled() bool {
-	return r.Status == "cancelled"
+	return NormalizeStatus(r.Status) == StatusCancelled
+}
+
+// MatchesObligation returns true if the e-slip pays the given obligation
+// type for the given period
+//
+// Obligation types are compared case-insensitively. Periods are compared by
+// year and month when both parse in one of the formats in periodLayouts, such
+// as "2024-01", "01/2024", "202401" or "Jan 2024", and as case-insensitive text
+// otherwise.
+//
+// Example:
+//
+//	if !result.MatchesObligation("VAT", "2024-01") {
+//	    return fmt.Errorf("e-slip %s does not pay January VAT", result.EslipNumber)
+//	}
+func (r *EslipValidationResult) MatchesObligation(obligationType, period string) bool {
+	if !strings.EqualFold(strings.TrimSpace(r.ObligationType), strings.TrimSpace(obligationType)) {
+		return false
+	}
+	return samePeriod(r.ObligationPeriod, period)
+}
+
+// AmountWithin returns true if the e-slip amount is within tolerance of expected
+//
+// The bounds are inclusive, so AmountWithin(1000, 0) requires an exact match.
+// A negative tolerance is treated as zero.
+func (r *EslipValidationResult) AmountWithin(expected, tolerance float64) bool {
+	if tolerance < 0 {
+		tolerance = 0
+	}
+	// Absorb floating point error so amounts exactly at the bound match
+	return math.Abs(r.Amount-expected) <= tolerance+amountEpsilon
+}
+
+// ExpectedPayment describes the payment an e-slip is expected to cover
+//
+// Zero-valued fields are not checked, so a caller that only cares about the
+// amount can leave Currency and ObligationType empty.
+type ExpectedPayment struct {
+	// Amount is the expected amount; the e-slip must be within Tolerance of it
+	Amount float64
+	// Tolerance is the allowed difference from Amount. Default: 0 (exact)
+	Tolerance float64
+	// Currency is the expected currency code, e.g. "KES", compared case-insensitively
+	Currency string
+	// ObligationType is the expected obligation type, e.g. "VAT", compared case-insensitively
+	ObligationType string
+}
+
+// validate checks that the expectation is well-formed
+func (e ExpectedPayment) validate() error {
+	if e.Amount < 0 {
+		return NewValidationError("amount", "Expected amount cannot be negative")
+	}
+	if e.Tolerance < 0 {
+		return NewValidationError("tolerance", "Tolerance cannot be negative")
+	}
+	return nil
+}
+
+// PaymentMismatch describes one way an e-slip differs from an ExpectedPayment
+type PaymentMismatch struct {
+	// Field is "is_valid", "amount", "currency" or "obligation_type"
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// String returns a human-readable description of the mismatch
+func (m PaymentMismatch) String() string {
+	return fmt.Sprintf("%s: expected %q, got %q", m.Field, m.Expected, m.Actual)
+}
+
+// PaymentMatch is the outcome of comparing an e-slip with an ExpectedPayment
+type PaymentMatch struct {
+	Matches    bool              `json:"matches"`
+	Mismatches []PaymentMismatch `json:"mismatches,omitempty"`
+}
+
+// CheckPayment compares the e-slip with the payment it is expected to cover
+//
+// The e-slip must be valid, and every non-zero field of expected must agree
+// with it. Each disagreement is listed in Mismatches.
+//
+// Example:
+//
+//	match := result.CheckPayment(kra.ExpectedPayment{Amount: 5000, Currency: "KES"})
+//	for _, mismatch := range match.Mismatches {
+//	    log.Println(mismatch)
+//	}
+func (r *EslipValidationResult) CheckPayment(expected ExpectedPayment) PaymentMatch {
+	var mismatches []PaymentMismatch
+	if !r.IsValid {
+		mismatches = append(mismatches, PaymentMismatch{Field: "is_valid", Expected: "true", Actual: "false"})
+	}
+	if expected.Amount > 0 && !r.AmountWithin(expected.Amount, expected.Tolerance) {
+		mismatches = append(mismatches, PaymentMismatch{
+			Field:    "amount",
+			Expected: strconv.FormatFloat(expected.Amount, 'f', 2, 64),
+			Actual:   strconv.FormatFloat(r.Amount, 'f', 2, 64),
+		})
+	}
+	if currency := strings.TrimSpace(expected.Currency); currency != "" && !strings.EqualFold(currency, strings.TrimSpace(r.Currency)) {
+		mismatches = append(mismatches, PaymentMismatch{Field: "currency", Expected: currency, Actual: r.Currency})
+	}
+	if obligationType := strings.TrimSpace(expected.ObligationType); obligationType != "" &&
+		!strings.EqualFold(obligationType, strings.TrimSpace(r.ObligationType)) {
+		mismatches = append(mismatches, PaymentMismatch{Field: "obligation_type", Expected: obligationType, Actual: r.ObligationType})
+	}
+	return PaymentMatch{Matches: len(mismatches) == 0, Mismatches: mismatches}
+}
+
+// amountEpsilon absorbs floating point error in AmountWithin
+const amountEpsilon = 1e-9
+
+// periodLayouts are the obligation period formats samePeriod understands
+var periodLayouts = []string{
+	"2006-01",
+	"2006/01",
+	"01/2006",
+	"01-2006",
+	"200601",
+	"Jan 2006",
+	"January 2006",
+	"2006-01-02",
+}
+
+// samePeriod reports whether two obligation periods refer to the same month
+func samePeriod(a, b string) bool {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	if a == "" || b == "" {
+		return false
+	}
+	if ta, ok := parsePeriod(a); ok {
+		if tb, ok := parsePeriod(b); ok {
+			return ta.Year() == tb.Year() && ta.Month() == tb.Month()
+		}
+	}
+	return strings.EqualFold(a, b)
+}
+
+// parsePeriod parses an obligation period using periodLayouts
+func parsePeriod(period string) (time.Time, bool) {
+	for _, layout := range periodLayouts {
+		if t, err := time.Parse(layout, period); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
 }
 
 // NILReturnRequest represents a NIL return filing request
@@ -139,21 +474,57 @@ type NILReturnResult struct {
 	FiledAt               time.Time              `json:"filed_at"`
 	Metadata              ResponseMetadata       `json:"metadata"`
 	RawData               map[string]interface{} `json:"raw_data,omitempty"`
+	ResultTags            map[string]string      `json:"result_tags,omitempty"`
+}
+
+// tagged returns a copy of the result carrying the context's result tags,
+// or the result itself when the context has none
+func (r *NILReturnResult) tagged(ctx context.Context) *NILReturnResult {
+	tags := resultTagsFromContext(ctx)
+	if tags == nil {
+		return r
+	}
+	copied := *r
+	copied.ResultTags = tags
+	return &copied
 }
 
 // IsAccepted returns true if the filing was accepted
 func (r *NILReturnResult) IsAccepted() bool {
-	return r.Success && r.Status == "accepted"
+	return r.Success && NormalizeStatus(r.Status) == StatusAccepted
 }
 
 // IsPending returns true if the filing is pending approval
 func (r *NILReturnResult) IsPending() bool {
-	return r.Success && r.Status == "pending"
+	return r.Success && NormalizeStatus(r.Status) == StatusPending
 }
 
 // IsRejected returns true if the filing was rejected
 func (r *NILReturnResult) IsRejected() bool {
-	return !r.Success || r.Status == "rejected"
+	return !r.Success || NormalizeStatus(r.Status) == StatusRejected
+}
+
+// ObligationRegistrationRequest represents a request to register or deregister a tax obligation
+type ObligationRegistrationRequest struct {
+	PINNumber      string `json:"pin_number"`
+	ObligationCode int    `json:"obligation_code"`
+	EffectiveDate  string `json:"effective_date"`
+}
+
+// ObligationResult represents the result of an obligation registration or deregistration
+type ObligationResult struct {
+	Success         bool                   `json:"success"`
+	PINNumber       string                 `json:"pin_number,omitempty"`
+	ObligationID    string                 `json:"obligation_id,omitempty"`
+	EffectiveDate   string                 `json:"effective_date,omitempty"`
+	ReferenceNumber string                 `json:"reference_number,omitempty"`
+	Status          string                 `json:"status,omitempty"`
+	Message         string                 `json:"message,omitempty"`
+	AdditionalData  map[string]interface{} `json:"additional_data,omitempty"`
+	ProcessedAt     time.Time              `json:"processed_at"`
+	Metadata        ResponseMetadata       `json:"metadata"`
+	RawData         map[string]interface{} `json:"raw_data,omitempty"`
+	ResultTags      map[string]string      `json:"result_tags,omitempty"`
 }
 
 // TaxpayerDetails represents detailed taxpayer information
@@ -174,11 +545,41 @@ type TaxpayerDetails struct {
 	RetrievedAt      time.Time              `json:"retrieved_at"`
 	Metadata         ResponseMetadata       `json:"metadata"`
 	RawData          map[string]interface{} `json:"raw_data,omitempty"`
+	ResultTags       map[string]string      `json:"result_tags,omitempty"`
+
+	// dueSoonDays is the client's due-soon window; 0 means DefaultDueSoonDays
+	dueSoonDays int
+}
+
+// tagged returns a copy of the details carrying the context's result tags,
+// or the details themselves when the context has none
+func (t *TaxpayerDetails) tagged(ctx context.Context) *TaxpayerDetails {
+	tags := resultTagsFromContext(ctx)
+	if tags == nil {
+		return t
+	}
+	copied := *t
+	copied.ResultTags = tags
+	return &copied
 }
 
 // IsActive returns true if the taxpayer is active
 func (t *TaxpayerDetails) IsActive() bool {
-	return t.Status == "active"
+	return NormalizeStatus(t.Status) == StatusActive
+}
+
+// IsSuspended returns true if KRA has suspended or blacklisted the taxpayer
+func (t *TaxpayerDetails) IsSuspended() bool {
+	switch NormalizeStatus(t.Status) {
+	case StatusSuspended, StatusBlacklisted:
+		return true
+	}
+	return false
+}
+
+// IsDormant returns true if the taxpayer is dormant
+func (t *TaxpayerDetails) IsDormant() bool {
+	return NormalizeStatus(t.Status) == StatusDormant
 }
 
 // IsCompany returns true if the taxpayer is a company
@@ -212,6 +613,178 @@ func (t *TaxpayerDetails) HasObligation(obligationType string) bool {
 	return false
 }
 
+// ComplianceLevel is a traffic-light rollup of a taxpayer's compliance
+type ComplianceLevel string
+
+// Compliance levels returned by TaxpayerDetails.ComplianceLevel
+const (
+	ComplianceGreen ComplianceLevel = "green"
+	ComplianceAmber ComplianceLevel = "amber"
+	ComplianceRed   ComplianceLevel = "red"
+)
+
+// DefaultDueSoonDays is the default window, in days, for obligations due soon
+const DefaultDueSoonDays = 7
+
+// dueSoonWindow returns days, or DefaultDueSoonDays when it is unset
+func dueSoonWindow(days int) int {
+	if days <= 0 {
+		return DefaultDueSoonDays
+	}
+	return days
+}
+
+// ObligationsDueSoonDefault returns the obligations whose filing falls due
+// within the client's due-soon window
+//
+// The window is set with WithDueSoonWindow on the client that retrieved the
+// details and defaults to DefaultDueSoonDays.
+func (t *TaxpayerDetails) ObligationsDueSoonDefault() []TaxObligation {
+	var due []TaxObligation
+	for i := range t.Obligations {
+		if t.Obligations[i].IsFilingDueSoon(dueSoonWindow(t.dueSoonDays)) {
+			due = append(due, t.Obligations[i])
+		}
+	}
+	return due
+}
+
+// ComplianceLevel rolls the taxpayer's status and obligations up into a
+// single traffic-light level, treating filings due within the client's
+// due-soon window (DefaultDueSoonDays unless set with WithDueSoonWindow)
+// as due soon
+//
+// Example:
+//
+//	switch details.ComplianceLevel() {
+//	case kra.ComplianceRed:
+//	    fmt.Println("Not compliant")
+//	case kra.ComplianceAmber:
+//	    fmt.Println("Filing due soon")
+//	}
+func (t *TaxpayerDetails) ComplianceLevel() ComplianceLevel {
+	return t.ComplianceLevelWithin(dueSoonWindow(t.dueSoonDays))
+}
+
+// ComplianceLevelWithin is ComplianceLevel with a custom due-soon window
+//
+// The level is red when the taxpayer is not active (including suspended,
+// blacklisted and dormant taxpayers) or has an overdue filing, amber when a
+// filing falls due within dueSoonDays, and green otherwise.
+func (t *TaxpayerDetails) ComplianceLevelWithin(dueSoonDays int) ComplianceLevel {
+	if !t.IsActive() {
+		return ComplianceRed
+	}
+
+	level := ComplianceGreen
+	for i := range t.Obligations {
+		if t.Obligations[i].IsFilingOverdue() {
+			return ComplianceRed
+		}
+		if t.Obligations[i].IsFilingDueSoon(dueSoonDays) {
+			level = ComplianceAmber
+		}
+	}
+	return level
+}
+
+// FilingStatus is how up to date a taxpayer's filings for an obligation type are
+type FilingStatus string
+
+// Filing statuses returned by TaxpayerDetails.FilingStatusByType
+const (
+	FilingCurrent FilingStatus = "current"
+	FilingDueSoon FilingStatus = "due_soon"
+	FilingOverdue FilingStatus = "overdue"
+)
+
+// filingStatusRank orders filing statuses from best to worst
+var filingStatusRank = map[FilingStatus]int{
+	FilingCurrent: 0,
+	FilingDueSoon: 1,
+	FilingOverdue: 2,
+}
+
+// FilingStatusByType classifies the filing status of each active obligation type
+//
+// Filings due within the client's due-soon window (DefaultDueSoonDays unless
+// set with WithDueSoonWindow) are FilingDueSoon, past-due filings are
+// FilingOverdue, and everything else is FilingCurrent. When several
+// obligations share a type, the worst status wins. Inactive obligations and
+// obligations without a type are left out.
+//
+// Example:
+//
+//	for obligationType, status := range details.FilingStatusByType() {
+//	    fmt.Printf("%s: %s\n", obligationType, status)
+//	}
+func (t *TaxpayerDetails) FilingStatusByType() map[string]FilingStatus {
+	window := dueSoonWindow(t.dueSoonDays)
+	statuses := make(map[string]FilingStatus)
+	for i := range t.Obligations {
+		obligation := &t.Obligations[i]
+		obligationType := strings.TrimSpace(obligation.ObligationType)
+		if !obligation.IsActive || obligationType == "" {
+			continue
+		}
+
+		status := FilingCurrent
+		switch {
+		case obligation.IsFilingOverdue():
+			status = FilingOverdue
+		case obligation.IsFilingDueSoon(window):
+			status = FilingDueSoon
+		}
+
+		if current, ok := statuses[obligationType]; !ok || filingStatusRank[status] > filingStatusRank[current] {
+			statuses[obligationType] = status
+		}
+	}
+	return statuses
+}
+
+// FilingRecord represents a single filing in an obligation's history
+type FilingRecord struct {
+	FilingID              string                 `json:"filing_id,omitempty"`
+	ObligationID          string                 `json:"obligation_id"`
+	Period                string                 `json:"period"`
+	FilingDate            string                 `json:"filing_date,omitempty"`
+	DueDate               string                 `json:"due_date,omitempty"`
+	Status                string                 `json:"status,omitempty"`
+	ReturnType            string                 `json:"return_type,omitempty"`
+	AcknowledgementNumber string                 `json:"acknowledgement_number,omitempty"`
+	TaxAmount             float64                `json:"tax_amount"`
+	AdditionalData        map[string]interface{} `json:"additional_data,omitempty"`
+}
+
+// IsLate returns true if the record was filed after its due date
+func (r *FilingRecord) IsLate() bool {
+	if r.FilingDate == "" || r.DueDate == "" {
+		return false
+	}
+
+	filed, err := time.Parse("2006-01-02", r.FilingDate)
+	if err != nil {
+		return false
+	}
+	due, err := time.Parse("2006-01-02", r.DueDate)
+	if err != nil {
+		return false
+	}
+
+	return filed.After(due)
+}
+
+// NILEligibleObligationTypes lists the obligation types that can be filed
+// as NIL returns. Matching in IsNILEligible is case-insensitive.
+var NILEligibleObligationTypes = []string{
+	"VAT",
+	"PAYE",
+	"TOT",
+	"Excise Duty",
+	"MRI",
+}
+
 // TaxObligation represents a tax obligation
 type TaxObligation struct {
 	ObligationID     string                 `json:"obligation_id"`
@@ -225,6 +798,9 @@ type TaxObligation struct {
 	NextFilingDate   string                 `json:"next_filing_date,omitempty"`
 	IsActive         bool                   `json:"is_active"`
 	AdditionalData   map[string]interface{} `json:"additional_data,omitempty"`
+
+	// dueSoonDays is the client's due-soon window; 0 means DefaultDueSoonDays
+	dueSoonDays int
 }
 
 // HasEnded returns true if the obligation has ended
@@ -256,6 +832,54 @@ func (o *TaxObligation) IsFilingDueSoon(days int) bool {
 	return daysUntil >= 0 && daysUntil <= days
 }
 
+// coversPeriod returns true if the obligation's effective and end dates span the given month
+//
+// Missing or unparseable dates are treated as unbounded.
+func (o *TaxObligation) coversPeriod(year int, month time.Month) bool {
+	periodStart := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := periodStart.AddDate(0, 1, 0)
+
+	if effective, err := time.Parse("2006-01-02", o.EffectiveDate); err == nil && !effective.Before(periodEnd) {
+		return false
+	}
+	if end, err := time.Parse("2006-01-02", o.EndDate); err == nil && end.Before(periodStart) {
+		return false
+	}
+	return true
+}
+
+// IsNILEligible returns true if the obligation can be filed as a NIL return
+//
+// The obligation must be active, its type must appear in
+// NILEligibleObligationTypes, and it must be filed monthly (or have no
+// frequency reported), since NIL returns are submitted per month.
+func (o *TaxObligation) IsNILEligible() bool {
+	if !o.IsActive || NormalizeStatus(o.Status) == StatusInactive {
+		return false
+	}
+
+	if strings.TrimSpace(o.Frequency) != "" && o.ParsedFrequency() != FrequencyMonthly {
+		return false
+	}
+
+	obligationType := strings.TrimSpace(o.ObligationType)
+	for _, eligible := range NILEligibleObligationTypes {
+		if strings.EqualFold(obligationType, eligible) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFilingDueSoonDefault returns true if filing is due within the client's
+// due-soon window
+//
+// The window is set with WithDueSoonWindow on the client that retrieved the
+// obligation and defaults to DefaultDueSoonDays.
+func (o *TaxObligation) IsFilingDueSoonDefault() bool {
+	return o.IsFilingDueSoon(dueSoonWindow(o.dueSoonDays))
+}
+
 // IsFilingOverdue returns true if filing is overdue
 func (o *TaxObligation) IsFilingOverdue() bool {
 	if o.NextFilingDate == "" || !o.IsActive {
@@ -70,6 +70,66 @@ func TestPINVerificationResult_IsIndividual(t *testing.T) {
 	}
 }
 
+func TestPINVerificationResult_DetectedTypeFromPIN(t *testing.T) {
+	tests := []struct {
+		name         string
+		pin          string
+		taxpayerType string
+		wantType     string
+		wantMismatch bool
+	}{
+		{"company matches", "P051234567A", "company", "company", false},
+		{"company mismatch", "P051234567A", "individual", "company", true},
+		{"individual matches", "A012345678B", "Individual", "individual", false},
+		{"individual mismatch", "a012345678b", "company", "individual", true},
+		{"other non-individual type", "P051234567A", "Partnership", "company", false},
+		{"unknown returned type", "P051234567A", "", "company", false},
+		{"unknown PIN prefix", "X051234567A", "company", "", false},
+		{"empty PIN", "", "company", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &PINVerificationResult{PINNumber: tt.pin, TaxpayerType: tt.taxpayerType}
+			if got := result.DetectedTypeFromPIN(); got != tt.wantType {
+				t.Errorf("DetectedTypeFromPIN() = %q, want %q", got, tt.wantType)
+			}
+			if got := result.TypeMismatch(); got != tt.wantMismatch {
+				t.Errorf("TypeMismatch() = %v, want %v", got, tt.wantMismatch)
+			}
+		})
+	}
+}
+
+func TestPINVerificationResult_MatchesName(t *testing.T) {
+	tests := []struct {
+		name      string
+		recorded  string
+		submitted string
+		threshold float64
+		want      bool
+	}{
+		{"exact", "JOHN KAMAU DOE", "JOHN KAMAU DOE", 1, true},
+		{"case and punctuation", "JOHN KAMAU DOE", "john  kamau-doe.", 1, true},
+		{"reordered", "JOHN KAMAU DOE", "Doe, John Kamau", 1, true},
+		{"typo above threshold", "JOHN KAMAU DOE", "Jon Kamau Doe", 0.9, true},
+		{"typo below strict threshold", "JOHN KAMAU DOE", "Jon Kamau Doe", 1, false},
+		{"mismatched", "JOHN KAMAU DOE", "Mary Wanjiku Otieno", 0.5, false},
+		{"empty submitted", "JOHN KAMAU DOE", "  ", 0, false},
+		{"no recorded name", "", "John Doe", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &PINVerificationResult{TaxpayerName: tt.recorded}
+			if got := result.MatchesName(tt.submitted, tt.threshold); got != tt.want {
+				t.Errorf("MatchesName(%q, %v) = %v, want %v (similarity %.2f)",
+					tt.submitted, tt.threshold, got, tt.want, result.NameSimilarity(tt.submitted))
+			}
+		})
+	}
+}
+
 func TestTCCVerificationResult_IsCurrentlyValid(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -162,6 +222,56 @@ func TestTCCVerificationResult_IsExpiringSoon(t *testing.T) {
 	}
 }
 
+func TestTCCVerificationResult_IsValidThroughout(t *testing.T) {
+	day := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatalf("bad test date %s: %v", s, err)
+		}
+		return d
+	}
+
+	result := &TCCVerificationResult{
+		IsValid:    true,
+		Status:     "active",
+		IssueDate:  "2025-01-01",
+		ExpiryDate: "2025-12-31",
+	}
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  bool
+	}{
+		{"full coverage", day("2025-02-01"), day("2025-11-30"), true},
+		{"matches certificate window", day("2025-01-01"), day("2025-12-31").Add(23 * time.Hour), true},
+		{"starts before issue", day("2024-12-15"), day("2025-06-30"), false},
+		{"ends after expiry", day("2025-06-01"), day("2026-01-15"), false},
+		{"end before start", day("2025-06-01"), day("2025-05-01"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := result.IsValidThroughout(tt.start, tt.end); got != tt.want {
+				t.Errorf("IsValidThroughout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	unparseable := *result
+	unparseable.ExpiryDate = "31/12/2025"
+	if unparseable.IsValidThroughout(day("2025-02-01"), day("2025-03-01")) {
+		t.Error("Expected IsValidThroughout() to return false for unparseable expiry date")
+	}
+
+	expired := *result
+	expired.IsExpired = true
+	if expired.IsValidThroughout(day("2025-02-01"), day("2025-03-01")) {
+		t.Error("Expected IsValidThroughout() to return false for expired certificate")
+	}
+}
+
 func TestTaxpayerDetailsHelpers(t *testing.T) {
 	details := &TaxpayerDetails{
 		TaxpayerType: "company",
@@ -229,6 +339,67 @@ func TestEslipValidationResult_IsCancelled(t *testing.T) {
 	}
 }
 
+func TestEslipValidationResult_MatchesObligation(t *testing.T) {
+	result := &EslipValidationResult{ObligationType: "VAT", ObligationPeriod: "2024-01"}
+
+	tests := []struct {
+		name           string
+		obligationType string
+		period         string
+		want           bool
+	}{
+		{"exact", "VAT", "2024-01", true},
+		{"type case-insensitive", " vat ", "2024-01", true},
+		{"slash period", "VAT", "01/2024", true},
+		{"compact period", "VAT", "202401", true},
+		{"month name period", "VAT", "January 2024", true},
+		{"different type", "PAYE", "2024-01", false},
+		{"different month", "VAT", "2024-02", false},
+		{"different year", "VAT", "01/2023", false},
+		{"empty period", "VAT", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := result.MatchesObligation(tt.obligationType, tt.period); got != tt.want {
+				t.Errorf("MatchesObligation(%q, %q) = %v, want %v", tt.obligationType, tt.period, got, tt.want)
+			}
+		})
+	}
+
+	// Unparseable periods fall back to a text comparison
+	result.ObligationPeriod = "Q1 FY2024"
+	if !result.MatchesObligation("VAT", "q1 fy2024") {
+		t.Error("expected free-text periods to match case-insensitively")
+	}
+}
+
+func TestEslipValidationResult_AmountWithin(t *testing.T) {
+	result := &EslipValidationResult{Amount: 1000.10}
+
+	tests := []struct {
+		name      string
+		expected  float64
+		tolerance float64
+		want      bool
+	}{
+		{"exact with zero tolerance", 1000.10, 0, true},
+		{"at upper bound", 1000.00, 0.10, true},
+		{"at lower bound", 1000.20, 0.10, true},
+		{"just outside", 1000.00, 0.09, false},
+		{"negative tolerance treated as zero", 1000.10, -1, true},
+		{"negative tolerance with difference", 1000.00, -1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := result.AmountWithin(tt.expected, tt.tolerance); got != tt.want {
+				t.Errorf("AmountWithin(%v, %v) = %v, want %v", tt.expected, tt.tolerance, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestNILReturnResult_IsAccepted(t *testing.T) {
 	result := &NILReturnResult{Success: true, Status: "accepted"}
 	if !result.IsAccepted() {
@@ -415,3 +586,338 @@ func TestTaxObligation_IsFilingOverdue(t *testing.T) {
 		t.Error("Expected IsFilingOverdue() to return false for inactive obligation")
 	}
 }
+
+func TestTaxpayerDetailsComplianceLevel(t *testing.T) {
+	date := func(days int) string {
+		return time.Now().AddDate(0, 0, days).Format("2006-01-02")
+	}
+
+	tests := []struct {
+		name    string
+		details TaxpayerDetails
+		want    ComplianceLevel
+	}{
+		{
+			name: "active with nothing due",
+			details: TaxpayerDetails{Status: "active", Obligations: []TaxObligation{
+				{ObligationType: "VAT", IsActive: true, NextFilingDate: date(30)},
+				{ObligationType: "PAYE", IsActive: true},
+			}},
+			want: ComplianceGreen,
+		},
+		{
+			name: "filing due soon",
+			details: TaxpayerDetails{Status: "Active", Obligations: []TaxObligation{
+				{ObligationType: "VAT", IsActive: true, NextFilingDate: date(30)},
+				{ObligationType: "PAYE", IsActive: true, NextFilingDate: date(4)},
+			}},
+			want: ComplianceAmber,
+		},
+		{
+			name: "overdue filing",
+			details: TaxpayerDetails{Status: "active", Obligations: []TaxObligation{
+				{ObligationType: "PAYE", IsActive: true, NextFilingDate: date(4)},
+				{ObligationType: "VAT", IsActive: true, NextFilingDate: date(-3)},
+			}},
+			want: ComplianceRed,
+		},
+		{
+			name:    "suspended",
+			details: TaxpayerDetails{Status: "suspended"},
+			want:    ComplianceRed,
+		},
+		{
+			name:    "inactive",
+			details: TaxpayerDetails{Status: "inactive"},
+			want:    ComplianceRed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.details.ComplianceLevel(); got != tt.want {
+				t.Errorf("ComplianceLevel() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	dueInTwoWeeks := TaxpayerDetails{Status: "active", Obligations: []TaxObligation{
+		{ObligationType: "VAT", IsActive: true, NextFilingDate: date(14)},
+	}}
+	if got := dueInTwoWeeks.ComplianceLevelWithin(30); got != ComplianceAmber {
+		t.Errorf("ComplianceLevelWithin(30) = %s, want amber", got)
+	}
+	if got := dueInTwoWeeks.ComplianceLevel(); got != ComplianceGreen {
+		t.Errorf("ComplianceLevel() = %s, want green", got)
+	}
+}
+
+func TestTaxpayerDetailsFilingStatusByType(t *testing.T) {
+	date := func(days int) string {
+		return time.Now().AddDate(0, 0, days).Format("2006-01-02")
+	}
+
+	details := TaxpayerDetails{Status: "active", Obligations: []TaxObligation{
+		{ObligationType: "VAT", IsActive: true, NextFilingDate: date(30)},
+		{ObligationType: "PAYE", IsActive: true, NextFilingDate: date(3)},
+		{ObligationType: "Income Tax", IsActive: true, NextFilingDate: date(-2)},
+		{ObligationType: "TOT", IsActive: true},
+		// The worst status of a type wins
+		{ObligationType: "MRI", IsActive: true, NextFilingDate: date(3)},
+		{ObligationType: "MRI", IsActive: true, NextFilingDate: date(-1)},
+		// Inactive and untyped obligations are left out
+		{ObligationType: "Excise Duty", IsActive: false, NextFilingDate: date(-5)},
+		{ObligationType: " ", IsActive: true, NextFilingDate: date(-5)},
+	}}
+
+	want := map[string]FilingStatus{
+		"VAT":        FilingCurrent,
+		"PAYE":       FilingDueSoon,
+		"Income Tax": FilingOverdue,
+		"TOT":        FilingCurrent,
+		"MRI":        FilingOverdue,
+	}
+
+	got := details.FilingStatusByType()
+	if len(got) != len(want) {
+		t.Fatalf("FilingStatusByType() = %v, want %v", got, want)
+	}
+	for obligationType, status := range want {
+		if got[obligationType] != status {
+			t.Errorf("FilingStatusByType()[%q] = %q, want %q", obligationType, got[obligationType], status)
+		}
+	}
+
+	// The client's due-soon window applies
+	details.dueSoonDays = 1
+	if got := details.FilingStatusByType()["PAYE"]; got != FilingCurrent {
+		t.Errorf("with a 1-day window PAYE = %q, want %q", got, FilingCurrent)
+	}
+}
+
+func TestParseObligationFrequency(t *testing.T) {
+	tests := map[string]ObligationFrequency{
+		"Monthly":   FrequencyMonthly,
+		"MONTHLY":   FrequencyMonthly,
+		" m ":       FrequencyMonthly,
+		"Quarterly": FrequencyQuarterly,
+		"Q":         FrequencyQuarterly,
+		"annual":    FrequencyAnnual,
+		"Yearly":    FrequencyAnnual,
+		"Y":         FrequencyAnnual,
+		"":          FrequencyUnknown,
+		"weekly":    FrequencyUnknown,
+	}
+	for raw, want := range tests {
+		if got := ParseObligationFrequency(raw); got != want {
+			t.Errorf("ParseObligationFrequency(%q) = %v, want %v", raw, got, want)
+		}
+	}
+
+	obligation := &TaxObligation{Frequency: "QUARTERLY"}
+	if obligation.ParsedFrequency() != FrequencyQuarterly {
+		t.Fatalf("ParsedFrequency() = %v, want quarterly", obligation.ParsedFrequency())
+	}
+	if FrequencyMonthly.String() != "monthly" || ObligationFrequency(99).String() != "unknown" {
+		t.Fatalf("unexpected String() values: %s, %s", FrequencyMonthly, ObligationFrequency(99))
+	}
+
+	nilEligible := &TaxObligation{ObligationType: "VAT", Status: "active", IsActive: true, Frequency: "M"}
+	if !nilEligible.IsNILEligible() {
+		t.Fatal("expected single-letter monthly frequency to be NIL eligible")
+	}
+}
+
+func TestNormalizeStatus(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want StatusEnum
+	}{
+		{"active", StatusActive},
+		{"ACTIVE", StatusActive},
+		{" Active ", StatusActive},
+		{"A", StatusActive},
+		{"a", StatusActive},
+		{"I", StatusInactive},
+		{"Inactive", StatusInactive},
+		{"PAID", StatusPaid},
+		{"Canceled", StatusCancelled},
+		{"something-else", StatusUnknown},
+		{"", StatusUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeStatus(tt.raw); got != tt.want {
+			t.Errorf("NormalizeStatus(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeStatusString(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"active", "active"},
+		{"ACTIVE", "active"},
+		{"  Pending\t", "pending"},
+		{"\nRejected ", "rejected"},
+		{"Black-Listed", "black-listed"},
+		{"Canceled", "canceled"},
+		{"A", "a"},
+		{"Under Review", "under review"},
+		{"   ", ""},
+	}
+
+	for _, tt := range tests {
+		got := NormalizeStatusString(tt.raw)
+		if got != tt.want {
+			t.Errorf("NormalizeStatusString(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+		if NormalizeStatus(got) != NormalizeStatus(tt.raw) {
+			t.Errorf("NormalizeStatus disagrees for %q and its normalized form %q", tt.raw, got)
+		}
+	}
+}
+
+func TestStatusHelpersIgnoreCase(t *testing.T) {
+	for _, status := range []string{"active", "ACTIVE", "Active", "A"} {
+		pin := &PINVerificationResult{IsValid: true, Status: status}
+		if !pin.IsActive() {
+			t.Errorf("PINVerificationResult{Status: %q}.IsActive() = false, want true", status)
+		}
+		tcc := &TCCVerificationResult{IsValid: true, Status: status}
+		if !tcc.IsCurrentlyValid() {
+			t.Errorf("TCCVerificationResult{Status: %q}.IsCurrentlyValid() = false, want true", status)
+		}
+	}
+
+	eslip := &EslipValidationResult{IsValid: true, Status: "PAID"}
+	if !eslip.IsPaid() {
+		t.Error("expected uppercase PAID e-slip to be paid")
+	}
+	nilReturn := &NILReturnResult{Success: true, Status: "Accepted"}
+	if !nilReturn.IsAccepted() {
+		t.Error("expected mixed-case Accepted NIL return to be accepted")
+	}
+}
+
+func TestInferValidityFromSingleLetterStatus(t *testing.T) {
+	if !inferValidityFromStatus("A") {
+		t.Error("expected status A to be valid")
+	}
+	if inferValidityFromStatus("I") {
+		t.Error("expected status I to be invalid")
+	}
+}
+
+func TestTaxObligation_IsNILEligible(t *testing.T) {
+	tests := []struct {
+		name       string
+		obligation TaxObligation
+		want       bool
+	}{
+		{"active VAT", TaxObligation{ObligationType: "VAT", IsActive: true}, true},
+		{"lowercase PAYE monthly", TaxObligation{ObligationType: "paye", Frequency: "Monthly", IsActive: true}, true},
+		{"turnover tax", TaxObligation{ObligationType: "TOT", IsActive: true}, true},
+		{"excise duty", TaxObligation{ObligationType: "excise duty", IsActive: true}, true},
+		{"inactive VAT", TaxObligation{ObligationType: "VAT", IsActive: false}, false},
+		{"VAT with inactive status", TaxObligation{ObligationType: "VAT", Status: "I", IsActive: true}, false},
+		{"annual VAT", TaxObligation{ObligationType: "VAT", Frequency: "Annual", IsActive: true}, false},
+		{"income tax", TaxObligation{ObligationType: "Income Tax - Company", IsActive: true}, false},
+		{"missing type", TaxObligation{IsActive: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.obligation.IsNILEligible(); got != tt.want {
+				t.Errorf("IsNILEligible() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlaggedTaxpayerStatuses(t *testing.T) {
+	tests := []struct {
+		status    string
+		valid     bool
+		suspended bool
+		dormant   bool
+	}{
+		{"SUSPENDED", false, true, false},
+		{"Blacklisted", false, true, false},
+		{"black-listed", false, true, false},
+		{"Dormant", true, false, true},
+		{"active", true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := inferValidityFromStatus(tt.status); got != tt.valid {
+				t.Errorf("inferValidityFromStatus(%q) = %v, want %v", tt.status, got, tt.valid)
+			}
+
+			pin := &PINVerificationResult{Status: tt.status}
+			if pin.IsSuspended() != tt.suspended || pin.IsDormant() != tt.dormant {
+				t.Errorf("PINVerificationResult{Status: %q}: IsSuspended=%v IsDormant=%v", tt.status, pin.IsSuspended(), pin.IsDormant())
+			}
+
+			details := &TaxpayerDetails{Status: tt.status}
+			if details.IsSuspended() != tt.suspended || details.IsDormant() != tt.dormant {
+				t.Errorf("TaxpayerDetails{Status: %q}: IsSuspended=%v IsDormant=%v", tt.status, details.IsSuspended(), details.IsDormant())
+			}
+		})
+	}
+}
+
+func TestTCCVerificationResult_CacheAdvisory(t *testing.T) {
+	today := time.Now().UTC().Format("2006-01-02")
+	nextYear := time.Now().AddDate(1, 0, 0).Format("2006-01-02")
+	lastWeek := time.Now().AddDate(0, 0, -7).Format("2006-01-02")
+
+	near := &TCCVerificationResult{ExpiryDate: today}
+	if got := near.CacheAdvisory(); got <= 0 || got > 24*time.Hour {
+		t.Errorf("CacheAdvisory() expiring today = %v, want within (0, 24h]", got)
+	}
+
+	far := &TCCVerificationResult{ExpiryDate: nextYear}
+	if got := far.CacheAdvisory(); got < 300*24*time.Hour {
+		t.Errorf("CacheAdvisory() expiring next year = %v, want about a year", got)
+	}
+
+	for _, expiry := range []string{lastWeek, "", "not-a-date"} {
+		if got := (&TCCVerificationResult{ExpiryDate: expiry}).CacheAdvisory(); got != 0 {
+			t.Errorf("CacheAdvisory() with expiry %q = %v, want 0", expiry, got)
+		}
+	}
+}
+
+func TestTaxpayerDetailsParsedPostalAddress(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Address
+	}{
+		{"P.O. Box 12345-00100, Nairobi", Address{POBox: "12345", PostalCode: "00100", Town: "Nairobi"}},
+		{"P.O BOX 12345 - 00100 NAIROBI", Address{POBox: "12345", PostalCode: "00100", Town: "NAIROBI"}},
+		{"PO Box 4567, 20100 Nakuru", Address{POBox: "4567", PostalCode: "20100", Town: "Nakuru"}},
+		{"p. o. box 90 80100 Mombasa GPO", Address{POBox: "90", PostalCode: "80100", Town: "Mombasa GPO"}},
+		{"Box 321 Kisumu", Address{POBox: "321", Town: "Kisumu"}},
+		{"  12345-00100 Nairobi.  ", Address{POBox: "12345", PostalCode: "00100", Town: "Nairobi"}},
+		{"P.O. Box 555-00200", Address{POBox: "555", PostalCode: "00200"}},
+		{"Moi Avenue, Nairobi", Address{}},
+		{"", Address{}},
+	}
+
+	for _, tt := range tests {
+		details := &TaxpayerDetails{PostalAddress: tt.raw}
+		got := details.ParsedPostalAddress()
+		want := tt.want
+		want.Raw = tt.raw
+		if got != want {
+			t.Errorf("ParsedPostalAddress(%q) = %+v, want %+v", tt.raw, got, want)
+		}
+		if got.Parsed() != (tt.want.POBox != "") {
+			t.Errorf("ParsedPostalAddress(%q).Parsed() = %v", tt.raw, got.Parsed())
+		}
+	}
+}
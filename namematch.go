@@ -0,0 +1,78 @@
+package kra
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// NameSimilarity scores how closely name matches the TaxpayerName returned by KRA
+//
+// Both names are compared case-, whitespace- and punctuation-insensitively and
+// with their words sorted, so "DOE, John" and "john doe" score 1. The score is
+// a Levenshtein ratio between 0 (nothing in common) and 1 (identical). Returns
+// 0 when either name is empty.
+func (r *PINVerificationResult) NameSimilarity(name string) float64 {
+	return nameSimilarity(r.TaxpayerName, name)
+}
+
+// MatchesName returns true if name is similar enough to the TaxpayerName
+// returned by KRA
+//
+// threshold is the minimum NameSimilarity score, between 0 and 1. A threshold
+// of 1 only accepts names that are identical after normalization.
+//
+// Example:
+//
+//	if !result.MatchesName(applicant.FullName, 0.85) {
+//	    return errors.New("name does not match KRA records")
+//	}
+func (r *PINVerificationResult) MatchesName(name string, threshold float64) bool {
+	if r.TaxpayerName == "" || strings.TrimSpace(name) == "" {
+		return false
+	}
+	return r.NameSimilarity(name) >= threshold
+}
+
+// nameSimilarity returns the Levenshtein ratio of two normalized names
+func nameSimilarity(a, b string) float64 {
+	na, nb := normalizeName(a), normalizeName(b)
+	if len(na) == 0 || len(nb) == 0 {
+		return 0
+	}
+	longest := len(na)
+	if len(nb) > longest {
+		longest = len(nb)
+	}
+	return 1 - float64(levenshtein(na, nb))/float64(longest)
+}
+
+// normalizeName lowercases a name, drops punctuation and sorts its words
+func normalizeName(name string) []rune {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	sort.Strings(words)
+	return []rune(strings.Join(words, " "))
+}
+
+// levenshtein returns the edit distance between two rune slices
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
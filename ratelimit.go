@@ -1,11 +1,30 @@
 package kra
 
 import (
-	"fmt"
+	"context"
+	"math"
 	"sync"
 	"time"
 )
 
+// RateLimiterBackend is a token bucket the client draws request tokens from
+//
+// The in-memory RateLimiter is the default backend and limits a single
+// process. Implement this interface on top of shared storage, such as a Redis
+// token bucket, to enforce one quota across several processes or pods, and
+// install it with WithDistributedRateLimiter. Implementations must be
+// goroutine-safe and should honour ctx in any storage round trip.
+//
+// An error from either method fails the request with that error wrapped, so a
+// backend that cannot reach its storage fails closed. To fail open instead,
+// return true and a nil error from TryAcquire when storage is unavailable.
+type RateLimiterBackend interface {
+	// TryAcquire takes a token if one is available, without waiting for one
+	TryAcquire(ctx context.Context) (bool, error)
+	// EstimateWaitTime reports how long until a token is likely to be available
+	EstimateWaitTime(ctx context.Context) (time.Duration, error)
+}
+
 // RateLimiter implements a token bucket rate limiter
 //
 // The rate limiter is goroutine-safe and uses the token bucket algorithm
@@ -19,8 +38,16 @@ type RateLimiter struct {
 	enabled      bool
 	debug        bool
 	windowPeriod time.Duration
+	name         string
+	logger       Logger
 }
 
+// Bounds for the per-token refill interval, see timePerToken
+const (
+	minTokenInterval = time.Microsecond
+	maxTokenInterval = 24 * time.Hour
+)
+
 // NewRateLimiter creates a new rate limiter
 //
 // Parameters:
@@ -52,31 +79,50 @@ func NewRateLimiter(maxRequests int, window time.Duration, enabled bool, debug b
 
 // Wait blocks until a token is available
 //
-// This method will block the current goroutine until a token becomes available.
-// Use this method when you want to ensure the request waits for rate limiting.
+// This method will block the current goroutine until a token becomes available,
+// with no way to give up. Prefer WaitContext, which returns when the context is
+// cancelled.
 //
 // Example:
 //
 //	limiter.Wait()
 //	// Proceed with API request
 func (rl *RateLimiter) Wait() {
+	_ = rl.WaitContext(context.Background())
+}
+
+// WaitContext blocks until a token is available or the context is done
+//
+// It returns nil once a token has been acquired and the context error if the
+// context is cancelled first, in which case no token is consumed.
+//
+// Example:
+//
+//	if err := limiter.WaitContext(ctx); err != nil {
+//	    return err
+//	}
+//	// Proceed with API request
+func (rl *RateLimiter) WaitContext(ctx context.Context) error {
 	if !rl.enabled {
-		return
+		return nil
 	}
 
 	for {
 		if rl.tryAcquire() {
-			return
+			return nil
 		}
 
 		// Calculate how long to wait for next token (inline to avoid deadlock)
-		timePerToken := time.Second / time.Duration(rl.refillRate)
-		waitDuration := timePerToken + (10 * time.Millisecond)
+		waitDuration := rl.timePerToken() + (10 * time.Millisecond)
 
-		if rl.debug {
-			fmt.Printf("[RateLimit] WAIT: Sleeping for %v\n", waitDuration)
+		rl.debugf("[RateLimit] WAIT: Sleeping for %v\n", waitDuration)
+		timer := time.NewTimer(waitDuration)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
 		}
-		time.Sleep(waitDuration)
 	}
 }
 
@@ -110,15 +156,11 @@ func (rl *RateLimiter) tryAcquire() bool {
 
 	if rl.tokens > 0 {
 		rl.tokens--
-		if rl.debug {
-			fmt.Printf("[RateLimit] ACQUIRE: Token acquired (remaining: %d/%d)\n", rl.tokens, rl.maxTokens)
-		}
+		rl.debugf("[RateLimit] ACQUIRE: Token acquired (remaining: %d/%d)\n", rl.tokens, rl.maxTokens)
 		return true
 	}
 
-	if rl.debug {
-		fmt.Printf("[RateLimit] EXCEED: No tokens available (0/%d)\n", rl.maxTokens)
-	}
+	rl.debugf("[RateLimit] EXCEED: No tokens available (0/%d)\n", rl.maxTokens)
 	return false
 }
 
@@ -126,7 +168,13 @@ func (rl *RateLimiter) tryAcquire() bool {
 func (rl *RateLimiter) refill() {
 	now := time.Now()
 	elapsed := now.Sub(rl.lastRefill).Seconds()
-	tokensToAdd := int(elapsed * rl.refillRate)
+
+	// Cap in floating point before converting, so a huge refill rate cannot
+	// overflow the int conversion
+	tokensToAdd := rl.maxTokens
+	if earned := elapsed * rl.refillRate; earned < float64(rl.maxTokens) {
+		tokensToAdd = int(earned)
+	}
 
 	if tokensToAdd > 0 {
 		rl.tokens += tokensToAdd
@@ -135,9 +183,7 @@ func (rl *RateLimiter) refill() {
 		}
 		rl.lastRefill = now
 
-		if rl.debug && tokensToAdd > 0 {
-			fmt.Printf("[RateLimit] REFILL: Added %d tokens (now: %d/%d)\n", tokensToAdd, rl.tokens, rl.maxTokens)
-		}
+		rl.debugf("[RateLimit] REFILL: Added %d tokens (now: %d/%d)\n", tokensToAdd, rl.tokens, rl.maxTokens)
 	}
 }
 
@@ -170,9 +216,7 @@ func (rl *RateLimiter) Reset() {
 	rl.tokens = rl.maxTokens
 	rl.lastRefill = time.Now()
 
-	if rl.debug {
-		fmt.Printf("[RateLimit] RESET: Tokens reset to %d/%d\n", rl.tokens, rl.maxTokens)
-	}
+	rl.debugf("[RateLimit] RESET: Tokens reset to %d/%d\n", rl.tokens, rl.maxTokens)
 }
 
 // EstimateWaitTime estimates how long it would take to acquire a token
@@ -192,9 +236,44 @@ func (rl *RateLimiter) EstimateWaitTime() time.Duration {
 		return 0
 	}
 
-	// Time to generate one token (inline to avoid deadlock)
-	timePerToken := time.Second / time.Duration(rl.refillRate)
-
 	// Add a small buffer to ensure token is available
-	return timePerToken + (10 * time.Millisecond)
+	return rl.timePerToken() + (10 * time.Millisecond)
+}
+
+// timePerToken returns how long the bucket takes to generate one token
+//
+// The result is computed in floating point so refill rates below one token
+// per second work, and is clamped to [minTokenInterval, maxTokenInterval] so
+// extreme configurations neither truncate to zero nor overflow time.Duration.
+func (rl *RateLimiter) timePerToken() time.Duration {
+	perToken := float64(time.Second) / rl.refillRate
+	switch {
+	case math.IsNaN(perToken) || perToken > float64(maxTokenInterval):
+		return maxTokenInterval
+	case perToken < float64(minTokenInterval):
+		return minTokenInterval
+	}
+	return time.Duration(perToken)
+}
+
+// debugf writes a debug log line when debug mode is enabled
+func (rl *RateLimiter) debugf(format string, args ...interface{}) {
+	if rl.debug {
+		logf(rl.logger, rl.name, format, args...)
+	}
+}
+
+// localBackend adapts the in-memory RateLimiter to RateLimiterBackend
+type localBackend struct {
+	limiter *RateLimiter
+}
+
+// TryAcquire takes a token from the in-memory bucket; it never fails
+func (b localBackend) TryAcquire(ctx context.Context) (bool, error) {
+	return b.limiter.TryAcquire(), nil
+}
+
+// EstimateWaitTime reports the in-memory bucket's wait; it never fails
+func (b localBackend) EstimateWaitTime(ctx context.Context) (time.Duration, error) {
+	return b.limiter.EstimateWaitTime(), nil
 }
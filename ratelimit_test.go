@@ -1,6 +1,11 @@
 package kra
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -119,6 +124,40 @@ func TestRateLimiter_EstimateWaitTime(t *testing.T) {
 	}
 }
 
+func TestRateLimiter_EstimateWaitTimeExtremeRates(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxRequests int
+		window      time.Duration
+		maxWait     time.Duration
+	}{
+		{"one per hour", 1, time.Hour, time.Hour + time.Second},
+		{"ten thousand per second", 10000, time.Second, 20 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := NewRateLimiter(tt.maxRequests, tt.window, true, false)
+			for rl.TryAcquire() {
+			}
+
+			wait := rl.EstimateWaitTime()
+			if wait <= 0 || wait > tt.maxWait {
+				t.Errorf("EstimateWaitTime() = %v, want in (0, %v]", wait, tt.maxWait)
+			}
+		})
+	}
+
+	// Rates that would truncate to zero or overflow time.Duration are clamped
+	for _, rate := range []float64{1e15, 1e-15, 0} {
+		rl := &RateLimiter{refillRate: rate}
+		if got := rl.timePerToken(); got < minTokenInterval || got > maxTokenInterval {
+			t.Errorf("timePerToken() at %g tokens/s = %v, want within [%v, %v]",
+				rate, got, minTokenInterval, maxTokenInterval)
+		}
+	}
+}
+
 func TestRateLimiter_Disabled(t *testing.T) {
 	rl := NewRateLimiter(5, 1*time.Minute, false, false)
 
@@ -166,3 +205,113 @@ func TestRateLimiter_Concurrent(t *testing.T) {
 		t.Errorf("Expected approximately 100 successful acquisitions, got %d", successCount)
 	}
 }
+
+// sharedBucket is a fake distributed backend holding a fixed number of tokens
+// shared by every client that uses it
+type sharedBucket struct {
+	mu       sync.Mutex
+	tokens   int
+	acquired int
+}
+
+func (b *sharedBucket) TryAcquire(ctx context.Context) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens == 0 {
+		return false, nil
+	}
+	b.tokens--
+	b.acquired++
+	return true, nil
+}
+
+func (b *sharedBucket) EstimateWaitTime(ctx context.Context) (time.Duration, error) {
+	return 5 * time.Millisecond, nil
+}
+
+// failingBucket is a distributed backend whose storage is unreachable
+type failingBucket struct {
+	err error
+}
+
+func (b failingBucket) TryAcquire(ctx context.Context) (bool, error) {
+	return false, b.err
+}
+
+func (b failingBucket) EstimateWaitTime(ctx context.Context) (time.Duration, error) {
+	return 0, b.err
+}
+
+func TestDistributedRateLimiterSharesQuotaAcrossClients(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data:    map[string]interface{}{"isValid": true, "status": "active"},
+		})
+	}
+	bucket := &sharedBucket{tokens: 3}
+
+	first, firstServer := newClientWithServer(t, handler, WithoutCache(), WithDistributedRateLimiter(bucket))
+	defer firstServer.Close()
+	second, secondServer := newClientWithServer(t, handler, WithoutCache(), WithDistributedRateLimiter(bucket))
+	defer secondServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	succeeded := 0
+	for _, client := range []*Client{first, second, first, second} {
+		if _, err := client.VerifyPIN(ctx, "P051234567A"); err == nil {
+			succeeded++
+		} else if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected the over-quota call to wait until the deadline, got %v", err)
+		}
+	}
+
+	if succeeded != 3 || bucket.acquired != 3 {
+		t.Fatalf("expected 3 calls across both clients, got %d succeeded and %d tokens taken", succeeded, bucket.acquired)
+	}
+
+	if err := WithDistributedRateLimiter(nil)(DefaultConfig()); err == nil {
+		t.Fatal("expected error for nil backend")
+	}
+}
+
+func TestDistributedRateLimiterErrorFailsRequest(t *testing.T) {
+	var requests int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"isValid": true}})
+	}
+	storageErr := errors.New("redis: connection refused")
+	client, server := newClientWithServer(t, handler, WithoutCache(), WithDistributedRateLimiter(failingBucket{err: storageErr}))
+	defer server.Close()
+
+	_, err := client.VerifyPIN(context.Background(), "P051234567A")
+	if !errors.Is(err, storageErr) {
+		t.Fatalf("expected the backend error, got %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 0 {
+		t.Fatalf("expected no requests without a token, got %d", got)
+	}
+}
+
+func TestRateLimiterWaitContextCancels(t *testing.T) {
+	rl := NewRateLimiter(1, time.Hour, true, false)
+	if err := rl.WaitContext(context.Background()); err != nil {
+		t.Fatalf("WaitContext() with a token available = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := rl.WaitContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("WaitContext did not return promptly after cancellation: %v", elapsed)
+	}
+	if wait := rl.EstimateWaitTime(); wait < time.Minute {
+		t.Fatalf("EstimateWaitTime() = %v, want about an hour for a sub-1/s refill rate", wait)
+	}
+}
@@ -0,0 +1,102 @@
+package kra
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Raw sends a request to an arbitrary KRA endpoint and returns the
+// normalized response
+//
+// Use Raw for endpoints the SDK does not wrap yet. The request goes through
+// the same authentication, rate limiting, retry and response normalization
+// as the typed methods; body is encoded as JSON when non-nil. When
+// WithRawCacheTTL is set, responses of idempotent calls are cached.
+//
+// Example:
+//
+//	resp, err := client.Raw(ctx, http.MethodPost, "/checker/v1/custom", map[string]string{
+//	    "KRAPIN": "P051234567A",
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	fmt.Println(resp.Data)
+func (c *Client) Raw(ctx context.Context, method, endpoint string, body interface{}) (*APIResponse, error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		return nil, NewValidationError("method", "HTTP method is required")
+	}
+	if !strings.HasPrefix(endpoint, "/") {
+		return nil, NewValidationError("endpoint", "Endpoint must be a path starting with '/'")
+	}
+
+	var cacheKey string
+	if c.config.RawCacheTTL > 0 && c.rawCacheable(method, endpoint) {
+		hash, err := rawBodyHash(body)
+		if err != nil {
+			return nil, NewValidationError("body", "Request body cannot be encoded as JSON: "+err.Error())
+		}
+
+		cacheKey = c.cacheKey(ctx, "raw", method, endpoint, hash)
+		if cached, found := c.cacheManager.Get(cacheKey); found {
+			if resp, ok := cached.(*APIResponse); ok {
+				return resp, nil
+			}
+			c.evictMistyped(cacheKey, cached)
+		}
+	}
+
+	resp, err := c.httpClient.Send(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheKey != "" {
+		c.cacheManager.Set(cacheKey, resp, c.config.RawCacheTTL)
+	}
+
+	return resp, nil
+}
+
+// rawCacheable reports whether a Raw call is idempotent enough to cache
+func (c *Client) rawCacheable(method, endpoint string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	case http.MethodPost:
+		if strings.Contains(endpoint, "/checker/") {
+			return true
+		}
+		for _, allowed := range c.config.RawCacheablePOSTs {
+			if allowed == endpoint {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rawBodyHash returns the hex SHA-256 of the JSON-encoded request body
+func rawBodyHash(body interface{}) (string, error) {
+	var encoded []byte
+	if body != nil {
+		var err error
+		encoded, err = json.Marshal(body)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,86 @@
+package kra
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClientRawCachesIdempotentCalls(t *testing.T) {
+	hits := make(map[string]int)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		hits[r.Method+" "+r.URL.Path]++
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"pin": body["KRAPIN"]}})
+	}
+
+	client, server := newClientWithServer(t, handler,
+		WithRawCacheTTL(time.Minute),
+		WithRawCacheablePOST("/custom/v1/lookup"),
+	)
+	defer server.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := client.Raw(ctx, http.MethodGet, "/custom/v1/status", nil); err != nil {
+			t.Fatalf("Raw() error = %v", err)
+		}
+	}
+	if hits["GET /custom/v1/status"] != 1 {
+		t.Fatalf("expected identical GETs to hit the server once, got %d", hits["GET /custom/v1/status"])
+	}
+
+	// Different bodies to a read-only endpoint must not collide
+	for _, pin := range []string{"P051234567A", "P051234567B", "P051234567A"} {
+		resp, err := client.Raw(ctx, http.MethodPost, "/checker/v1/custom", map[string]string{"KRAPIN": pin})
+		if err != nil {
+			t.Fatalf("Raw() error = %v", err)
+		}
+		if resp.Data["pin"] != pin {
+			t.Fatalf("Raw() returned data for %v, want %s", resp.Data["pin"], pin)
+		}
+	}
+	if hits["POST /checker/v1/custom"] != 2 {
+		t.Fatalf("expected one request per distinct body, got %d", hits["POST /checker/v1/custom"])
+	}
+
+	// POSTs to other endpoints are only cached when explicitly allowed
+	for i := 0; i < 2; i++ {
+		if _, err := client.Raw(ctx, http.MethodPost, "/dtd/return/v1/nil", map[string]string{"KRAPIN": "P051234567A"}); err != nil {
+			t.Fatalf("Raw() error = %v", err)
+		}
+		if _, err := client.Raw(ctx, http.MethodPost, "/custom/v1/lookup", map[string]string{"KRAPIN": "P051234567A"}); err != nil {
+			t.Fatalf("Raw() error = %v", err)
+		}
+	}
+	if hits["POST /dtd/return/v1/nil"] != 2 || hits["POST /custom/v1/lookup"] != 1 {
+		t.Fatalf("unexpected POST hits: %v", hits)
+	}
+}
+
+func TestClientRawWithoutCacheTTL(t *testing.T) {
+	var hits int
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"ok": true}})
+	}
+
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Raw(context.Background(), "get", "/custom/v1/status", nil); err != nil {
+			t.Fatalf("Raw() error = %v", err)
+		}
+	}
+	if hits != 2 {
+		t.Fatalf("expected no Raw caching without a TTL, got %d hits", hits)
+	}
+
+	if _, err := client.Raw(context.Background(), http.MethodGet, "custom/v1/status", nil); err == nil {
+		t.Fatal("expected error for endpoint without leading slash")
+	}
+}
@@ -0,0 +1,285 @@
+package kra
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ComplianceReportRow represents the compliance outcome for a single taxpayer
+type ComplianceReportRow struct {
+	PINNumber          string `json:"pin_number"`
+	TaxpayerName       string `json:"taxpayer_name,omitempty"`
+	Status             string `json:"status,omitempty"`
+	IsValid            bool   `json:"is_valid"`
+	ActiveObligations  int    `json:"active_obligations"`
+	OverdueObligations int    `json:"overdue_obligations"`
+	Error              string `json:"error,omitempty"`
+}
+
+// IsCompliant returns true if the PIN is valid and has no overdue obligations
+func (r *ComplianceReportRow) IsCompliant() bool {
+	return r.Error == "" && r.IsValid && r.OverdueObligations == 0
+}
+
+// ComplianceBatchReport summarizes the compliance status of a set of taxpayers
+type ComplianceBatchReport struct {
+	Rows         []ComplianceReportRow `json:"rows"`
+	Total        int                   `json:"total"`
+	ValidCount   int                   `json:"valid_count"`
+	InvalidCount int                   `json:"invalid_count"`
+	ErrorCount   int                   `json:"error_count"`
+	OverdueCount int                   `json:"overdue_count"`
+	GeneratedAt  time.Time             `json:"generated_at"`
+}
+
+// complianceCSVHeader lists the columns written by ToCSV
+var complianceCSVHeader = []string{
+	"pin_number",
+	"taxpayer_name",
+	"status",
+	"is_valid",
+	"active_obligations",
+	"overdue_obligations",
+	"compliant",
+	"error",
+}
+
+// ToCSV writes the report rows as CSV, including a header row
+//
+// Example:
+//
+//	report, err := client.GenerateComplianceReport(ctx, pins)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := report.ToCSV(os.Stdout); err != nil {
+//	    log.Fatal(err)
+//	}
+func (r *ComplianceBatchReport) ToCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(complianceCSVHeader); err != nil {
+		return err
+	}
+
+	for _, row := range r.Rows {
+		record := []string{
+			row.PINNumber,
+			row.TaxpayerName,
+			row.Status,
+			strconv.FormatBool(row.IsValid),
+			strconv.Itoa(row.ActiveObligations),
+			strconv.Itoa(row.OverdueObligations),
+			strconv.FormatBool(row.IsCompliant()),
+			row.Error,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// GenerateComplianceReport verifies each PIN, fetches its obligations, and
+// summarizes the outcome in a single report
+//
+// Up to 10 PINs are processed concurrently and rows are returned in input
+// order. Failures for individual PINs are recorded in the row's Error field
+// rather than failing the whole report. Obligations are only fetched for
+// valid PINs. If the client is closed while the report is running, no further
+// PINs are processed and the partial report is returned with
+// ErrClosedDuringBatch.
+//
+// Example:
+//
+//	report, err := client.GenerateComplianceReport(ctx, pins)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	fmt.Printf("%d/%d valid, %d with overdue filings\n",
+//	    report.ValidCount, report.Total, report.OverdueCount)
+func (c *Client) GenerateComplianceReport(ctx context.Context, pins []string) (*ComplianceBatchReport, error) {
+	if err := c.checkBatchSize(len(pins)); err != nil {
+		return nil, err
+	}
+
+	endBatch, err := c.beginBatch()
+	if err != nil {
+		return nil, err
+	}
+	defer endBatch()
+
+	rows := make([]ComplianceReportRow, len(pins))
+	errs := make([]error, len(pins))
+
+	c.runBatch(ctx, len(pins), nil, func(i int) {
+		rows[i], errs[i] = c.complianceRow(ctx, pins[i])
+	}, func(i int, err error) {
+		rows[i] = ComplianceReportRow{PINNumber: pins[i], Error: err.Error()}
+		errs[i] = err
+	})
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &ComplianceBatchReport{
+		Rows:        rows,
+		Total:       len(rows),
+		GeneratedAt: time.Now(),
+	}
+	for _, row := range rows {
+		switch {
+		case row.Error != "":
+			report.ErrorCount++
+		case row.IsValid:
+			report.ValidCount++
+		default:
+			report.InvalidCount++
+		}
+		if row.OverdueObligations > 0 {
+			report.OverdueCount++
+		}
+	}
+
+	if err := firstBatchError(errs); errors.Is(err, ErrClosedDuringBatch) {
+		return report, err
+	}
+	return report, nil
+}
+
+// complianceRow builds the report row for a single PIN
+//
+// The returned error is the one recorded in the row's Error field.
+func (c *Client) complianceRow(ctx context.Context, pin string) (ComplianceReportRow, error) {
+	row := ComplianceReportRow{PINNumber: pin}
+
+	result, err := c.VerifyPIN(ctx, pin)
+	if err != nil {
+		err = batchItemError(err)
+		row.Error = err.Error()
+		return row, err
+	}
+
+	row.PINNumber = result.PINNumber
+	row.TaxpayerName = result.TaxpayerName
+	row.Status = result.Status
+	row.IsValid = result.IsValid
+
+	if !result.IsValid {
+		return row, nil
+	}
+
+	obligations, _, err := c.fetchObligations(ctx, result.PINNumber)
+	if err != nil {
+		err = batchItemError(err)
+		row.Error = err.Error()
+		return row, err
+	}
+
+	for i := range obligations {
+		if obligations[i].IsActive {
+			row.ActiveObligations++
+		}
+		if obligations[i].IsFilingOverdue() {
+			row.OverdueObligations++
+		}
+	}
+
+	return row, nil
+}
+
+// UnmatchedEslip describes an e-slip that could not be reconciled
+type UnmatchedEslip struct {
+	EslipNumber string `json:"eslip_number"`
+	Reason      string `json:"reason"`
+}
+
+// EslipReconciliation summarizes the validation of a set of e-slips
+type EslipReconciliation struct {
+	Results              []*EslipValidationResult `json:"results"`
+	Total                int                      `json:"total"`
+	PaidCount            int                      `json:"paid_count"`
+	PendingCount         int                      `json:"pending_count"`
+	CancelledCount       int                      `json:"cancelled_count"`
+	PaidTotalsByCurrency map[string]float64       `json:"paid_totals_by_currency"`
+	Unmatched            []UnmatchedEslip         `json:"unmatched,omitempty"`
+	ReconciledAt         time.Time                `json:"reconciled_at"`
+}
+
+// ReconcileEslips validates a set of e-slips and summarizes the outcome for
+// payment reconciliation
+//
+// Paid, pending and cancelled slips are counted using the result helpers,
+// and the amounts of paid slips are totalled per currency. Slips that fail
+// validation or are reported invalid are listed in Unmatched with the reason
+// instead of failing the whole reconciliation. Results holds every slip that
+// was found, in input order. Up to 10 slips are validated concurrently; if the
+// client is closed meanwhile, no further slips are validated and the partial
+// reconciliation is returned with ErrClosedDuringBatch.
+//
+// Example:
+//
+//	recon, err := client.ReconcileEslips(ctx, eslipNumbers)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	fmt.Printf("paid: %d, KES total: %.2f, unmatched: %d\n",
+//	    recon.PaidCount, recon.PaidTotalsByCurrency["KES"], len(recon.Unmatched))
+func (c *Client) ReconcileEslips(ctx context.Context, eslipNumbers []string) (*EslipReconciliation, error) {
+	if err := c.checkBatchSize(len(eslipNumbers)); err != nil {
+		return nil, err
+	}
+
+	endBatch, err := c.beginBatch()
+	if err != nil {
+		return nil, err
+	}
+	defer endBatch()
+
+	results, errs := c.validateEslips(ctx, eslipNumbers, nil)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	recon := &EslipReconciliation{
+		Total:                len(eslipNumbers),
+		PaidTotalsByCurrency: make(map[string]float64),
+		ReconciledAt:         time.Now(),
+	}
+	for i, result := range results {
+		if errs[i] != nil {
+			recon.Unmatched = append(recon.Unmatched, UnmatchedEslip{EslipNumber: eslipNumbers[i], Reason: errs[i].Error()})
+			continue
+		}
+
+		recon.Results = append(recon.Results, result)
+
+		switch {
+		case result.IsCancelled():
+			recon.CancelledCount++
+		case !result.IsValid:
+			recon.Unmatched = append(recon.Unmatched, UnmatchedEslip{EslipNumber: eslipNumbers[i], Reason: "e-slip is not valid"})
+		case result.IsPaid():
+			recon.PaidCount++
+			recon.PaidTotalsByCurrency[strings.ToUpper(result.Currency)] += result.Amount
+		case result.IsPending():
+			recon.PendingCount++
+		}
+	}
+
+	if err := firstBatchError(errs); errors.Is(err, ErrClosedDuringBatch) {
+		return recon, err
+	}
+	return recon, nil
+}
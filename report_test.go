@@ -0,0 +1,217 @@
+package kra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientGenerateComplianceReport(t *testing.T) {
+	overdue := time.Now().Add(-10 * 24 * time.Hour).Format("2006-01-02")
+	upcoming := time.Now().Add(10 * 24 * time.Hour).Format("2006-01-02")
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.URL.Path {
+		case "/checker/v1/pinbypin":
+			pin := body["KRAPIN"]
+			status := "active"
+			if pin == "P051234567C" {
+				status = "inactive"
+			}
+			writeJSON(t, w, apiResponse{
+				Success: true,
+				Data: map[string]interface{}{
+					"kraPin":       pin,
+					"taxpayerName": "Taxpayer " + pin[len(pin)-1:],
+					"pinStatus":    status,
+				},
+			})
+		case "/dtd/checker/v1/obligation":
+			nextFiling := upcoming
+			if body["taxPayerPin"] == "P051234567B" {
+				nextFiling = overdue
+			}
+			writeJSON(t, w, apiResponse{
+				Success: true,
+				Data: map[string]interface{}{
+					"obligations": []map[string]interface{}{
+						{"obligationType": "VAT", "isActive": true, "nextFilingDate": nextFiling},
+						{"obligationType": "PAYE", "isActive": false},
+					},
+				},
+			})
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}
+
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+
+	pins := []string{"P051234567A", "P051234567B", "P051234567C", "INVALID"}
+	report, err := client.GenerateComplianceReport(context.Background(), pins)
+	if err != nil {
+		t.Fatalf("GenerateComplianceReport() error = %v", err)
+	}
+
+	if report.Total != 4 || report.ValidCount != 2 || report.InvalidCount != 1 || report.ErrorCount != 1 || report.OverdueCount != 1 {
+		t.Fatalf("unexpected counts: %+v", report)
+	}
+	if !report.Rows[0].IsCompliant() || report.Rows[0].ActiveObligations != 1 {
+		t.Fatalf("expected first row to be compliant, got %+v", report.Rows[0])
+	}
+	if report.Rows[1].IsCompliant() || report.Rows[1].OverdueObligations != 1 {
+		t.Fatalf("expected second row to have an overdue obligation, got %+v", report.Rows[1])
+	}
+	if report.Rows[3].Error == "" {
+		t.Fatalf("expected error for invalid PIN row, got %+v", report.Rows[3])
+	}
+}
+
+func TestComplianceBatchReport_ToCSV(t *testing.T) {
+	report := &ComplianceBatchReport{
+		Rows: []ComplianceReportRow{
+			{PINNumber: "P051234567A", TaxpayerName: "Acme, Ltd", Status: "active", IsValid: true, ActiveObligations: 2},
+			{PINNumber: "P051234567B", Status: "active", IsValid: true, ActiveObligations: 1, OverdueObligations: 1},
+			{PINNumber: "BAD", Error: "invalid PIN"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := report.ToCSV(&buf); err != nil {
+		t.Fatalf("ToCSV() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"pin_number,taxpayer_name,status,is_valid,active_obligations,overdue_obligations,compliant,error",
+		`P051234567A,"Acme, Ltd",active,true,2,0,true,`,
+		"P051234567B,,active,true,1,1,false,",
+		"BAD,,,false,0,0,false,invalid PIN",
+		"",
+	}, "\n")
+	if buf.String() != want {
+		t.Fatalf("ToCSV() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestClientReconcileEslips(t *testing.T) {
+	slips := map[string]map[string]interface{}{
+		"1000000001": {"status": "PAID", "amount": 1500.0, "currency": "KES"},
+		"1000000002": {"status": "paid", "amount": 500.5, "currency": "kes"},
+		"1000000003": {"status": "Paid", "amount": 20.0, "currency": "USD"},
+		"1000000004": {"status": "pending", "amount": 300.0, "currency": "KES"},
+		"1000000005": {"status": "cancelled", "amount": 100.0, "currency": "KES"},
+		"1000000006": {"status": "invalid", "isValid": false},
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		data, ok := slips[body["EslipNumber"]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			writeJSON(t, w, apiResponse{Success: false, Message: "e-slip not found"})
+			return
+		}
+		writeJSON(t, w, apiResponse{Success: true, Data: data})
+	}
+
+	client, server := newClientWithServer(t, handler, WithRetry(0, time.Millisecond, time.Millisecond))
+	defer server.Close()
+
+	numbers := []string{"1000000001", "1000000002", "1000000003", "1000000004", "1000000005", "1000000006", "1000000007", "ABC"}
+	recon, err := client.ReconcileEslips(context.Background(), numbers)
+	if err != nil {
+		t.Fatalf("ReconcileEslips() error = %v", err)
+	}
+
+	if recon.Total != 8 || recon.PaidCount != 3 || recon.PendingCount != 1 || recon.CancelledCount != 1 {
+		t.Fatalf("unexpected counts: %+v", recon)
+	}
+	if recon.PaidTotalsByCurrency["KES"] != 2000.5 || recon.PaidTotalsByCurrency["USD"] != 20 || len(recon.PaidTotalsByCurrency) != 2 {
+		t.Fatalf("unexpected paid totals: %v", recon.PaidTotalsByCurrency)
+	}
+	if len(recon.Results) != 6 {
+		t.Fatalf("expected 6 matched results, got %d", len(recon.Results))
+	}
+
+	var unmatched []string
+	for _, u := range recon.Unmatched {
+		if u.Reason == "" {
+			t.Fatalf("expected a reason for unmatched e-slip %s", u.EslipNumber)
+		}
+		unmatched = append(unmatched, u.EslipNumber)
+	}
+	if strings.Join(unmatched, ",") != "1000000006,1000000007,ABC" {
+		t.Fatalf("unexpected unmatched e-slips: %v", unmatched)
+	}
+}
+
+func TestClientExportObligations(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["taxPayerPin"] == "P051234567C" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		writeJSON(t, w, apiResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"obligations": []map[string]interface{}{
+					{"obligationType": "VAT", "isActive": true},
+				},
+			},
+		})
+	}
+
+	client, server := newClientWithServer(t, handler, WithRetry(0, time.Millisecond, time.Millisecond))
+	defer server.Close()
+
+	pins := []string{"P051234567A", "P051234567B", "P051234567C", "INVALID"}
+	for i := 0; i < 20; i++ {
+		pins = append(pins, fmt.Sprintf("P1%08dZ", i))
+	}
+
+	var buf bytes.Buffer
+	stats, err := client.ExportObligations(context.Background(), pins, &buf)
+	if err != nil {
+		t.Fatalf("ExportObligations() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(pins) {
+		t.Fatalf("expected %d lines, got %d", len(pins), len(lines))
+	}
+
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		var record ObligationExportRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		seen[record.PINNumber] = true
+		if record.Error == "" && len(record.Obligations) != 1 {
+			t.Fatalf("expected one obligation for %s, got %+v", record.PINNumber, record)
+		}
+	}
+	if len(seen) != len(pins) {
+		t.Fatalf("expected a line per PIN, got %d distinct PINs", len(seen))
+	}
+
+	if stats.Total != len(pins) || stats.Succeeded != len(pins)-2 || stats.Failed != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats.Errors["P051234567C"] == "" || stats.Errors["INVALID"] == "" {
+		t.Fatalf("expected per-PIN errors, got %v", stats.Errors)
+	}
+}
@@ -2,6 +2,7 @@ package kra
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 )
@@ -21,9 +22,47 @@ type ResponseMetadata struct {
 	ErrorCode    string
 	ErrorMessage string
 	RequestID    string
+
+	// Attempts is the number of attempts the SDK made before the request succeeded
+	Attempts int
+
+	// DryRun is true when the result was synthesized in dry-run mode without calling the API
+	DryRun bool
+}
+
+// responseCodeDescriptions maps documented GavaConnect response codes to
+// their standard meanings
+var responseCodeDescriptions = map[string]string{
+	"0":   "Success",
+	"00":  "Success",
+	"200": "Request processed successfully",
+	"201": "Record created successfully",
+	"400": "Bad request: the request payload is invalid",
+	"401": "Unauthorized: missing or invalid credentials",
+	"403": "Forbidden: the credentials lack permission for this operation",
+	"404": "Record not found",
+	"409": "Duplicate request: the record already exists",
+	"422": "Validation failed for one or more fields",
+	"429": "Too many requests: rate limit exceeded",
+	"500": "Internal server error",
+	"502": "Bad gateway: upstream service unavailable",
+	"503": "Service unavailable",
+	"504": "Gateway timeout: upstream service did not respond",
 }
 
-func normalizeAPIResponse(raw map[string]interface{}, statusCode int, endpoint string, body []byte) (*APIResponse, error) {
+// Description returns a human-readable description of the response
+//
+// The server-provided ResponseDesc is preferred. When it is blank, the
+// standard meaning of a known ResponseCode is returned instead, or an empty
+// string if the code is unknown.
+func (m ResponseMetadata) Description() string {
+	if desc := strings.TrimSpace(m.ResponseDesc); desc != "" {
+		return desc
+	}
+	return responseCodeDescriptions[strings.TrimSpace(m.ResponseCode)]
+}
+
+func normalizeAPIResponse(raw map[string]interface{}, statusCode int, endpoint string, body []byte, strict bool) (*APIResponse, error) {
 	meta := ResponseMetadata{
 		ResponseCode: firstString(raw, "responseCode", "ResponseCode"),
 		ResponseDesc: firstString(raw, "responseDesc", "ResponseDesc", "message", "Message"),
@@ -52,7 +91,27 @@ func normalizeAPIResponse(raw map[string]interface{}, statusCode int, endpoint s
 		if msg == "" {
 			msg = "API request failed"
 		}
-		return nil, NewAPIError(statusCode, msg, endpoint, string(body))
+		return nil, withKRACode(NewAPIError(statusCode, msg, endpoint, string(body)), meta.ErrorCode)
+	}
+
+	if strict {
+		if key, kind := nonObjectPayload(raw); key != "" {
+			return nil, NewAPIError(
+				statusCode,
+				fmt.Sprintf("API response payload %q is a JSON %s, expected an object", key, kind),
+				endpoint,
+				string(body),
+			)
+		}
+	}
+
+	if strict && !hasPayload(raw) {
+		return nil, NewAPIError(
+			statusCode,
+			"API response envelope has neither a data payload nor error metadata",
+			endpoint,
+			string(body),
+		)
 	}
 
 	return &APIResponse{
@@ -69,13 +128,41 @@ func extractPayload(raw map[string]interface{}) map[string]interface{} {
 	if data, ok := raw["data"].(map[string]interface{}); ok {
 		return data
 	}
-	// Legacy success structure
-	if success, ok := raw["success"].(bool); ok && success {
-		return raw["data"].(map[string]interface{})
-	}
+	// Non-object or missing payloads fall back to the envelope itself
 	return raw
 }
 
+// nonObjectPayload reports the first payload field that is present but not a
+// JSON object, along with the JSON kind it holds
+func nonObjectPayload(raw map[string]interface{}) (key, kind string) {
+	for _, key := range []string{"responseData", "data"} {
+		switch raw[key].(type) {
+		case nil:
+			continue
+		case map[string]interface{}:
+			return "", ""
+		case []interface{}:
+			return key, "array"
+		case string:
+			return key, "string"
+		case bool:
+			return key, "boolean"
+		default:
+			return key, "number"
+		}
+	}
+	return "", ""
+}
+
+// hasPayload reports whether the envelope carries a recognizable data payload
+func hasPayload(raw map[string]interface{}) bool {
+	if _, ok := raw["responseData"].(map[string]interface{}); ok {
+		return true
+	}
+	_, ok := raw["data"].(map[string]interface{})
+	return ok
+}
+
 func isError(meta ResponseMetadata, raw map[string]interface{}) bool {
 	if meta.ErrorCode != "" {
 		return true
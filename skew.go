@@ -0,0 +1,68 @@
+package kra
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// clockSkew tracks the difference between KRA's clock, as reported in the
+// Date response header, and the local clock
+type clockSkew struct {
+	mu     sync.Mutex
+	last   time.Duration
+	warned bool // a warning was logged and skew has not since returned within the threshold
+}
+
+// observeServerTime records the skew implied by a response's Date header
+// received at receivedAt, warning when it exceeds the configured threshold
+func (h *HTTPClient) observeServerTime(date string, receivedAt time.Time) {
+	if date == "" {
+		return
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return
+	}
+	// The header has one-second precision, so compare whole seconds
+	skew := serverTime.Sub(receivedAt.Truncate(time.Second))
+
+	h.skew.mu.Lock()
+	h.skew.last = skew
+	threshold := h.config.ClockSkewWarning
+	exceeded := threshold > 0 && (skew > threshold || skew < -threshold)
+	warn := exceeded && !h.skew.warned
+	h.skew.warned = exceeded
+	h.skew.mu.Unlock()
+
+	if warn {
+		logf(h.config.Logger, h.config.ClientName,
+			"[HTTP] WARN: Server clock differs from local clock by %v (threshold %v); expiry and TTL calculations may be off\n",
+			skew, threshold)
+	}
+}
+
+// serverTimeSkew returns the last observed skew
+func (h *HTTPClient) serverTimeSkew() time.Duration {
+	h.skew.mu.Lock()
+	defer h.skew.mu.Unlock()
+	return h.skew.last
+}
+
+// ServerTimeSkew returns how far KRA's clock was ahead of the local clock in
+// the last response that carried a Date header
+//
+// A negative value means the local clock is ahead. The Date header has
+// one-second precision, so skews under a second cannot be detected. Returns 0
+// before any such response has been received. A large skew explains
+// disagreements between local expiry calculations, such as days until a TCC
+// expires, and KRA's own; see WithClockSkewWarning to have it logged.
+//
+// Example:
+//
+//	if skew := client.ServerTimeSkew(); skew > time.Minute || skew < -time.Minute {
+//	    log.Printf("local clock is off from KRA by %v", skew)
+//	}
+func (c *Client) ServerTimeSkew() time.Duration {
+	return c.httpClient.serverTimeSkew()
+}
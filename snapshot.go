@@ -0,0 +1,123 @@
+package kra
+
+import "time"
+
+// ConfigSnapshot is a read-only copy of a client's effective configuration
+//
+// Credentials are masked so the snapshot can be logged or served from a debug
+// endpoint. Modifying a snapshot has no effect on the client.
+type ConfigSnapshot struct {
+	// APIKey is the primary API key with all but its last four characters masked
+	APIKey       string        `json:"api_key,omitempty"`
+	APIKeyCount  int           `json:"api_key_count"`
+	OAuth        bool          `json:"oauth"`
+	BaseURL      string        `json:"base_url"`
+	TokenURL     string        `json:"token_url,omitempty"`
+	Timeout      time.Duration `json:"timeout"`
+	ClientName   string        `json:"client_name,omitempty"`
+	DebugMode    bool          `json:"debug_mode"`
+	DryRun       bool          `json:"dry_run"`
+	MaxBatchSize int           `json:"max_batch_size"`
+
+	// Retry policy
+	MaxRetries           int                      `json:"max_retries"`
+	InitialDelay         time.Duration            `json:"initial_delay"`
+	MaxDelay             time.Duration            `json:"max_delay"`
+	EndpointRetries      map[string]int           `json:"endpoint_retries,omitempty"`
+	EndpointTimeouts     map[string]time.Duration `json:"endpoint_timeouts,omitempty"`
+	RetryableStatusCodes []int                    `json:"retryable_status_codes,omitempty"`
+
+	// Rate limiting
+	RateLimitEnabled       bool          `json:"rate_limit_enabled"`
+	MaxRequests            int           `json:"max_requests"`
+	RateLimitWindow        time.Duration `json:"rate_limit_window"`
+	DistributedRateLimiter bool          `json:"distributed_rate_limiter"`
+
+	// Caching
+	CacheEnabled    bool   `json:"cache_enabled"`
+	CacheMaxEntries int    `json:"cache_max_entries"`
+	CacheKeyPrefix  string `json:"cache_key_prefix,omitempty"`
+
+	// CacheTTLs maps each cached operation to its cache TTL
+	CacheTTLs map[string]time.Duration `json:"cache_ttls"`
+}
+
+// Config returns a snapshot of the client's effective configuration
+//
+// Example:
+//
+//	http.HandleFunc("/debug/config", func(w http.ResponseWriter, r *http.Request) {
+//	    json.NewEncoder(w).Encode(client.Config())
+//	})
+func (c *Client) Config() ConfigSnapshot {
+	cfg := c.config
+
+	var endpointRetries map[string]int
+	if len(cfg.EndpointRetries) > 0 {
+		endpointRetries = make(map[string]int, len(cfg.EndpointRetries))
+		for endpoint, retries := range cfg.EndpointRetries {
+			endpointRetries[endpoint] = retries
+		}
+	}
+
+	var endpointTimeouts map[string]time.Duration
+	if len(cfg.EndpointTimeouts) > 0 {
+		endpointTimeouts = make(map[string]time.Duration, len(cfg.EndpointTimeouts))
+		for endpoint, timeout := range cfg.EndpointTimeouts {
+			endpointTimeouts[endpoint] = timeout
+		}
+	}
+
+	apiKeyCount := len(cfg.APIKeys)
+	if apiKeyCount == 0 && cfg.APIKey != "" {
+		apiKeyCount = 1
+	}
+
+	return ConfigSnapshot{
+		APIKey:       maskSecret(cfg.APIKey),
+		APIKeyCount:  apiKeyCount,
+		OAuth:        cfg.ClientID != "",
+		BaseURL:      cfg.BaseURL,
+		TokenURL:     cfg.TokenURL,
+		Timeout:      cfg.Timeout,
+		ClientName:   cfg.ClientName,
+		DebugMode:    cfg.DebugMode,
+		DryRun:       cfg.DryRun,
+		MaxBatchSize: cfg.MaxBatchSize,
+
+		MaxRetries:           cfg.MaxRetries,
+		InitialDelay:         cfg.InitialDelay,
+		MaxDelay:             cfg.MaxDelay,
+		EndpointRetries:      endpointRetries,
+		EndpointTimeouts:     endpointTimeouts,
+		RetryableStatusCodes: append([]int(nil), cfg.RetryableStatusCodes...),
+
+		RateLimitEnabled:       cfg.RateLimitEnabled,
+		MaxRequests:            cfg.MaxRequests,
+		RateLimitWindow:        cfg.RateLimitWindow,
+		DistributedRateLimiter: cfg.RateLimiterBackend != nil,
+
+		CacheEnabled:    cfg.CacheEnabled,
+		CacheMaxEntries: cfg.CacheMaxEntries,
+		CacheKeyPrefix:  cfg.CacheKeyPrefix,
+		CacheTTLs: map[string]time.Duration{
+			"VerifyPIN":          cfg.PINVerificationTTL,
+			"VerifyTCC":          cfg.TCCVerificationTTL,
+			"ValidateEslip":      cfg.EslipValidationTTL,
+			"GetTaxpayerDetails": cfg.TaxpayerDetailsTTL,
+			"FileNILReturn":      cfg.NILReturnTTL,
+			"Raw":                cfg.RawCacheTTL,
+		},
+	}
+}
+
+// maskSecret hides all but the last four characters of a secret
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) <= 4 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
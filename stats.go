@@ -0,0 +1,216 @@
+package kra
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clientStats accumulates request counters for a client
+type clientStats struct {
+	mu             sync.Mutex
+	requests       int64
+	retries        int64
+	rateLimitWaits int64
+	errors         map[string]int64
+	latencies      map[string]*latencyHistogram
+}
+
+// newClientStats creates an empty set of counters
+func newClientStats() *clientStats {
+	return &clientStats{
+		errors:    make(map[string]int64),
+		latencies: make(map[string]*latencyHistogram),
+	}
+}
+
+// recordLatency adds the duration of a completed HTTP attempt to the
+// endpoint's latency histogram
+func (s *clientStats) recordLatency(endpoint string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h, ok := s.latencies[endpoint]
+	if !ok {
+		h = &latencyHistogram{counts: make([]int64, len(latencyBuckets)+1)}
+		s.latencies[endpoint] = h
+	}
+	h.observe(d)
+}
+
+// latencySummaries returns a snapshot of every endpoint's latency summary
+func (s *clientStats) latencySummaries() map[string]LatencySummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summaries := make(map[string]LatencySummary, len(s.latencies))
+	for endpoint, h := range s.latencies {
+		summaries[endpoint] = h.summary()
+	}
+	return summaries
+}
+
+// recordRequest counts a single HTTP attempt
+func (s *clientStats) recordRequest() {
+	s.mu.Lock()
+	s.requests++
+	s.mu.Unlock()
+}
+
+// recordRetry counts a retry of a failed attempt
+func (s *clientStats) recordRetry() {
+	s.mu.Lock()
+	s.retries++
+	s.mu.Unlock()
+}
+
+// recordRateLimitWait counts a request that had to wait for a rate limit token
+func (s *clientStats) recordRateLimitWait() {
+	s.mu.Lock()
+	s.rateLimitWaits++
+	s.mu.Unlock()
+}
+
+// recordError counts a failed call by error type
+func (s *clientStats) recordError(err error) {
+	s.mu.Lock()
+	s.errors[errorLabel(err)]++
+	s.mu.Unlock()
+}
+
+// summary formats the counters, together with cache hit statistics, as a single line
+func (s *clientStats) summary(cacheHits, cacheMisses int64) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hitRatio := 0.0
+	if lookups := cacheHits + cacheMisses; lookups > 0 {
+		hitRatio = float64(cacheHits) / float64(lookups) * 100
+	}
+
+	labels := make([]string, 0, len(s.errors))
+	for label := range s.errors {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	errorParts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		errorParts = append(errorParts, fmt.Sprintf("%s:%d", label, s.errors[label]))
+	}
+	errorsByType := "none"
+	if len(errorParts) > 0 {
+		errorsByType = strings.Join(errorParts, ",")
+	}
+
+	return fmt.Sprintf(
+		"requests=%d cache_hits=%d cache_misses=%d cache_hit_ratio=%.1f%% retries=%d rate_limit_waits=%d errors=%s",
+		s.requests, cacheHits, cacheMisses, hitRatio, s.retries, s.rateLimitWaits, errorsByType,
+	)
+}
+
+// errorLabel returns a short label describing the type of an error
+func errorLabel(err error) string {
+	if kinded, ok := err.(interface{ Kind() ErrorKind }); ok {
+		if kind := kinded.Kind(); kind != KindUnknown {
+			return kind.String()
+		}
+	}
+	return "other"
+}
+
+// LatencySummary describes the observed latency of one endpoint
+//
+// P50 and P95 are approximate: they are the upper bound of the histogram
+// bucket containing the percentile, clamped to the observed Min and Max.
+type LatencySummary struct {
+	Count int64         `json:"count"`
+	Min   time.Duration `json:"min"`
+	Max   time.Duration `json:"max"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+}
+
+// latencyBuckets are the upper bounds of the latency histogram buckets; a
+// final overflow bucket holds anything slower
+var latencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+}
+
+// latencyHistogram is a fixed-bucket latency histogram
+type latencyHistogram struct {
+	counts []int64
+	count  int64
+	min    time.Duration
+	max    time.Duration
+}
+
+// observe records a single duration
+func (h *latencyHistogram) observe(d time.Duration) {
+	if h.count == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.count++
+
+	index := sort.Search(len(latencyBuckets), func(i int) bool { return d <= latencyBuckets[i] })
+	h.counts[index]++
+}
+
+// summary computes the count, extremes, and approximate percentiles
+func (h *latencyHistogram) summary() LatencySummary {
+	return LatencySummary{
+		Count: h.count,
+		Min:   h.min,
+		Max:   h.max,
+		P50:   h.percentile(0.50),
+		P95:   h.percentile(0.95),
+	}
+}
+
+// percentile returns the upper bound of the bucket containing quantile q
+func (h *latencyHistogram) percentile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := int64(q*float64(h.count) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+
+	var cumulative int64
+	for i, n := range h.counts {
+		cumulative += n
+		if cumulative < rank {
+			continue
+		}
+		if i == len(latencyBuckets) {
+			return h.max
+		}
+		bound := latencyBuckets[i]
+		if bound > h.max {
+			bound = h.max
+		}
+		if bound < h.min {
+			bound = h.min
+		}
+		return bound
+	}
+	return h.max
+}
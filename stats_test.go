@@ -0,0 +1,165 @@
+package kra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientStatsSummary(t *testing.T) {
+	stats := newClientStats()
+	stats.recordRequest()
+	stats.recordRequest()
+	stats.recordRequest()
+	stats.recordRetry()
+	stats.recordRateLimitWait()
+	stats.recordError(NewAPIError(500, "boom", "/x", ""))
+	stats.recordError(NewNetworkError("/x", errors.New("dial")))
+	stats.recordError(NewNetworkError("/x", errors.New("dial")))
+
+	want := "requests=3 cache_hits=3 cache_misses=1 cache_hit_ratio=75.0% retries=1 rate_limit_waits=1 errors=api:1,network:2"
+	if got := stats.summary(3, 1); got != want {
+		t.Fatalf("summary() = %q, want %q", got, want)
+	}
+}
+
+func TestClientStatsLatencySummaries(t *testing.T) {
+	stats := newClientStats()
+	for i := 0; i < 90; i++ {
+		stats.recordLatency("/checker/v1/pinbypin", 20*time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		stats.recordLatency("/checker/v1/pinbypin", 400*time.Millisecond)
+	}
+	stats.recordLatency("/dtd/return/v1/nil", 45*time.Second)
+
+	summaries := stats.latencySummaries()
+
+	want := LatencySummary{
+		Count: 100,
+		Min:   20 * time.Millisecond,
+		Max:   400 * time.Millisecond,
+		P50:   25 * time.Millisecond,
+		P95:   400 * time.Millisecond,
+	}
+	if got := summaries["/checker/v1/pinbypin"]; got != want {
+		t.Fatalf("pinbypin summary = %+v, want %+v", got, want)
+	}
+
+	// Durations beyond the last bucket report the observed maximum
+	slow := summaries["/dtd/return/v1/nil"]
+	if slow.Count != 1 || slow.P50 != 45*time.Second || slow.P95 != 45*time.Second {
+		t.Fatalf("unexpected summary for slow endpoint: %+v", slow)
+	}
+}
+
+func TestClientLatencyStats(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"pinStatus": "active"}})
+	}
+
+	client, server := newClientWithServer(t, handler, WithoutCache())
+	defer server.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.VerifyPIN(context.Background(), "P051234567A"); err != nil {
+			t.Fatalf("VerifyPIN() error = %v", err)
+		}
+	}
+
+	summary, ok := client.LatencyStats()["/checker/v1/pinbypin"]
+	if !ok || summary.Count != 3 || summary.Min <= 0 || summary.Max < summary.Min {
+		t.Fatalf("unexpected latency stats: %+v", client.LatencyStats())
+	}
+}
+
+func TestClientLastResponsesKeepsMostRecent(t *testing.T) {
+	var calls int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		writeJSON(t, w, apiResponse{Success: true, Data: map[string]interface{}{"call": n}})
+	}
+	client, server := newClientWithServer(t, handler, WithoutCache(), WithResponseHistory(3))
+	defer server.Close()
+
+	for i := 0; i < 5; i++ {
+		if _, err := client.httpClient.Post(context.Background(), "/checker/v1/pinbypin", nil); err != nil {
+			t.Fatalf("Post() error = %v", err)
+		}
+	}
+
+	records := client.LastResponses()
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	for i, record := range records {
+		want := fmt.Sprintf(`"call":%d`, i+3)
+		if !strings.Contains(record.Body, want) {
+			t.Fatalf("record %d body = %s, want it to contain %s", i, record.Body, want)
+		}
+		if record.Endpoint != "/checker/v1/pinbypin" || record.StatusCode != http.StatusOK || record.Method != http.MethodPost {
+			t.Fatalf("unexpected record: %+v", record)
+		}
+	}
+
+	plain, plainServer := newClientWithServer(t, handler, WithoutCache())
+	defer plainServer.Close()
+	if records := plain.LastResponses(); records != nil {
+		t.Fatalf("expected no history by default, got %v", records)
+	}
+	if err := WithResponseHistory(0)(DefaultConfig()); err == nil {
+		t.Fatal("expected error for non-positive history size")
+	}
+}
+
+func TestClientEstimateBatchDuration(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("EstimateBatchDuration should not call the API")
+	}
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		items     int
+		want      time.Duration
+		tolerance time.Duration
+	}{
+		{"empty batch", []Option{WithRateLimit(10, time.Second)}, 0, 0, 0},
+		{"fits available tokens", []Option{WithRateLimit(10, time.Second)}, 5, 0, 0},
+		{"exceeds tokens per second", []Option{WithRateLimit(10, time.Second)}, 30, 2 * time.Second, 50 * time.Millisecond},
+		{"exceeds tokens per minute", []Option{WithRateLimit(60, time.Minute)}, 120, time.Minute, time.Second},
+		{"distributed limiter uses configured rate", []Option{
+			WithRateLimit(60, time.Minute),
+			WithDistributedRateLimiter(&sharedBucket{tokens: 0}),
+		}, 90, 30 * time.Second, time.Second},
+		{"no rate limit", nil, 1000, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newClientWithServer(t, handler, tt.opts...)
+			defer server.Close()
+
+			got := client.EstimateBatchDuration(tt.items)
+			if diff := got - tt.want; diff < -tt.tolerance || diff > tt.tolerance {
+				t.Errorf("EstimateBatchDuration(%d) = %v, want %v ± %v", tt.items, got, tt.want, tt.tolerance)
+			}
+		})
+	}
+
+	// Observed latency bounds the estimate when the rate limit does not
+	client, server := newClientWithServer(t, handler)
+	defer server.Close()
+	for i := 0; i < 5; i++ {
+		client.httpClient.stats.recordLatency("/checker/v1/pinbypin", 100*time.Millisecond)
+	}
+	got := client.EstimateBatchDuration(25)
+	if got < 200*time.Millisecond || got > 400*time.Millisecond {
+		t.Errorf("EstimateBatchDuration(25) with ~100ms latency = %v, want about 3 rounds", got)
+	}
+}
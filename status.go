@@ -0,0 +1,97 @@
+package kra
+
+import "strings"
+
+// StatusEnum is a normalized status value returned by KRA
+//
+// KRA reports statuses with inconsistent casing and occasionally as
+// single-letter codes. NormalizeStatus maps those variants onto the
+// constants below so that status checks do not depend on the raw string.
+//
+// The canonical vocabulary, with the raw spellings accepted for each:
+//
+//	active       "active", "A"
+//	inactive     "inactive", "I"
+//	expired      "expired"
+//	paid         "paid"
+//	pending      "pending", "queued", "processing"
+//	cancelled    "cancelled", "canceled"
+//	accepted     "accepted"
+//	rejected     "rejected"
+//	suspended    "suspended"
+//	blacklisted  "blacklisted", "black-listed"
+//	dormant      "dormant"
+type StatusEnum string
+
+// Normalized status values
+const (
+	StatusUnknown   StatusEnum = ""
+	StatusActive    StatusEnum = "active"
+	StatusInactive  StatusEnum = "inactive"
+	StatusExpired   StatusEnum = "expired"
+	StatusPaid      StatusEnum = "paid"
+	StatusPending   StatusEnum = "pending"
+	StatusCancelled StatusEnum = "cancelled"
+	StatusAccepted  StatusEnum = "accepted"
+	StatusRejected  StatusEnum = "rejected"
+
+	// Taxpayer statuses flagged by KRA for compliance review
+	StatusSuspended   StatusEnum = "suspended"
+	StatusBlacklisted StatusEnum = "blacklisted"
+	StatusDormant     StatusEnum = "dormant"
+)
+
+// statusAliases maps lowercased raw statuses onto their normalized values
+var statusAliases = map[string]StatusEnum{
+	"a":          StatusActive,
+	"active":     StatusActive,
+	"i":          StatusInactive,
+	"inactive":   StatusInactive,
+	"expired":    StatusExpired,
+	"paid":       StatusPaid,
+	"pending":    StatusPending,
+	"queued":     StatusPending,
+	"processing": StatusPending,
+	"cancelled":  StatusCancelled,
+	"canceled":   StatusCancelled,
+	"accepted":   StatusAccepted,
+	"rejected":   StatusRejected,
+
+	"suspended":    StatusSuspended,
+	"blacklisted":  StatusBlacklisted,
+	"black-listed": StatusBlacklisted,
+	"dormant":      StatusDormant,
+}
+
+// NormalizeStatus maps a raw KRA status onto a StatusEnum
+//
+// Matching is case-insensitive and ignores surrounding whitespace. The
+// single-letter codes "A" and "I" map to StatusActive and StatusInactive.
+// Unrecognized statuses return StatusUnknown.
+//
+// Example:
+//
+//	kra.NormalizeStatus("ACTIVE") // kra.StatusActive
+//	kra.NormalizeStatus("A")      // kra.StatusActive
+func NormalizeStatus(status string) StatusEnum {
+	return statusAliases[NormalizeStatusString(status)]
+}
+
+// NormalizeStatusString lowercases a raw KRA status and trims surrounding
+// whitespace
+//
+// This is the normalization the SDK applies to the Status field of every
+// result, so callers comparing against those fields, or mapping them to UI
+// states, can apply the same rules to their own values. Unlike
+// NormalizeStatus it keeps unrecognized statuses and does not resolve
+// aliases; pass the result to NormalizeStatus to map it onto the canonical
+// vocabulary. It carries the String suffix because NormalizeStatus already
+// names the StatusEnum mapping.
+//
+// Example:
+//
+//	kra.NormalizeStatusString("  Active ") // "active"
+//	kra.NormalizeStatusString("Canceled")  // "canceled"
+func NormalizeStatusString(raw string) string {
+	return strings.ToLower(strings.TrimSpace(raw))
+}
@@ -6,7 +6,6 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
-	"time"
 )
 
 type apiResponse struct {
@@ -43,7 +42,6 @@ func newClientWithServer(t testing.TB, handler http.HandlerFunc, extraOpts ...Op
 	}
 
 	client.httpClient.client = server.Client()
-	client.httpClient.client.Timeout = 5 * time.Second
 
 	return client, server
 }
@@ -9,8 +9,9 @@ import (
 
 // Regular expressions for validation
 var (
-	// PIN format: P followed by 9 digits and a letter (e.g., P051234567A)
-	pinRegex = regexp.MustCompile(`^P\d{9}[A-Z]$`)
+	// PIN format: A (individuals) or P (non-individuals) followed by 9 digits
+	// and a letter (e.g., P051234567A)
+	pinRegex = regexp.MustCompile(`^[AP]\d{9}[A-Z]$`)
 
 	// TCC format: TCC followed by digits (e.g., TCC123456)
 	tccRegex = regexp.MustCompile(`^TCC\d+$`)
@@ -27,7 +28,8 @@ var (
 
 // ValidateAndNormalizePIN validates and normalizes a PIN number
 //
-// PIN format: P followed by 9 digits and a letter (e.g., P051234567A)
+// PIN format: A (individuals) or P (non-individuals) followed by 9 digits and
+// a letter (e.g., A012345678Z or P051234567A)
 // The function converts the PIN to uppercase and removes whitespace.
 //
 // Returns the normalized PIN or an error if validation fails.
@@ -47,6 +49,88 @@ func ValidateAndNormalizePIN(pin string) (string, error) {
 	return normalized, nil
 }
 
+// IsValidPINFormat reports whether a PIN is well formed
+//
+// It accepts the same ASCII inputs as ValidateAndNormalizePIN, including
+// surrounding whitespace and lowercase letters, but checks them in place
+// without allocating, which makes it suitable for pre-filtering large
+// datasets before batching.
+func IsValidPINFormat(pin string) bool {
+	pin = strings.TrimSpace(pin)
+	if len(pin) != 11 {
+		return false
+	}
+	switch pin[0] {
+	case 'A', 'a', 'P', 'p':
+	default:
+		return false
+	}
+	for i := 1; i < 10; i++ {
+		if pin[i] < '0' || pin[i] > '9' {
+			return false
+		}
+	}
+	last := pin[10]
+	return (last >= 'A' && last <= 'Z') || (last >= 'a' && last <= 'z')
+}
+
+// taxpayerTypeFromPIN maps the leading character of a PIN to a taxpayer type
+func taxpayerTypeFromPIN(pin string) string {
+	normalized := strings.ToUpper(strings.TrimSpace(pin))
+	if normalized == "" {
+		return ""
+	}
+
+	switch normalized[0] {
+	case 'A':
+		return "individual"
+	case 'P':
+		return "company"
+	default:
+		return ""
+	}
+}
+
+// PINInfo describes a PIN as far as it can be checked without calling KRA
+type PINInfo struct {
+	// PIN is the input trimmed and uppercased
+	PIN string `json:"pin"`
+	// TaxpayerType is "individual" or "company" as encoded in the PIN's
+	// leading character, or empty if the character is not recognized
+	TaxpayerType string `json:"taxpayer_type,omitempty"`
+	// ValidFormat is true if the SDK accepts the PIN for API calls
+	ValidFormat bool `json:"valid_format"`
+}
+
+// InspectPIN checks a PIN's format and detects its taxpayer type offline
+//
+// No request is made, so it is cheap enough to run on every keystroke of a
+// form field. The taxpayer type is read from the leading character ("A" for
+// individuals, "P" for companies and other non-individuals) even when the
+// format is invalid, so a UI can hint at it early. ValidFormat follows
+// ValidateAndNormalizePIN; for an invalid PIN the returned error is the one
+// ValidateAndNormalizePIN would return, and the info is still filled in.
+//
+// Example:
+//
+//	info, err := kra.InspectPIN(input)
+//	if err != nil {
+//	    showHint(err.Error())
+//	} else {
+//	    showHint("Looks like a " + info.TaxpayerType + " PIN")
+//	}
+func InspectPIN(pin string) (PINInfo, error) {
+	info := PINInfo{
+		PIN:          strings.ToUpper(strings.TrimSpace(pin)),
+		TaxpayerType: taxpayerTypeFromPIN(pin),
+	}
+	if _, err := ValidateAndNormalizePIN(pin); err != nil {
+		return info, err
+	}
+	info.ValidFormat = true
+	return info, nil
+}
+
 // ValidateAndNormalizeTCC validates and normalizes a TCC number
 //
 // TCC format: TCC followed by digits (e.g., TCC123456)
@@ -228,13 +312,14 @@ func ValidateRateLimitConfig(maxRequests int, window time.Duration) error {
 
 // ValidateCacheTTL validates cache TTL duration
 //
-// Returns an error if validation fails.
+// The TTL must be between 0 and 24 hours, or CacheForever. Returns an error
+// if validation fails.
 func ValidateCacheTTL(ttl time.Duration) error {
 	if ttl < 0 {
 		return NewValidationError("cache_ttl", "Cache TTL cannot be negative")
 	}
 
-	if ttl > 24*time.Hour {
+	if ttl > 24*time.Hour && ttl != CacheForever {
 		return NewValidationError("cache_ttl", "Cache TTL cannot exceed 24 hours")
 	}
 
@@ -1,6 +1,7 @@
 package kra
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -36,6 +37,18 @@ func TestValidateAndNormalizePIN(t *testing.T) {
 			want:    "",
 			wantErr: true,
 		},
+		{
+			name:    "valid individual PIN",
+			pin:     "a012345678z",
+			want:    "A012345678Z",
+			wantErr: false,
+		},
+		{
+			name:    "invalid format - unknown prefix",
+			pin:     "X051234567A",
+			want:    "",
+			wantErr: true,
+		},
 		{
 			name:    "invalid format - missing P",
 			pin:     "051234567A",
@@ -82,6 +95,51 @@ func TestValidateAndNormalizePIN(t *testing.T) {
 	}
 }
 
+func TestIsValidPINFormat(t *testing.T) {
+	inputs := []string{
+		"P051234567A", "p051234567a", "  P051234567A  ", "\tP051234567Z\n",
+		"", "051234567A", "P05123456A", "P0512345678A", "P05123456AA",
+		"A051234567A", "a051234567a", "X051234567A", "P051234567", "P051234567!", "P0512345 7A",
+	}
+	for _, pin := range inputs {
+		_, err := ValidateAndNormalizePIN(pin)
+		if got, want := IsValidPINFormat(pin), err == nil; got != want {
+			t.Errorf("IsValidPINFormat(%q) = %v, want %v", pin, got, want)
+		}
+	}
+}
+
+func TestInspectPIN(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    PINInfo
+		wantErr bool
+	}{
+		{"P051234567A", PINInfo{PIN: "P051234567A", TaxpayerType: "company", ValidFormat: true}, false},
+		{" p051234567a ", PINInfo{PIN: "P051234567A", TaxpayerType: "company", ValidFormat: true}, false},
+		{"A012345678Z", PINInfo{PIN: "A012345678Z", TaxpayerType: "individual", ValidFormat: true}, false},
+		{"A0123", PINInfo{PIN: "A0123", TaxpayerType: "individual"}, true},
+		{"P05123", PINInfo{PIN: "P05123", TaxpayerType: "company"}, true},
+		{"X051234567A", PINInfo{PIN: "X051234567A"}, true},
+		{"", PINInfo{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := InspectPIN(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("InspectPIN(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("InspectPIN(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+
+	var formatErr *InvalidPINFormatError
+	if _, err := InspectPIN("P05123"); !errors.As(err, &formatErr) {
+		t.Errorf("expected InvalidPINFormatError, got %v", err)
+	}
+}
+
 func TestValidateAndNormalizeTCC(t *testing.T) {
 	tests := []struct {
 		name    string
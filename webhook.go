@@ -0,0 +1,156 @@
+package kra
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Webhook event types delivered by GavaConnect callbacks
+const (
+	WebhookFilingAccepted         = "filing.accepted"
+	WebhookFilingRejected         = "filing.rejected"
+	WebhookPaymentConfirmed       = "payment.confirmed"
+	WebhookObligationRegistered   = "obligation.registered"
+	WebhookObligationDeregistered = "obligation.deregistered"
+)
+
+// ErrInvalidWebhookSignature is returned when a webhook signature does not
+// match its payload
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// WebhookEvent is a parsed asynchronous callback
+//
+// Exactly one of NILReturn, Eslip and Obligation is set for known event
+// types, depending on the event family ("filing.", "payment." or
+// "obligation."). Unknown event types are still parsed so that new callbacks
+// do not break existing handlers; their payload is available in Data.
+type WebhookEvent struct {
+	ID         string                 `json:"id,omitempty"`
+	Type       string                 `json:"type"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	NILReturn  *NILReturnResult       `json:"nil_return,omitempty"`
+	Eslip      *EslipValidationResult `json:"eslip,omitempty"`
+	Obligation *ObligationResult      `json:"obligation,omitempty"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+}
+
+// VerifyWebhookSignature checks that signature is the HMAC-SHA256 of payload
+// keyed with secret
+//
+// The signature is hex encoded and may carry a "sha256=" prefix. Comparison
+// runs in constant time. Always verify the raw request body before parsing it.
+//
+// Example:
+//
+//	body, _ := io.ReadAll(r.Body)
+//	if err := kra.VerifyWebhookSignature(body, r.Header.Get("X-KRA-Signature"), secret); err != nil {
+//	    http.Error(w, "invalid signature", http.StatusUnauthorized)
+//	    return
+//	}
+func VerifyWebhookSignature(payload []byte, signature, secret string) error {
+	if secret == "" {
+		return NewValidationError("webhook_secret", "Webhook secret cannot be empty")
+	}
+
+	signature = strings.TrimSpace(signature)
+	signature = strings.TrimPrefix(signature, "sha256=")
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(expected) != sha256.Size {
+		return ErrInvalidWebhookSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
+
+// ParseWebhookEvent parses a callback body into a typed WebhookEvent
+//
+// The payload is read from "responseData" or "data", as with API responses.
+// Verify the signature with VerifyWebhookSignature before calling this.
+//
+// Example:
+//
+//	event, err := kra.ParseWebhookEvent(body)
+//	if err != nil {
+//	    return err
+//	}
+//	if event.Type == kra.WebhookFilingAccepted {
+//	    fmt.Println("Acknowledged:", event.NILReturn.AcknowledgementNumber)
+//	}
+func ParseWebhookEvent(payload []byte) (*WebhookEvent, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, NewValidationError("webhook_payload", "Webhook payload is not a JSON object")
+	}
+
+	eventType := strings.ToLower(firstString(raw, "eventType", "event_type", "event", "type"))
+	if eventType == "" {
+		return nil, NewValidationError("webhook_payload", "Webhook payload has no event type")
+	}
+
+	data := extractPayload(raw)
+	event := &WebhookEvent{
+		ID:   firstString(raw, "eventId", "event_id", "id"),
+		Type: eventType,
+		Data: data,
+	}
+	if ts := firstString(raw, "timestamp", "occurredAt", "occurred_at"); ts != "" {
+		if occurred, err := time.Parse(time.RFC3339, ts); err == nil {
+			event.OccurredAt = occurred
+		}
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	meta := ResponseMetadata{RequestID: event.ID}
+	family, outcome, _ := strings.Cut(eventType, ".")
+	switch family {
+	case "filing":
+		result := &NILReturnResult{
+			PINNumber:    firstString(data, "pinNumber", "PINNumber", "kraPin", "pin"),
+			ObligationID: firstString(data, "obligationId", "ObligationID", "obligationCode"),
+			Period:       firstString(data, "period", "taxPeriod"),
+			FiledAt:      event.OccurredAt,
+			Metadata:     meta,
+		}
+		applyNILReturnPayload(result, data, nil)
+		if result.Status == "" {
+			result.Status = outcome
+			result.Success = outcome != string(StatusRejected)
+		}
+		event.NILReturn = result
+	case "payment":
+		result := parseEslipResult(data, meta)
+		result.ValidatedAt = event.OccurredAt
+		if result.Status == "" && eventType == WebhookPaymentConfirmed {
+			result.Status = string(StatusPaid)
+			result.IsValid = true
+		}
+		event.Eslip = result
+	case "obligation":
+		result := &ObligationResult{
+			PINNumber:     firstString(data, "pinNumber", "PINNumber", "kraPin", "pin"),
+			EffectiveDate: firstString(data, "effectiveDate", "EffectiveDate"),
+			ProcessedAt:   event.OccurredAt,
+			Metadata:      meta,
+		}
+		applyObligationPayload(result, data)
+		if result.Status == "" {
+			result.Status = outcome
+			result.Success = true
+		}
+		event.Obligation = result
+	}
+
+	return event, nil
+}
@@ -0,0 +1,91 @@
+package kra
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+)
+
+func signWebhook(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	const secret = "whsec_test"
+	payload := []byte(`{"eventType":"filing.accepted","data":{"pinNumber":"P051234567A"}}`)
+	signature := signWebhook(payload, secret)
+
+	if err := VerifyWebhookSignature(payload, signature, secret); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if err := VerifyWebhookSignature(payload, "sha256="+signature, secret); err != nil {
+		t.Fatalf("prefixed signature rejected: %v", err)
+	}
+
+	tampered := []byte(`{"eventType":"filing.accepted","data":{"pinNumber":"P051234567B"}}`)
+	if err := VerifyWebhookSignature(tampered, signature, secret); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Fatalf("tampered payload: expected ErrInvalidWebhookSignature, got %v", err)
+	}
+	if err := VerifyWebhookSignature(payload, signature, "other-secret"); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Fatalf("wrong secret: expected ErrInvalidWebhookSignature, got %v", err)
+	}
+	if err := VerifyWebhookSignature(payload, "not-hex", secret); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Fatalf("malformed signature: expected ErrInvalidWebhookSignature, got %v", err)
+	}
+
+	var validationErr *ValidationError
+	if err := VerifyWebhookSignature(payload, signature, ""); !errors.As(err, &validationErr) {
+		t.Fatalf("empty secret: expected ValidationError, got %v", err)
+	}
+}
+
+func TestParseWebhookEvent(t *testing.T) {
+	filing, err := ParseWebhookEvent([]byte(`{
+		"eventId": "evt-1",
+		"eventType": "filing.accepted",
+		"timestamp": "2024-02-01T08:00:00Z",
+		"data": {"pinNumber": "P051234567A", "period": "202401", "acknowledgementNumber": "ACK-9"}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent() error = %v", err)
+	}
+	if filing.NILReturn == nil || filing.Eslip != nil || filing.Obligation != nil {
+		t.Fatalf("expected only a NIL return result, got %+v", filing)
+	}
+	if !filing.NILReturn.IsAccepted() || filing.NILReturn.AcknowledgementNumber != "ACK-9" {
+		t.Fatalf("unexpected NIL return: %+v", filing.NILReturn)
+	}
+	if want := time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC); !filing.OccurredAt.Equal(want) || filing.ID != "evt-1" {
+		t.Fatalf("unexpected envelope: id=%s occurred=%v", filing.ID, filing.OccurredAt)
+	}
+
+	payment, err := ParseWebhookEvent([]byte(`{
+		"eventType": "payment.confirmed",
+		"responseData": {"eslipNumber": "1234567890", "amount": 2500, "currency": "KES"}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent() error = %v", err)
+	}
+	if payment.Eslip == nil || !payment.Eslip.IsPaid() || payment.Eslip.Amount != 2500 {
+		t.Fatalf("unexpected e-slip: %+v", payment.Eslip)
+	}
+
+	unknown, err := ParseWebhookEvent([]byte(`{"eventType": "audit.opened", "data": {"caseId": "C-1"}}`))
+	if err != nil {
+		t.Fatalf("unknown event type should parse, got %v", err)
+	}
+	if unknown.NILReturn != nil || unknown.Eslip != nil || unknown.Obligation != nil || unknown.Data["caseId"] != "C-1" {
+		t.Fatalf("unexpected unknown event: %+v", unknown)
+	}
+
+	for _, body := range []string{`not json`, `{"data": {}}`} {
+		if _, err := ParseWebhookEvent([]byte(body)); err == nil {
+			t.Fatalf("expected error for %s", body)
+		}
+	}
+}